@@ -0,0 +1,133 @@
+// Command mcp serves the article corpus over the Model Context Protocol, on
+// stdio for locally launched agents or over HTTP for remote ones.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/mcp"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
+	handler "article-chat-system/internal/transport/http"
+	"article-chat-system/internal/usage"
+	"article-chat-system/internal/vector"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	transport := flag.String("transport", "stdio", "MCP transport: stdio or http")
+	addr := flag.String("addr", ":8090", "listen address for the http transport")
+	flag.Parse()
+
+	// stdout carries protocol messages on the stdio transport, so logs go to stderr.
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+	log.SetOutput(os.Stderr)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.New()
+
+	repo, err := repository.NewPostgresRepository(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize repository: %v", err)
+	}
+
+	llmRouter, err := llm.NewRouterFactory(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to create LLM client: %v", err)
+	}
+	if cfg.LLMCache != "" {
+		store, err := repository.OpenLLMCache(cfg.LLMCache, cfg.LLMCacheDir, repo.DB)
+		if err != nil {
+			log.Fatalf("Failed to open LLM response cache: %v", err)
+		}
+		llmRouter.Wrap(func(client llm.Client, model string) llm.Client {
+			return llm.WithResponseCache(client, store, llm.ResponseCacheConfig{
+				Model:         model,
+				PromptVersion: cfg.PromptVersion,
+				TTL:           cfg.LLMCacheTTL,
+			})
+		})
+		go llm.PruneResponseCache(ctx, store, cfg.LLMCacheTTL, cfg.LLMCacheMaxEntries, cfg.LLMCachePruneEvery)
+	}
+
+	promptLoader, err := prompts.NewLoader(cfg.PromptVersion)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	}
+	promptFactory, err := prompts.NewFactory(promptLoader)
+	if err != nil {
+		log.Fatalf("Failed to create prompt factory: %v", err)
+	}
+
+	// Vector search is optional; until Weaviate is reachable the search tool
+	// reports it as unavailable.
+	vecRepo, err := repository.OpenVectorRepository(cfg.WeaviateHost, cfg.WeaviateScheme)
+	if err != nil {
+		log.Fatalf("Invalid Weaviate configuration: %v", err)
+	}
+	weaviateSvc, err := vector.OpenWeaviateService(cfg.WeaviateHost, cfg.WeaviateScheme, cfg.WeaviateAPIKey)
+	if err != nil {
+		log.Fatalf("Invalid Weaviate configuration: %v", err)
+	}
+	go vector.Reconnect(ctx, logger, 5*time.Second, time.Minute, vecRepo, weaviateSvc)
+
+	articleSvc := article.NewService(llmRouter.Client(llm.RoleSynthesis), repo, vecRepo)
+	processingFacade := processing.NewFacade(llmRouter.Client(llm.RoleAnalysis), articleSvc, promptFactory, weaviateSvc, vecRepo)
+	processingFacade.SetTokenBudget(llm.NewBudget(llmRouter.Model(llm.RoleAnalysis), cfg.ModelContextLimits))
+	prices, err := usage.LoadPrices(cfg.UsagePricesFile)
+	if err != nil {
+		log.Fatalf("Failed to load model prices: %v", err)
+	}
+	usageSvc := usage.NewService(repository.NewPostgresUsageRepository(repo.DB), prices, usage.Budgets{
+		PerCaller: cfg.UsageDailyBudget,
+		Total:     cfg.UsageTotalBudget,
+	})
+	processingFacade.SetUsageRecorder(usageSvc)
+	server := mcp.NewServer(logger, articleSvc, processingFacade, promptFactory)
+	server.SetUsageTracker(usageSvc)
+
+	switch *transport {
+	case "stdio":
+		logger.Info("Serving MCP on stdio")
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatalf("MCP stdio transport failed: %v", err)
+		}
+	case "http":
+		mux := http.NewServeMux()
+		// Remote agents authenticate with the same API keys as the HTTP API.
+		apiKeys := usage.NewAPIKeys(cfg.APIKeys, cfg.AdminAPIKeys)
+		mux.Handle("/mcp", handler.IdentifyCaller(apiKeys, server.HTTPHandler()))
+		httpServer := &http.Server{Addr: *addr, Handler: mux}
+		go func() {
+			logger.Info("Serving MCP over HTTP", "addr", *addr, "path", "/mcp")
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Could not listen on %s: %v", *addr, err)
+			}
+		}()
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("MCP server shutdown failed", "error", err)
+		}
+	default:
+		log.Fatalf("Unknown transport %q: expected stdio or http", *transport)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,15 +14,22 @@ import (
 
 	"article-chat-system/internal/article"
 	"article-chat-system/internal/cache"
+	"article-chat-system/internal/chat"
 	"article-chat-system/internal/config"
+	"article-chat-system/internal/health"
+	"article-chat-system/internal/jobs"
 	"article-chat-system/internal/llm"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/processing"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/repository"
+	"article-chat-system/internal/resolver"
+	"article-chat-system/internal/session"
 	"article-chat-system/internal/strategies"
 	"article-chat-system/internal/tracing"
+	grpcserver "article-chat-system/internal/transport/grpc"
 	handler "article-chat-system/internal/transport/http"
+	"article-chat-system/internal/usage"
 	"article-chat-system/internal/vector"
 
 	_ "github.com/lib/pq"
@@ -70,12 +78,32 @@ func main() {
 	logger.Info("Successfully connected to PostgreSQL")
 
 	// 2. Initialize Core Components
-	llmClient, err := llm.NewClientFactory(ctx, cfg)
+	llmRouter, err := llm.NewRouterFactory(ctx, cfg)
 	if err != nil {
 		logger.Error("Failed to create LLM client", "error", err)
 		log.Fatalf("Failed to create LLM client: %v", err)
 	}
-	logger.Info("LLM client created", "provider", cfg.LLMProvider)
+	var llmCache llm.ResponseStore
+	if cfg.LLMCache != "" {
+		store, err := repository.OpenLLMCache(cfg.LLMCache, cfg.LLMCacheDir, repo.DB)
+		if err != nil {
+			logger.Error("Failed to open LLM response cache", "error", err, "backend", cfg.LLMCache)
+			log.Fatalf("Failed to open LLM response cache: %v", err)
+		}
+		llmRouter.Wrap(func(client llm.Client, model string) llm.Client {
+			return llm.WithResponseCache(client, store, llm.ResponseCacheConfig{
+				Model:         model,
+				PromptVersion: cfg.PromptVersion,
+				TTL:           cfg.LLMCacheTTL,
+			})
+		})
+		llmCache = store
+		logger.Info("LLM response cache enabled", "backend", cfg.LLMCache, "ttl", cfg.LLMCacheTTL, "max_entries", cfg.LLMCacheMaxEntries)
+	}
+	logger.Info("LLM client created", "provider", cfg.LLMProvider,
+		"planner_model", llmRouter.Model(llm.RolePlanner),
+		"analysis_model", llmRouter.Model(llm.RoleAnalysis),
+		"synthesis_model", llmRouter.Model(llm.RoleSynthesis))
 
 	promptLoader, err := prompts.NewLoader(cfg.PromptVersion)
 	if err != nil {
@@ -88,71 +116,125 @@ func main() {
 		log.Fatalf("Failed to create prompt factory: %v", err)
 	}
 	strategyExecutor := strategies.NewExecutor()
+	strategyExecutor.StepTimeout = cfg.PlanStepTimeout
+	strategyExecutor.MaxParallel = cfg.PlanMaxParallel
+	for intent := range cfg.LLMIntentModels {
+		if _, ok := strategyExecutor.Strategies[planner.QueryIntent(strings.ToUpper(intent))]; !ok {
+			logger.Warn("LLM_INTENT_MODELS names an unknown intent; its model is never used", "intent", intent)
+		}
+	}
 
 	// Initialize cache service for API-level caching
 	cacheSvc := cache.NewService()
 	logger.Info("Successfully initialized cache service")
 
-	// Initialize vector repository (Weaviate)
-	vecRepo, err := repository.NewVectorRepository(cfg.WeaviateHost, cfg.WeaviateScheme)
+	// Initialize the vector stores (Weaviate). They are created even when
+	// Weaviate is down; vector search stays unavailable until they connect.
+	vecRepo, err := repository.OpenVectorRepository(cfg.WeaviateHost, cfg.WeaviateScheme)
 	if err != nil {
-		logger.Warn("Failed to initialize Weaviate repository", "error", err, "host", cfg.WeaviateHost)
-		logger.Info("Falling back to simple vector service")
-		vecRepo = nil
-	} else {
-		logger.Info("Successfully initialized Weaviate repository", "host", cfg.WeaviateHost)
+		logger.Error("Invalid Weaviate configuration", "error", err, "host", cfg.WeaviateHost)
+		log.Fatalf("Invalid Weaviate configuration: %v", err)
+	}
+	weaviateSvc, err := vector.OpenWeaviateService(cfg.WeaviateHost, cfg.WeaviateScheme, cfg.WeaviateAPIKey)
+	if err != nil {
+		logger.Error("Invalid Weaviate configuration", "error", err, "host", cfg.WeaviateHost)
+		log.Fatalf("Invalid Weaviate configuration: %v", err)
+	}
+	vectorsReady := true
+	for _, store := range []vector.Connector{vecRepo, weaviateSvc} {
+		if err := store.Connect(ctx); err != nil {
+			logger.Warn("Weaviate unreachable; vector search is degraded until it connects", "error", err, "host", cfg.WeaviateHost)
+			vectorsReady = false
+			break
+		}
+	}
+	if vectorsReady {
+		logger.Info("Successfully connected to Weaviate", "host", cfg.WeaviateHost)
 	}
 
 	// 3. Initialize Services
-	articleSvc := article.NewService(llmClient, repo, vecRepo)
-
-	// Initialize vector service (fallback)
-	var vectorSvc vector.Service
-	if vecRepo == nil {
-		vectorSvc = nil
-		logger.Info("No vector service available")
-	} else {
-		// Use the existing Weaviate service as fallback
-		weaviateSvc, err := vector.NewWeaviateService(cfg.WeaviateHost, cfg.WeaviateScheme, cfg.WeaviateAPIKey)
-		if err != nil {
-			logger.Warn("Failed to initialize Weaviate service", "error", err)
-			vectorSvc = nil
-		} else {
-			logger.Info("Successfully initialized Weaviate service")
-			vectorSvc = weaviateSvc
-		}
+	articleSvc := article.NewService(llmRouter.Client(llm.RoleSynthesis), repo, vecRepo)
+	var vectorSvc vector.Service = weaviateSvc
+
+	sessionSvc := session.NewService(repository.NewPostgresSessionRepository(repo.DB))
+
+	plannerSvc := planner.NewService(llmRouter.Client(llm.RolePlanner), promptFactory, articleSvc, vecRepo, strategyExecutor)
+	processingFacade := processing.NewFacade(llmRouter.Client(llm.RoleAnalysis), articleSvc, promptFactory, vectorSvc, vecRepo)
+	processingFacade.SetTokenBudget(llm.NewBudget(llmRouter.Model(llm.RoleAnalysis), cfg.ModelContextLimits))
+	processingFacade.SetAnswerCache(cacheSvc)
+
+	jobCfg := jobs.DefaultConfig()
+	jobCfg.Workers = cfg.JobWorkers
+	jobCfg.MaxAttempts = cfg.JobMaxAttempts
+	jobQueue := jobs.NewQueue(repository.NewPostgresJobRepository(repo.DB), processingFacade, jobCfg, logger)
+
+	chatSvc := chat.NewService(plannerSvc, strategyExecutor, articleSvc, promptFactory, vectorSvc, cacheSvc)
+	targetResolver := resolver.NewResolver(articleSvc)
+	targetResolver.MinRelevance = cfg.ResolverRelevance
+	chatSvc.SetTargetResolver(targetResolver)
+	processingFacade.SetTitleIndex(targetResolver)
+
+	prices, err := usage.LoadPrices(cfg.UsagePricesFile)
+	if err != nil {
+		logger.Error("Failed to load model prices", "error", err, "path", cfg.UsagePricesFile)
+		log.Fatalf("Failed to load model prices: %v", err)
 	}
+	usageSvc := usage.NewService(repository.NewPostgresUsageRepository(repo.DB), prices, usage.Budgets{
+		PerCaller: cfg.UsageDailyBudget,
+		Total:     cfg.UsageTotalBudget,
+	})
+	chatSvc.SetUsageTracker(usageSvc)
+	processingFacade.SetUsageRecorder(usageSvc)
+	apiKeys := usage.NewAPIKeys(cfg.APIKeys, cfg.AdminAPIKeys)
 
-	plannerSvc := planner.NewService(llmClient, promptFactory, articleSvc, vecRepo)
-	processingFacade := processing.NewFacade(llmClient, articleSvc, promptFactory, vectorSvc, vecRepo)
+	// Postgres is required to serve anything; Weaviate and the LLM provider
+	// only take their capabilities down with them.
+	healthChecks := []health.Check{
+		{Name: "postgres", Critical: true, Probe: repo.DB.PingContext},
+		{Name: "weaviate", Capabilities: []string{"vector_search"}, Probe: func(ctx context.Context) error {
+			if !vecRepo.Ready() || !weaviateSvc.Ready() {
+				return vector.ErrUnavailable
+			}
+			return vecRepo.Ping(ctx)
+		}},
+	}
+	if checker, ok := llmRouter.Primary().(llm.HealthChecker); ok {
+		healthChecks = append(healthChecks, health.Check{Name: "llm", Capabilities: []string{"chat", "ingestion"}, Probe: checker.Ping})
+	}
+	healthSvc := health.NewService(3*time.Second, 10*time.Second, healthChecks...)
 
 	// 4. Initialize the Transport Layer (The Handler) LAST
 	apiHandler := handler.NewHandler(
 		logger,
 		articleSvc,
-		plannerSvc,
-		strategyExecutor,
-		promptFactory,
+		chatSvc,
 		processingFacade,
-		vectorSvc,
-		cacheSvc,
+		sessionSvc,
+		jobQueue,
+		healthSvc,
+		usageSvc,
 	)
+	apiHandler.SetAPIKeys(apiKeys)
 
 	// 5. Start Background Processes
-	go func() {
-		logger.Info("Processing initial articles in the background", "count", len(cfg.InitialArticleURLs))
-		for _, url := range cfg.InitialArticleURLs {
-			_, err := processingFacade.AddNewArticle(context.Background(), url)
-			if err != nil {
-				if strings.Contains(err.Error(), "already exists") {
-					logger.Debug("Article already processed", "url", url)
-				} else {
-					logger.Error("Failed to process initial URL", "url", url, "error", err)
-				}
-			}
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	jobQueue.Start(workerCtx)
+	if llmCache != nil {
+		go llm.PruneResponseCache(workerCtx, llmCache, cfg.LLMCacheTTL, cfg.LLMCacheMaxEntries, cfg.LLMCachePruneEvery)
+	}
+	if !vectorsReady {
+		go vector.Reconnect(workerCtx, logger, 5*time.Second, time.Minute, vecRepo, weaviateSvc)
+	}
+
+	// Seeding is just another ingestion job; articles already stored are skipped.
+	if len(cfg.InitialArticleURLs) > 0 {
+		seedJob, err := jobQueue.Enqueue(ctx, cfg.InitialArticleURLs)
+		if err != nil {
+			logger.Error("Failed to enqueue initial articles", "error", err)
+		} else {
+			logger.Info("Enqueued initial articles", "job_id", seedJob.ID, "count", len(cfg.InitialArticleURLs))
 		}
-		logger.Info("Initial article processing complete")
-	}()
+	}
 
 	// 6. Start the Server
 	server := &http.Server{
@@ -167,6 +249,21 @@ func main() {
 		}
 	}()
 
+	grpcService := grpcserver.NewServer(logger, articleSvc, chatSvc, processingFacade, sessionSvc)
+	grpcService.SetAPIKeys(apiKeys)
+	grpcServer := grpcService.NewGRPCServer()
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		logger.Error("Failed to listen for gRPC", "error", err, "port", cfg.GRPCPort)
+		log.Fatalf("Could not listen on :%s: %v\n", cfg.GRPCPort, err)
+	}
+	go func() {
+		logger.Info("Starting gRPC server", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Error("gRPC server failed", "error", err)
+		}
+	}()
+
 	// 7. Handle Graceful Shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
@@ -178,5 +275,8 @@ func main() {
 		logger.Error("Server shutdown failed", "error", err)
 		log.Fatalf("Server shutdown failed: %v", err)
 	}
+	grpcServer.GracefulStop()
+	stopWorkers()
+	jobQueue.Wait()
 	logger.Info("Server gracefully stopped")
 }
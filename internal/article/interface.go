@@ -1,6 +1,7 @@
 package article
 
 import (
+	"article-chat-system/internal/llm"
 	"article-chat-system/internal/models"
 	"article-chat-system/internal/repository"
 	"context"
@@ -10,7 +11,9 @@ import (
 type Service interface {
 	GetArticle(ctx context.Context, url string) (*models.Article, bool)
 	StoreArticle(ctx context.Context, article *models.Article) error
-	CallSynthesisLLM(ctx context.Context, prompt string) (string, error)
+	DeleteArticle(ctx context.Context, url string) (bool, error)
+	ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error)
+	CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error)
 	FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error)
 	SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error)
 }
@@ -40,12 +40,33 @@ func (s *ArticleService) StoreArticle(ctx context.Context, article *models.Artic
 	return s.pgRepo.Save(ctx, article)
 }
 
+// DeleteArticle removes an article's metadata and reports whether it existed.
+func (s *ArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	return s.pgRepo.Delete(ctx, url)
+}
+
+// ListArticles returns one page of the article catalog matching the filter.
+func (s *ArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	return s.pgRepo.List(ctx, filter)
+}
+
 // CallSynthesisLLM is a helper method for strategies to generate text.
-func (s *ArticleService) CallSynthesisLLM(ctx context.Context, prompt string) (string, error) {
+func (s *ArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
 	defer cancel()
+	// The answer's model is the one reported for the request.
+	ctx = llm.WithSynthesis(ctx)
+
+	// Stream the answer when the caller asked for it.
+	if onChunk, ok := llm.StreamHandlerFromContext(ctx); ok {
+		resp, err := llm.Stream(ctx, s.llmClient, req, onChunk)
+		if err != nil {
+			return "", err
+		}
+		return resp.Text, nil
+	}
 
-	resp, err := s.llmClient.GenerateContent(ctx, prompt)
+	resp, err := llm.Generate(ctx, s.llmClient, req)
 	if err != nil {
 		return "", err
 	}
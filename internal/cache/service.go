@@ -4,15 +4,28 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"sync"
+
+	"article-chat-system/internal/metrics"
 )
 
 // Service provides a simple in-memory cache for request hashing.
+// Entries remember which articles their answer was built from so they can be
+// invalidated when one of those articles is deleted or re-analyzed.
 type Service struct {
 	store sync.Map
+
+	mu         sync.Mutex
+	byArticle  map[string]map[string]struct{} // article URL -> cache keys
+	untargeted map[string]struct{}            // keys whose answer may draw on any article
+	articles   map[string][]string            // cache key -> article URLs it is indexed under
 }
 
 func NewService() *Service {
-	return &Service{}
+	return &Service{
+		byArticle:  make(map[string]map[string]struct{}),
+		untargeted: make(map[string]struct{}),
+		articles:   make(map[string][]string),
+	}
 }
 
 // GenerateCacheKey now creates a stable hash from a simple string.
@@ -21,14 +34,93 @@ func (s *Service) GenerateCacheKey(query string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-func (s *Service) Get(key string) (string, bool) {
-	val, ok := s.store.Load(key)
-	if !ok {
-		return "", false
+// Get returns the value stored under key.
+func (s *Service) Get(key string) (interface{}, bool) {
+	value, ok := s.store.Load(key)
+	if ok {
+		metrics.CacheLookups.WithLabelValues("hit").Inc()
+	} else {
+		metrics.CacheLookups.WithLabelValues("miss").Inc()
 	}
-	return val.(string), true
+	return value, ok
 }
 
-func (s *Service) Set(key, value string) {
+// Set stores an answer that is not tied to specific articles; it is dropped
+// whenever any article changes.
+func (s *Service) Set(key string, value interface{}) {
+	s.SetForArticles(key, value, nil)
+}
+
+// SetForArticles stores an answer built from the given articles. An empty list
+// means the answer may depend on any article in the corpus.
+func (s *Service) SetForArticles(key string, value interface{}, articleURLs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A replaced entry must not stay indexed under its old articles.
+	s.remove(key)
 	s.store.Store(key, value)
+	if len(articleURLs) == 0 {
+		s.untargeted[key] = struct{}{}
+		return
+	}
+	for _, url := range articleURLs {
+		keys, ok := s.byArticle[url]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.byArticle[url] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	s.articles[key] = articleURLs
+}
+
+// InvalidateArticle drops every cached answer that referenced the article,
+// along with all answers not tied to specific articles. It returns the number
+// of entries removed.
+func (s *Service) InvalidateArticle(url string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key := range s.byArticle[url] {
+		if s.remove(key) {
+			removed++
+		}
+	}
+	return removed + s.removeUntargeted()
+}
+
+// InvalidateUntargeted drops the answers not tied to specific articles, which
+// a newly added article may change. It returns the number of entries removed.
+func (s *Service) InvalidateUntargeted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.removeUntargeted()
+}
+
+func (s *Service) removeUntargeted() int {
+	removed := 0
+	for key := range s.untargeted {
+		if s.remove(key) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// remove drops the entry under key from the store and from every index,
+// reporting whether it was stored. The caller holds s.mu.
+func (s *Service) remove(key string) bool {
+	_, loaded := s.store.LoadAndDelete(key)
+	delete(s.untargeted, key)
+	for _, url := range s.articles[key] {
+		delete(s.byArticle[url], key)
+		if len(s.byArticle[url]) == 0 {
+			delete(s.byArticle, url)
+		}
+	}
+	delete(s.articles, key)
+	return loaded
 }
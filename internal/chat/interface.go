@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/resolver"
+)
+
+// Request is a single question put to the system, independent of transport.
+type Request struct {
+	Query   string
+	History []models.Turn // Prior turns, oldest first; stand-alone questions leave it empty
+
+	// Intent, when set, overrides the intent chosen by the planner while
+	// keeping its targets and parameters.
+	Intent planner.QueryIntent
+
+	// OnPlan, when set, is called once the query has been planned and before
+	// it is executed. Returning an error aborts the request.
+	OnPlan func(plan *planner.QueryPlan) error
+}
+
+// Answer describes an answer and how it was produced.
+type Answer struct {
+	Answer        string             `json:"answer"`
+	Plan          *planner.QueryPlan `json:"plan,omitempty"`
+	Sources       []planner.Source   `json:"sources"`
+	PromptVersion string             `json:"prompt_version,omitempty"`
+	Model         string             `json:"model,omitempty"`
+	Usage         llm.Usage          `json:"usage"`              // Tokens consumed by this request; zero on a cache hit
+	LatencyMs     map[string]int64   `json:"latency_ms"`         // Milliseconds per stage, plus "total"
+	CostUSD       float64            `json:"cost_usd,omitempty"` // Priced with the usage price table
+	Cached        bool               `json:"cached"`
+	Degraded      bool               `json:"degraded,omitempty"` // A last-resort fallback provider answered
+	// Clarification lists the articles a query could refer to when the
+	// answer asks which one was meant instead of answering.
+	Clarification []resolver.Candidate `json:"clarification,omitempty"`
+	// Steps holds the output of each step of a plan with several.
+	Steps []planner.StepResult `json:"steps,omitempty"`
+}
+
+// Service answers questions by planning and executing them. It is shared by
+// every transport so they all behave the same.
+type Service interface {
+	Ask(ctx context.Context, req Request) (*Answer, error)
+	Intents() []planner.QueryIntent
+	PromptVersion() string
+}
+
+// UsageTracker enforces spending budgets and accounts for the LLM usage of
+// each request.
+type UsageTracker interface {
+	Check(ctx context.Context) error
+	Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error)
+}
+
+// TargetResolver maps the targets of a plan to article URLs. It returns a
+// *resolver.AmbiguousError when a target could refer to several articles.
+type TargetResolver interface {
+	Resolve(ctx context.Context, plan *planner.QueryPlan, history []models.Turn) error
+}
@@ -0,0 +1,294 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/cache"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/metrics"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/resolver"
+	"article-chat-system/internal/strategies"
+	"article-chat-system/internal/vector"
+)
+
+// ChatService runs the plan-then-execute pipeline behind the answer cache.
+type ChatService struct {
+	plannerSvc       planner.Service
+	strategyExecutor *strategies.Executor
+	articleSvc       article.Service
+	promptFactory    *prompts.Factory
+	vectorSvc        vector.Service
+	cacheSvc         *cache.Service
+	usageTracker     UsageTracker
+	targetResolver   TargetResolver
+}
+
+// NewService is the constructor for the chat service.
+func NewService(
+	plannerSvc planner.Service,
+	strategyExecutor *strategies.Executor,
+	articleSvc article.Service,
+	promptFactory *prompts.Factory,
+	vectorSvc vector.Service,
+	cacheSvc *cache.Service,
+) *ChatService {
+	return &ChatService{
+		plannerSvc:       plannerSvc,
+		strategyExecutor: strategyExecutor,
+		articleSvc:       articleSvc,
+		promptFactory:    promptFactory,
+		vectorSvc:        vectorSvc,
+		cacheSvc:         cacheSvc,
+	}
+}
+
+// SetUsageTracker registers the tracker that enforces budgets and records the
+// usage of every answered query. Cached answers cost nothing and skip it.
+func (s *ChatService) SetUsageTracker(t UsageTracker) {
+	s.usageTracker = t
+}
+
+// SetTargetResolver registers the resolver that maps the titles, URL
+// fragments and ordinals the planner names to article URLs before a plan runs.
+func (s *ChatService) SetTargetResolver(r TargetResolver) {
+	s.targetResolver = r
+}
+
+// cachedAnswer is what the answer cache holds for a stand-alone question.
+type cachedAnswer struct {
+	Plan   *planner.QueryPlan
+	Result *planner.Result
+	Model  string
+}
+
+// Intents lists the intents a strategy is registered for, in sorted order.
+func (s *ChatService) Intents() []planner.QueryIntent {
+	intents := make([]planner.QueryIntent, 0, len(s.strategyExecutor.Strategies))
+	for intent := range s.strategyExecutor.Strategies {
+		intents = append(intents, intent)
+	}
+	sort.Slice(intents, func(i, j int) bool { return intents[i] < intents[j] })
+	return intents
+}
+
+// PromptVersion reports the prompt set answers are generated with.
+func (s *ChatService) PromptVersion() string {
+	return s.promptFactory.Version()
+}
+
+// Ask plans and executes a question. Stand-alone questions are served from and
+// stored in the cache; follow-ups depend on the conversation and never are.
+func (s *ChatService) Ask(ctx context.Context, req Request) (*Answer, error) {
+	start := time.Now()
+	answer, err := s.ask(ctx, req)
+
+	intent := req.Intent
+	if answer != nil && answer.Plan != nil {
+		intent = answer.Plan.Intent
+	}
+	if intent == "" {
+		intent = "UNKNOWN"
+	}
+	metrics.ObserveSince(metrics.ChatDuration.WithLabelValues(string(intent), metrics.Outcome(err)), start)
+	return answer, err
+}
+
+func (s *ChatService) ask(ctx context.Context, req Request) (*Answer, error) {
+	timer := newStageTimer()
+	useCache := len(req.History) == 0
+	cacheKey := s.cacheKey(req)
+
+	if useCache {
+		if cached, found := s.lookupCache(cacheKey); found {
+			if req.OnPlan != nil {
+				if err := req.OnPlan(cached.Plan); err != nil {
+					return nil, err
+				}
+			}
+			return &Answer{
+				Answer:        "🤖 (from cache)\n\n" + cached.Result.Answer,
+				Plan:          cached.Plan,
+				Sources:       cached.Result.Sources,
+				Steps:         cached.Result.Steps,
+				PromptVersion: s.PromptVersion(),
+				Model:         cached.Model,
+				LatencyMs:     timer.finish(),
+				Cached:        true,
+			}, nil
+		}
+	}
+
+	if s.usageTracker != nil {
+		if err := s.usageTracker.Check(ctx); err != nil {
+			return nil, err
+		}
+	}
+	ctx, meter := llm.WithUsageMeter(ctx)
+
+	plan, err := s.plannerSvc.CreatePlan(ctx, req.Query, req.History)
+	if err != nil {
+		s.recordUsage(ctx, meter)
+		return nil, fmt.Errorf("failed to create a query plan: %w", err)
+	}
+	if req.Intent != "" {
+		plan.Intent = req.Intent
+		plan.Steps = nil // A forced intent runs alone
+	}
+	timer.mark("plan")
+
+	if s.targetResolver != nil {
+		if err := s.targetResolver.Resolve(ctx, plan, req.History); err != nil {
+			var ambiguous *resolver.AmbiguousError
+			if errors.As(err, &ambiguous) {
+				return s.clarify(ctx, req, plan, ambiguous, meter, timer)
+			}
+			s.recordUsage(ctx, meter)
+			return nil, fmt.Errorf("failed to resolve the plan's targets: %w", err)
+		}
+		timer.mark("resolve")
+	}
+	if req.OnPlan != nil {
+		if err := req.OnPlan(plan); err != nil {
+			s.recordUsage(ctx, meter)
+			return nil, err
+		}
+	}
+
+	result, err := s.strategyExecutor.ExecutePlan(ctx, plan, s.articleSvc, s.promptFactory, s.vectorSvc)
+	cost := s.recordUsage(ctx, meter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute the plan: %w", err)
+	}
+	timer.mark("execute")
+
+	// Degraded answers are not worth keeping once the provider recovers.
+	if useCache && !meter.Degraded() {
+		s.cacheSvc.SetForArticles(cacheKey, &cachedAnswer{Plan: plan, Result: result, Model: meter.Model()}, answerArticles(plan, result))
+	}
+
+	return &Answer{
+		Answer:        result.Answer,
+		Plan:          plan,
+		Sources:       result.Sources,
+		Steps:         result.Steps,
+		PromptVersion: s.PromptVersion(),
+		Model:         meter.Model(),
+		Usage:         meter.Usage(),
+		CostUSD:       cost,
+		LatencyMs:     timer.finish(),
+		Degraded:      meter.Degraded(),
+	}, nil
+}
+
+// answerArticles lists the articles a cached answer must be dropped with: the
+// plan's targets and every source the answer drew on. An answer without
+// targets may depend on any article, so it is left untied.
+func answerArticles(plan *planner.QueryPlan, result *planner.Result) []string {
+	if len(plan.Targets) == 0 {
+		return nil
+	}
+	urls := append([]string(nil), plan.Targets...)
+	for _, source := range result.Sources {
+		if !slices.Contains(urls, source.URL) {
+			urls = append(urls, source.URL)
+		}
+	}
+	return urls
+}
+
+// clarify answers with a question asking which of the candidates the query
+// meant, without running the plan. The candidates become the plan's targets,
+// so that a reply such as "the second one" resolves to one of them. The
+// question depends on the catalog and is never cached.
+func (s *ChatService) clarify(ctx context.Context, req Request, plan *planner.QueryPlan, ambiguous *resolver.AmbiguousError, meter *llm.UsageMeter, timer *stageTimer) (*Answer, error) {
+	clarifying := *plan
+	clarifying.Targets = make([]string, len(ambiguous.Candidates))
+	for i, c := range ambiguous.Candidates {
+		clarifying.Targets[i] = c.URL
+	}
+	clarifying.Steps = nil
+	if req.OnPlan != nil {
+		if err := req.OnPlan(&clarifying); err != nil {
+			s.recordUsage(ctx, meter)
+			return nil, err
+		}
+	}
+	cost := s.recordUsage(ctx, meter)
+	return &Answer{
+		Answer:        ambiguous.Question(),
+		Plan:          &clarifying,
+		Sources:       []planner.Source{},
+		PromptVersion: s.PromptVersion(),
+		Model:         meter.Model(),
+		Usage:         meter.Usage(),
+		CostUSD:       cost,
+		LatencyMs:     timer.finish(),
+		Degraded:      meter.Degraded(),
+		Clarification: ambiguous.Candidates,
+	}, nil
+}
+
+// recordUsage accounts for the tokens the request consumed, including those
+// of a request that failed, and returns their cost.
+func (s *ChatService) recordUsage(ctx context.Context, meter *llm.UsageMeter) float64 {
+	if s.usageTracker == nil {
+		return 0
+	}
+	cost, err := s.usageTracker.Record(ctx, "chat", meter.ByModel())
+	if err != nil {
+		slog.Warn("Failed to record LLM usage", "error", err)
+	}
+	return cost
+}
+
+// cacheKey keys answers by query and, when forced, by intent.
+func (s *ChatService) cacheKey(req Request) string {
+	if req.Intent == "" {
+		return s.cacheSvc.GenerateCacheKey(req.Query)
+	}
+	return s.cacheSvc.GenerateCacheKey(string(req.Intent) + ":" + req.Query)
+}
+
+// lookupCache returns the cached answer for a key, if any.
+func (s *ChatService) lookupCache(cacheKey string) (*cachedAnswer, bool) {
+	value, found := s.cacheSvc.Get(cacheKey)
+	if !found {
+		return nil, false
+	}
+	cached, ok := value.(*cachedAnswer)
+	return cached, ok
+}
+
+// stageTimer measures the latency of each stage of answering a query.
+type stageTimer struct {
+	start  time.Time
+	last   time.Time
+	stages map[string]int64
+}
+
+func newStageTimer() *stageTimer {
+	now := time.Now()
+	return &stageTimer{start: now, last: now, stages: make(map[string]int64)}
+}
+
+// mark records the time elapsed since the previous mark as the given stage.
+func (t *stageTimer) mark(stage string) {
+	now := time.Now()
+	t.stages[stage] = now.Sub(t.last).Milliseconds()
+	t.last = now
+}
+
+// finish records the total latency and returns all stages.
+func (t *stageTimer) finish() map[string]int64 {
+	t.stages["total"] = time.Since(t.start).Milliseconds()
+	return t.stages
+}
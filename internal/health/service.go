@@ -0,0 +1,120 @@
+// Package health probes the service's dependencies for liveness and
+// readiness endpoints.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status summarizes the health of a component or of the whole service.
+type Status string
+
+const (
+	StatusOK          Status = "ok"
+	StatusDegraded    Status = "degraded"    // Serving, with some capabilities unavailable
+	StatusUnavailable Status = "unavailable" // Not able to serve traffic
+)
+
+// Check probes one dependency.
+type Check struct {
+	Name string
+	// Critical checks make the service unready when they fail. Failing
+	// non-critical checks only mark their Capabilities as unavailable.
+	Critical     bool
+	Capabilities []string
+	Probe        func(ctx context.Context) error
+}
+
+// ComponentReport is the outcome of a single check.
+type ComponentReport struct {
+	Status    Status `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// Report is the outcome of all checks.
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+	Degraded   []string                   `json:"degraded_capabilities,omitempty"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+}
+
+// Service runs the checks and caches the report briefly, so frequent probes
+// do not hammer the dependencies (or spend LLM quota).
+type Service struct {
+	checks  []Check
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu   sync.Mutex
+	last *Report
+}
+
+// NewService is the constructor for the health service. Each check gets at
+// most timeout to answer; reports are reused for ttl.
+func NewService(timeout, ttl time.Duration, checks ...Check) *Service {
+	return &Service{checks: checks, timeout: timeout, ttl: ttl}
+}
+
+// Readiness probes every dependency concurrently and reports whether the
+// service can serve traffic.
+func (s *Service) Readiness(ctx context.Context) *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last != nil && time.Since(s.last.CheckedAt) < s.ttl {
+		return s.last
+	}
+
+	results := make([]ComponentReport, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = s.probe(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Components: make(map[string]ComponentReport, len(s.checks)), CheckedAt: time.Now()}
+	degraded := make(map[string]bool)
+	for i, check := range s.checks {
+		report.Components[check.Name] = results[i]
+		if results[i].Status == StatusOK {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+		for _, capability := range check.Capabilities {
+			degraded[capability] = true
+		}
+	}
+	for capability := range degraded {
+		report.Degraded = append(report.Degraded, capability)
+	}
+	sort.Strings(report.Degraded)
+
+	s.last = report
+	return report
+}
+
+func (s *Service) probe(ctx context.Context, check Check) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	report := ComponentReport{Status: StatusOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		report.Status = StatusUnavailable
+		report.Error = err.Error()
+	}
+	return report
+}
@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/usage"
+)
+
+// ErrJobNotFound is returned when a job ID does not match any stored job.
+var ErrJobNotFound = errors.New("job not found")
+
+// Ingester is the part of the processing facade the workers drive.
+type Ingester interface {
+	AddNewArticle(ctx context.Context, url string) (*models.Article, error)
+}
+
+// Config tunes the worker pool.
+type Config struct {
+	Workers      int           // Number of concurrent workers
+	MaxAttempts  int           // Attempts per URL before it is marked failed
+	BaseBackoff  time.Duration // Delay before the first retry; doubles per attempt
+	MaxBackoff   time.Duration // Upper bound on the retry delay
+	PollInterval time.Duration // How long an idle worker waits before polling again
+	Lease        time.Duration // How long a claimed item stays locked to its worker
+	ItemTimeout  time.Duration // Deadline for ingesting a single URL
+}
+
+// DefaultConfig returns the settings used when none are configured.
+func DefaultConfig() Config {
+	return Config{
+		Workers:      4,
+		MaxAttempts:  3,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   5 * time.Minute,
+		PollInterval: 2 * time.Second,
+		Lease:        5 * time.Minute,
+		ItemTimeout:  3 * time.Minute,
+	}
+}
+
+// Queue is a durable ingestion queue backed by the job repository and
+// consumed by a bounded pool of workers.
+type Queue struct {
+	repo     repository.JobRepository
+	ingester Ingester
+	cfg      Config
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+}
+
+// NewQueue creates a queue; call Start to begin consuming it.
+func NewQueue(repo repository.JobRepository, ingester Ingester, cfg Config, logger *slog.Logger) *Queue {
+	return &Queue{
+		repo:     repo,
+		ingester: ingester,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// Enqueue creates a job for the given URLs and returns it immediately. The
+// LLM usage of the job is charged to the context's caller.
+func (q *Queue) Enqueue(ctx context.Context, urls []string) (*models.Job, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("at least one URL is required")
+	}
+	caller := usage.CallerFrom(ctx)
+	job, err := q.repo.CreateJob(ctx, urls, caller.User, caller.APIKeyID)
+	if err != nil {
+		return nil, err
+	}
+	q.logger.Info("Enqueued ingestion job", "job_id", job.ID, "urls", len(urls))
+	return job, nil
+}
+
+// Get returns a job with the current state of each of its URLs.
+func (q *Queue) Get(ctx context.Context, id string) (*models.Job, error) {
+	job, err := q.repo.FindJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Start launches the workers. They stop when ctx is cancelled; use Wait to
+// block until in-flight items are finished.
+func (q *Queue) Start(ctx context.Context) {
+	q.logger.Info("Starting ingestion workers", "workers", q.cfg.Workers)
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go func(worker int) {
+			defer q.wg.Done()
+			q.runWorker(ctx, worker)
+		}(i)
+	}
+}
+
+// Wait blocks until all workers have exited.
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+func (q *Queue) runWorker(ctx context.Context, worker int) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		item, err := q.repo.ClaimItem(ctx, q.cfg.Lease)
+		if err != nil && ctx.Err() == nil {
+			q.logger.Error("Failed to claim job item", "worker", worker, "error", err)
+		}
+		if item == nil {
+			// Nothing due (or the database is unavailable): back off before polling again.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(q.cfg.PollInterval):
+			}
+			continue
+		}
+
+		q.process(ctx, worker, item)
+	}
+}
+
+// process ingests one claimed item and records its outcome.
+func (q *Queue) process(ctx context.Context, worker int, item *models.JobItem) {
+	itemCtx, cancel := context.WithTimeout(ctx, q.cfg.ItemTimeout)
+	itemCtx = usage.WithCaller(itemCtx, usage.Caller{User: item.User, APIKeyID: item.APIKeyID})
+	_, err := q.ingester.AddNewArticle(itemCtx, item.URL)
+	cancel()
+
+	// Record the outcome even if the pool is shutting down.
+	recordCtx := context.WithoutCancel(ctx)
+	logger := q.logger.With("worker", worker, "job_id", item.JobID, "url", item.URL, "attempt", item.Attempts)
+
+	switch {
+	case err == nil:
+		logger.Info("Ingested article")
+		err = q.repo.CompleteItem(recordCtx, item.ID, models.JobItemSucceeded, "")
+	case errors.Is(err, processing.ErrArticleExists):
+		logger.Debug("Article already processed")
+		err = q.repo.CompleteItem(recordCtx, item.ID, models.JobItemSkipped, "")
+	case errors.Is(err, usage.ErrBudgetExceeded):
+		// Retrying before the budget resets at midnight UTC would only fail
+		// again.
+		logger.Warn("Budget spent, giving up on article", "error", err)
+		err = q.repo.CompleteItem(recordCtx, item.ID, models.JobItemFailed, err.Error())
+	case ctx.Err() != nil:
+		// The pool is shutting down: the attempt was cut short rather than
+		// failed, so the item is re-queued for the next worker to start.
+		logger.Info("Ingestion interrupted by shutdown, re-queueing article")
+		err = q.repo.ReleaseItem(recordCtx, item.ID)
+	case item.Attempts >= q.cfg.MaxAttempts:
+		logger.Error("Giving up on article", "error", err)
+		err = q.repo.CompleteItem(recordCtx, item.ID, models.JobItemFailed, err.Error())
+	default:
+		delay := Backoff(item.Attempts, q.cfg.BaseBackoff, q.cfg.MaxBackoff)
+		logger.Warn("Article ingestion failed, will retry", "error", err, "retry_in", delay)
+		err = q.repo.RetryItem(recordCtx, item.ID, err.Error(), time.Now().Add(delay))
+	}
+	if err != nil {
+		logger.Error("Failed to record job item outcome", "error", err)
+	}
+}
+
+// Backoff returns the delay before retrying after the given (1-based) attempt:
+// exponential from base, capped at max, with up to 20% random jitter so that
+// items failing together do not retry in lockstep.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	jitter := time.Duration(rand.Int64N(int64(delay)/5 + 1))
+	return delay - jitter
+}
@@ -0,0 +1,446 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultAnthropicURL = "https://api.anthropic.com"
+	anthropicVersion    = "2023-06-01"
+	// anthropicMaxTokens caps answers whose request sets no limit; the
+	// Messages API requires one.
+	anthropicMaxTokens = 4096
+	// anthropicJSONPrefill starts the assistant's answer in JSON mode; the
+	// Messages API has no response format option.
+	anthropicJSONPrefill = "{"
+)
+
+// anthropicClient calls the Anthropic Messages API. System messages become the
+// top-level system prompt. Prompt segments before a CacheBoundary are sent as
+// cacheable content blocks, so repeated planner instructions and article
+// context are billed at the cache-read rate.
+type anthropicClient struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// newAnthropicClient creates a client for the Anthropic API at baseURL.
+func newAnthropicClient(baseURL, apiKey, model string) (Client, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("Anthropic API key is missing. Please set the ANTHROPIC_API_KEY environment variable")
+	}
+	return &anthropicClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{},
+	}, nil
+}
+
+type anthropicCacheControl struct {
+	Type string `json:"type"`
+}
+
+type anthropicContentBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text"`
+	ID           string                 `json:"id,omitempty"`    // Of a tool_use block
+	Name         string                 `json:"name,omitempty"`  // Tool called by a tool_use block
+	Input        json.RawMessage        `json:"input,omitempty"` // Arguments of a tool_use block
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	InputSchema *Schema `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model         string                  `json:"model"`
+	MaxTokens     int                     `json:"max_tokens"`
+	Temperature   float32                 `json:"temperature"`
+	System        []anthropicContentBlock `json:"system,omitempty"`
+	Messages      []anthropicMessage      `json:"messages"`
+	StopSequences []string                `json:"stop_sequences,omitempty"`
+	Tools         []anthropicTool         `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice    `json:"tool_choice,omitempty"`
+	Stream        bool                    `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// usage maps Anthropic's counts onto ours. Anthropic reports cached prompt
+// tokens separately from input_tokens; PromptTokens includes them.
+func (u anthropicUsage) usage() Usage {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      prompt + u.OutputTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
+	}
+}
+
+type anthropicResponse struct {
+	Model   string                  `json:"model"`
+	Content []anthropicContentBlock `json:"content"`
+	Usage   anthropicUsage          `json:"usage"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// anthropicEvent is one server-sent event of a streamed message.
+type anthropicEvent struct {
+	Type    string             `json:"type"`
+	Message *anthropicResponse `json:"message"`
+	Delta   struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"`
+	Error *anthropicError `json:"error"`
+}
+
+// contentBlocks splits text into content blocks, one per prompt segment.
+func contentBlocks(text string) []anthropicContentBlock {
+	var blocks []anthropicContentBlock
+	for _, segment := range splitCacheBoundaries(text) {
+		block := anthropicContentBlock{Type: "text", Text: segment.Text}
+		if segment.Cacheable {
+			block.CacheControl = &anthropicCacheControl{Type: "ephemeral"}
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		blocks = []anthropicContentBlock{{Type: "text", Text: StripCacheBoundaries(text)}}
+	}
+	return blocks
+}
+
+// request converts a request to the Messages API's form. Consecutive messages
+// from the same role are merged into one turn. A structured output becomes a
+// tool the model is forced to call; plain JSON mode prefills the answer with
+// the opening brace. Otherwise the request's tools are offered for the model
+// to call at will.
+func (c *anthropicClient) request(req *Request, stream bool) anthropicRequest {
+	out := anthropicRequest{
+		Model:         c.model,
+		MaxTokens:     anthropicMaxTokens,
+		Temperature:   req.Temperature, // 0 gives deterministic output, as with OpenAI
+		StopSequences: req.Stop,
+		Stream:        stream,
+	}
+	if req.MaxTokens > 0 {
+		out.MaxTokens = req.MaxTokens
+	}
+	for _, msg := range req.Messages {
+		blocks := contentBlocks(msg.Content)
+		if msg.Role == RoleSystem {
+			out.System = append(out.System, blocks...)
+			continue
+		}
+		if n := len(out.Messages); n > 0 && out.Messages[n-1].Role == string(msg.Role) {
+			out.Messages[n-1].Content = append(out.Messages[n-1].Content, blocks...)
+			continue
+		}
+		out.Messages = append(out.Messages, anthropicMessage{Role: string(msg.Role), Content: blocks})
+	}
+	if req.Output != nil {
+		out.Tools = []anthropicTool{{Name: req.Output.Name, Description: req.Output.Description, InputSchema: req.Output.Schema}}
+		out.ToolChoice = &anthropicToolChoice{Type: "tool", Name: req.Output.Name}
+	} else {
+		for _, tool := range req.Tools {
+			out.Tools = append(out.Tools, anthropicTool{Name: tool.Name, Description: tool.Description, InputSchema: tool.Parameters})
+		}
+	}
+	if c.prefill(req) != "" {
+		out.Messages = append(out.Messages, anthropicMessage{
+			Role:    string(RoleAssistant),
+			Content: []anthropicContentBlock{{Type: "text", Text: anthropicJSONPrefill}},
+		})
+	}
+	return out
+}
+
+// prefill returns the text the answer is prefilled with, if any. The model
+// continues after it, so it is prepended to the answer.
+func (c *anthropicClient) prefill(req *Request) string {
+	if req.ResponseFormat != FormatJSON || req.Output != nil {
+		return ""
+	}
+	if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == RoleAssistant {
+		return "" // The caller already started the answer.
+	}
+	return anthropicJSONPrefill
+}
+
+// Ping verifies the API key and connectivity by listing the available models.
+func (c *anthropicClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v1/models", nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("anthropic API unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("anthropic API unreachable: %w", c.responseError(resp))
+	}
+	return nil
+}
+
+// GenerateContent sends the prompt as a single user message.
+func (c *anthropicClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+// StreamContent streams the answer to the prompt sent as a single user message.
+func (c *anthropicClient) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+// Generate sends the request and waits for the whole message.
+func (c *anthropicClient) Generate(ctx context.Context, req *Request) (*Response, error) {
+	ctx, span := c.startSpan(ctx, "LLM.GenerateContent", req)
+	defer span.End()
+	start := time.Now()
+
+	body, err := c.send(ctx, c.request(req, false))
+	if err != nil {
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("anthropic API call failed: %w", err)
+	}
+	defer body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		err = &ProviderError{Provider: "anthropic", Err: fmt.Errorf("invalid response: %w", err)}
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("anthropic API call failed: %w", err)
+	}
+
+	var text strings.Builder
+	var toolCalls []ToolCall
+	text.WriteString(c.prefill(req))
+	for _, block := range result.Content {
+		switch {
+		case block.Type == "text":
+			text.WriteString(block.Text)
+		case block.Type == "tool_use" && req.Output != nil:
+			text.Write(block.Input) // The structured output
+		case block.Type == "tool_use":
+			toolCalls = append(toolCalls, ToolCall{ID: block.ID, Name: block.Name, Arguments: block.Input})
+		}
+	}
+	resp := c.finish(ctx, span, start, result.Model, text.String(), result.Usage.usage())
+	resp.ToolCalls = toolCalls
+	return resp, nil
+}
+
+// Stream reads the message's server-sent events, passing each text delta to
+// onChunk.
+func (c *anthropicClient) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	ctx, span := c.startSpan(ctx, "LLM.StreamContent", req)
+	defer span.End()
+	start := time.Now()
+
+	body, err := c.send(ctx, c.request(req, true))
+	if err != nil {
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("anthropic streaming API call failed: %w", err)
+	}
+	defer body.Close()
+
+	fail := func(err error) (*Response, error) {
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("anthropic stream failed: %w", err)
+	}
+
+	var text strings.Builder
+	if prefill := c.prefill(req); prefill != "" {
+		text.WriteString(prefill)
+		if err := onChunk(prefill); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("stream handler aborted: %w", err)
+		}
+	}
+	var usage anthropicUsage
+	model := c.model
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // Event names and blank separators; the payload repeats the type.
+		}
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return fail(&ProviderError{Provider: "anthropic", Err: fmt.Errorf("invalid stream event: %w", err)})
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				usage = event.Message.Usage
+				if event.Message.Model != "" {
+					model = event.Message.Model
+				}
+			}
+		case "content_block_delta":
+			delta := event.Delta.Text
+			if event.Delta.Type == "input_json_delta" {
+				delta = event.Delta.PartialJSON
+			}
+			if delta == "" {
+				continue
+			}
+			text.WriteString(delta)
+			if err := onChunk(delta); err != nil {
+				span.RecordError(err)
+				return nil, fmt.Errorf("stream handler aborted: %w", err)
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "error":
+			message := "unknown stream error"
+			if event.Error != nil {
+				message = event.Error.Type + ": " + event.Error.Message
+			}
+			return fail(&ProviderError{Provider: "anthropic", Err: errors.New(message)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fail(&ProviderError{Provider: "anthropic", Err: err})
+	}
+
+	return c.finish(ctx, span, start, model, text.String(), usage.usage()), nil
+}
+
+// send posts the request to /v1/messages and returns the body of a successful
+// response.
+func (c *anthropicClient) send(ctx context.Context, body anthropicRequest) (io.ReadCloser, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &ProviderError{Provider: "anthropic", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+func (c *anthropicClient) setHeaders(req *http.Request) {
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+}
+
+// responseError converts an error response. Overload (529) and rate limit
+// (429) responses are temporary and may carry a Retry-After header.
+func (c *anthropicClient) responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	message := strings.TrimSpace(string(data))
+	var body struct {
+		Error anthropicError `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		message = body.Error.Type + ": " + body.Error.Message
+	}
+	return &ProviderError{
+		Provider:   "anthropic",
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Err:        errors.New(message),
+	}
+}
+
+func (c *anthropicClient) startSpan(ctx context.Context, name string, req *Request) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, name)
+	span.SetAttributes(
+		attribute.String("llm.provider", "anthropic"),
+		attribute.String("llm.model", c.model),
+		attribute.String("llm.prompt", StripCacheBoundaries(req.Prompt())),
+	)
+	return ctx, span
+}
+
+// finish records usage, metrics and span attributes for a completed call.
+func (c *anthropicClient) finish(ctx context.Context, span trace.Span, start time.Time, model, text string, usage Usage) *Response {
+	if model == "" {
+		model = c.model
+	}
+	RecordUsage(ctx, model, usage)
+	observeCall(model, start, usage, nil)
+
+	span.SetAttributes(attribute.String("llm.response", text))
+	setUsageAttributes(span, usage)
+	slog.Info("LLM API response received",
+		"provider", "anthropic",
+		"model", model,
+		"usage_total_tokens", usage.TotalTokens,
+		"usage_prompt_tokens", usage.PromptTokens,
+		"usage_completion_tokens", usage.CompletionTokens,
+		"usage_cache_read_tokens", usage.CacheReadTokens,
+		"usage_cache_write_tokens", usage.CacheWriteTokens,
+	)
+	return &Response{Text: text, Model: model, Usage: usage}
+}
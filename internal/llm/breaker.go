@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// BreakerConfig controls when a circuit breaker opens and how long it stays open.
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the circuit
+	Cooldown         time.Duration // Time open before a trial call is let through
+}
+
+// DefaultBreakerConfig opens after five consecutive failures for thirty seconds.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{FailureThreshold: 5, Cooldown: 30 * time.Second}
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker stops calling a provider that keeps failing, so requests fail
+// fast (or move on to a fallback) instead of waiting on it.
+type circuitBreaker struct {
+	name string
+	cfg  BreakerConfig
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// WithCircuitBreaker guards a provider's client with a circuit breaker. Only
+// temporary provider errors count as failures; a bad request does not mean
+// the provider is down.
+func WithCircuitBreaker(c Client, name string, cfg BreakerConfig) Client {
+	b := &circuitBreaker{name: name, cfg: cfg}
+	return &decorator{next: c, around: b.call}
+}
+
+func (b *circuitBreaker) call(ctx context.Context, call callFunc) (*Response, error) {
+	if !b.allow() {
+		return nil, fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+	}
+	resp, err := call(ctx)
+	b.record(err)
+	return resp, err
+}
+
+// allow reports whether a call may proceed. Once the cooldown has passed a
+// single trial call is let through to probe the provider.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Now().Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false // A trial call is already in flight.
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// The caller gave up; that says nothing about the provider. Let the
+		// next call run the trial instead.
+		if b.state == breakerHalfOpen {
+			b.state = breakerOpen
+		}
+		return
+	}
+	if err == nil || !IsTemporary(err) {
+		if b.state != breakerClosed {
+			slog.Info("LLM circuit breaker closed", "provider", b.name)
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		if b.state != breakerOpen {
+			slog.Warn("LLM circuit breaker opened", "provider", b.name, "failures", b.failures, "error", err)
+		}
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
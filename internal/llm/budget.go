@@ -0,0 +1,255 @@
+package llm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultOutputTokens is the part of the context window a Budget keeps free
+// for the answer.
+const DefaultOutputTokens = 1024
+
+// defaultContextTokens is assumed for models missing from the limits table.
+const defaultContextTokens = 8192
+
+// contextLimits are the context windows of known models, in tokens. A model
+// matches the longest entry its name starts with, so "gpt-4o-2024-08-06"
+// uses the "gpt-4o" limit. Ollama models are listed at the window Ollama is
+// usually run with rather than the model's maximum.
+var contextLimits = map[string]int{
+	"gpt-3.5-turbo": 16385,
+	"gpt-4":         8192,
+	"gpt-4-32k":     32768,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4.1":       1047576,
+	"o1":            200000,
+	"o3":            200000,
+	"claude-":       200000,
+	"llama3":        8192,
+	"mistral":       32768,
+}
+
+// ContextLimit returns the context window of a model in tokens. Overrides,
+// from MODEL_CONTEXT_LIMITS, take precedence over the built-in table and are
+// matched the same way.
+func ContextLimit(model string, overrides map[string]int) int {
+	for _, limits := range []map[string]int{overrides, contextLimits} {
+		if n, ok := matchLimit(model, limits); ok {
+			return n
+		}
+	}
+	return defaultContextTokens
+}
+
+func matchLimit(model string, limits map[string]int) (int, bool) {
+	best, limit := -1, 0
+	for prefix, n := range limits {
+		if strings.HasPrefix(model, prefix) && len(prefix) > best {
+			best, limit = len(prefix), n
+		}
+	}
+	return limit, best >= 0
+}
+
+// Tokenizer counts the tokens of a text.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// EstimateTokenizer approximates the BPE tokenizers of OpenAI, Anthropic and
+// Llama models without their vocabularies. Short words count as one token and
+// longer words as one more per four letters; punctuation and characters of
+// non-Latin scripts count one each. English prose comes out within about ten
+// percent of cl100k, erring high, which is the safe side for a budget.
+type EstimateTokenizer struct{}
+
+// CountTokens estimates the number of tokens in text.
+func (EstimateTokenizer) CountTokens(text string) int {
+	tokens, word := 0, 0
+	flush := func() {
+		if word > 0 {
+			tokens += 1 + (max(word-6, 0)+3)/4
+			word = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case (unicode.IsLetter(r) && r < 0x250) || unicode.IsDigit(r):
+			word++
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
+
+// messageOverhead is what chat formats add around each message.
+const messageOverhead = 4
+
+// Budget divides a model's context window between the prompt and the answer.
+type Budget struct {
+	ContextTokens int // The model's context window
+	OutputTokens  int // Kept free for the answer
+	Tokenizer     Tokenizer
+}
+
+// NewBudget returns the budget of a model, reserving DefaultOutputTokens for
+// the answer. Tokens are counted with the model's own tokenizer where one is
+// available.
+func NewBudget(model string, overrides map[string]int) *Budget {
+	return &Budget{
+		ContextTokens: ContextLimit(model, overrides),
+		OutputTokens:  DefaultOutputTokens,
+		Tokenizer:     TokenizerFor(model),
+	}
+}
+
+// CountTokens counts the tokens of a text.
+func (b *Budget) CountTokens(text string) int {
+	return b.Tokenizer.CountTokens(text)
+}
+
+// PromptTokens counts the tokens a request sends, including the per-message
+// overhead of chat formats.
+func (b *Budget) PromptTokens(req *Request) int {
+	n := 0
+	for _, msg := range req.Messages {
+		n += messageOverhead + b.CountTokens(msg.Content)
+	}
+	return n
+}
+
+// Remaining is the number of tokens that can still be added to the request
+// while leaving room for the answer. It is negative when the request is
+// already too long.
+func (b *Budget) Remaining(req *Request) int {
+	outputTokens := b.OutputTokens
+	if req.MaxTokens > 0 {
+		outputTokens = req.MaxTokens
+	}
+	return b.ContextTokens - outputTokens - b.PromptTokens(req)
+}
+
+// Fits reports whether the request and its answer fit the context window.
+func (b *Budget) Fits(req *Request) bool {
+	return b.Remaining(req) >= 0
+}
+
+// Chunk splits text into chunks of at most maxTokens, breaking between
+// paragraphs. Each chunk starts with the last paragraphs of the previous one,
+// up to overlap tokens, so that a passage spanning a break is seen whole at
+// least once. Paragraphs longer than maxTokens are split between sentences,
+// and sentences longer than that between words.
+func (b *Budget) Chunk(text string, maxTokens, overlap int) []string {
+	if maxTokens <= 0 {
+		return nil
+	}
+	// An overlap close to the chunk size would leave no room for new text.
+	overlap = min(overlap, maxTokens/4)
+
+	type piece struct {
+		text   string
+		tokens int
+	}
+	var pieces []piece
+	for _, para := range paragraphs(text) {
+		for _, part := range b.split(para, maxTokens) {
+			pieces = append(pieces, piece{text: part, tokens: b.CountTokens(part)})
+		}
+	}
+
+	var chunks []string
+	var current []piece
+	size := 0
+	emit := func() {
+		parts := make([]string, len(current))
+		for i, p := range current {
+			parts[i] = p.text
+		}
+		chunks = append(chunks, strings.Join(parts, "\n\n"))
+	}
+	for _, p := range pieces {
+		if size+p.tokens > maxTokens && len(current) > 0 {
+			emit()
+			// Carry over the trailing pieces that fit the overlap and still
+			// leave room for this one.
+			keep, kept := len(current), 0
+			for keep > 0 && kept+current[keep-1].tokens <= overlap && kept+current[keep-1].tokens+p.tokens <= maxTokens {
+				keep--
+				kept += current[keep].tokens
+			}
+			current, size = append([]piece(nil), current[keep:]...), kept
+		}
+		current = append(current, p)
+		size += p.tokens
+	}
+	if len(current) > 0 {
+		emit()
+	}
+	return chunks
+}
+
+// paragraphs splits text at line breaks, dropping blank lines. Extracted
+// article text has one paragraph per line.
+func paragraphs(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// split breaks a paragraph longer than maxTokens into sentences, and those
+// into runs of words, packing them back into parts of at most maxTokens.
+func (b *Budget) split(para string, maxTokens int) []string {
+	if b.CountTokens(para) <= maxTokens {
+		return []string{para}
+	}
+	var units []string
+	for _, sentence := range sentences(para) {
+		if b.CountTokens(sentence) <= maxTokens {
+			units = append(units, sentence)
+			continue
+		}
+		units = append(units, strings.Fields(sentence)...)
+	}
+
+	var parts, current []string
+	size := 0
+	for _, unit := range units {
+		tokens := b.CountTokens(unit)
+		if size+tokens > maxTokens && len(current) > 0 {
+			parts = append(parts, strings.Join(current, " "))
+			current, size = nil, 0
+		}
+		current = append(current, unit)
+		size += tokens
+	}
+	if len(current) > 0 {
+		parts = append(parts, strings.Join(current, " "))
+	}
+	return parts
+}
+
+// sentences splits text after sentence-ending punctuation followed by a space.
+func sentences(text string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(text)-1; i++ {
+		if strings.IndexByte(".!?", text[i]) >= 0 && text[i+1] == ' ' {
+			out = append(out, strings.TrimSpace(text[start:i+1]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
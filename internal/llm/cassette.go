@@ -0,0 +1,338 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Cassette modes, selected with LLM_CASSETTE_MODE.
+const (
+	CassetteRecord = "record" // Call the provider and save every answer
+	CassetteReplay = "replay" // Serve saved answers without a provider
+)
+
+// cassetteFile is the JSON layout of a cassette. Interactions are sorted so
+// that re-recording only changes the entries that differ.
+type cassetteFile struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+// interaction is one recorded call. The request is kept in full so cassettes
+// can be reviewed; only the hash, model and prompt version identify it.
+type interaction struct {
+	PromptHash    string           `json:"prompt_hash"`
+	Model         string           `json:"model"`
+	PromptVersion string           `json:"prompt_version"`
+	Request       cassetteRequest  `json:"request"`
+	Response      cassetteResponse `json:"response"`
+}
+
+type cassetteMessage struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+}
+
+type cassetteRequest struct {
+	Messages       []cassetteMessage `json:"messages"`
+	ResponseFormat ResponseFormat    `json:"response_format,omitempty"`
+	Output         string            `json:"output,omitempty"` // Schema name
+	Tools          []string          `json:"tools,omitempty"`  // Tool names
+}
+
+type cassetteToolCall struct {
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type cassetteResponse struct {
+	Text      string             `json:"text"`
+	Model     string             `json:"model,omitempty"`
+	Usage     Usage              `json:"usage"`
+	ToolCalls []cassetteToolCall `json:"tool_calls,omitempty"`
+}
+
+// PromptHash identifies a request by everything that shapes the answer: the
+// role-tagged messages, the response format, the output schema and the
+// tools. Sampling options are left out.
+func PromptHash(req *Request) string {
+	h := sha256.New()
+	for _, msg := range req.Messages {
+		fmt.Fprintf(h, "%s\x00%s\x00", msg.Role, msg.Content)
+	}
+	fmt.Fprintf(h, "format\x00%s\x00", req.ResponseFormat)
+	if req.Output != nil {
+		schema, _ := json.Marshal(req.Output.Schema)
+		fmt.Fprintf(h, "output\x00%s\x00%s\x00", req.Output.Name, schema)
+	}
+	for _, tool := range req.Tools {
+		params, _ := json.Marshal(tool.Parameters)
+		fmt.Fprintf(h, "tool\x00%s\x00%s\x00%s\x00", tool.Name, tool.Description, params)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func cassetteKey(hash, model, promptVersion string) string {
+	return hash + "|" + model + "|" + promptVersion
+}
+
+// CassetteMissError reports a request the cassette has no answer for.
+type CassetteMissError struct {
+	Path          string
+	PromptHash    string
+	Model         string
+	PromptVersion string
+	Prompt        string // The start of the flattened prompt
+}
+
+func (e *CassetteMissError) Error() string {
+	return fmt.Sprintf("cassette %s has no answer for prompt %s (model %s, prompt version %s), re-record it with LLM_CASSETTE_MODE=record: %q",
+		e.Path, e.PromptHash[:12], e.Model, e.PromptVersion, e.Prompt)
+}
+
+// cassette holds the interactions of one file.
+type cassette struct {
+	path string
+
+	mu           sync.Mutex
+	interactions map[string]*interaction
+}
+
+// loadCassette reads the cassette at path. A missing file is an empty
+// cassette.
+func loadCassette(path string) (*cassette, error) {
+	c := &cassette{path: path, interactions: make(map[string]*interaction)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	for _, it := range file.Interactions {
+		c.interactions[cassetteKey(it.PromptHash, it.Model, it.PromptVersion)] = it
+	}
+	return c, nil
+}
+
+func (c *cassette) lookup(key string) (*interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, ok := c.interactions[key]
+	return it, ok
+}
+
+// store adds or replaces an interaction and rewrites the file.
+func (c *cassette) store(it *interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions[cassetteKey(it.PromptHash, it.Model, it.PromptVersion)] = it
+
+	file := cassetteFile{Interactions: make([]*interaction, 0, len(c.interactions))}
+	for _, it := range c.interactions {
+		file.Interactions = append(file.Interactions, it)
+	}
+	sort.Slice(file.Interactions, func(i, j int) bool {
+		a, b := file.Interactions[i], file.Interactions[j]
+		return cassetteKey(a.PromptHash, a.Model, a.PromptVersion) < cassetteKey(b.PromptHash, b.Model, b.PromptVersion)
+	})
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so an interrupted run cannot leave a
+	// truncated cassette behind.
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// recorder passes calls to the wrapped client and saves every answer.
+type recorder struct {
+	next          Client
+	cassette      *cassette
+	model         string
+	promptVersion string
+}
+
+// WithRecorder wraps a client so that every successful answer is saved to
+// the cassette at path, keyed by prompt hash, model and prompt version. The
+// model is the one the client is configured with, since replay has no
+// provider to ask. Existing interactions are kept; re-recording a prompt
+// replaces its answer.
+func WithRecorder(next Client, path, model, promptVersion string) (Client, error) {
+	c, err := loadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return newRecorder(next, c, model, promptVersion), nil
+}
+
+// newRecorder records to a loaded cassette, which several recorders may share.
+func newRecorder(next Client, c *cassette, model, promptVersion string) Client {
+	return &recorder{next: next, cassette: c, model: model, promptVersion: promptVersion}
+}
+
+func (r *recorder) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return r.Generate(ctx, NewRequest("", prompt))
+}
+
+func (r *recorder) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return r.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+func (r *recorder) Generate(ctx context.Context, req *Request) (*Response, error) {
+	resp, err := Generate(ctx, r.next, req)
+	return r.record(req, resp, err)
+}
+
+func (r *recorder) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	resp, err := streamRequest(ctx, r.next, req, onChunk)
+	return r.record(req, resp, err)
+}
+
+func (r *recorder) Ping(ctx context.Context) error {
+	return ping(ctx, r.next)
+}
+
+// record saves a successful answer. Failing to write the cassette does not
+// fail the call; the recording is incomplete and replay will say so.
+func (r *recorder) record(req *Request, resp *Response, err error) (*Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	it := &interaction{
+		PromptHash:    PromptHash(req),
+		Model:         r.model,
+		PromptVersion: r.promptVersion,
+		Request:       newCassetteRequest(req),
+		Response: cassetteResponse{
+			Text:  resp.Text,
+			Model: resp.Model,
+			Usage: resp.Usage,
+		},
+	}
+	for _, call := range resp.ToolCalls {
+		it.Response.ToolCalls = append(it.Response.ToolCalls, cassetteToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments})
+	}
+	if err := r.cassette.store(it); err != nil {
+		log.Printf("WARNING: Failed to record LLM answer to cassette %s: %v", r.cassette.path, err)
+	}
+	return resp, nil
+}
+
+func newCassetteRequest(req *Request) cassetteRequest {
+	out := cassetteRequest{ResponseFormat: req.ResponseFormat}
+	for _, msg := range req.Messages {
+		out.Messages = append(out.Messages, cassetteMessage{Role: msg.Role, Content: msg.Content})
+	}
+	if req.Output != nil {
+		out.Output = req.Output.Name
+	}
+	for _, tool := range req.Tools {
+		out.Tools = append(out.Tools, tool.Name)
+	}
+	return out
+}
+
+// replayClient answers from a cassette and never contacts a provider.
+type replayClient struct {
+	cassette      *cassette
+	model         string
+	promptVersion string
+}
+
+// NewReplayClient serves the answers recorded in the cassette at path for the
+// given model and prompt version. A prompt that was not recorded fails with a
+// CassetteMissError, so tests notice when prompts change.
+func NewReplayClient(path, model, promptVersion string) (Client, error) {
+	c, err := loadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.interactions) == 0 {
+		return nil, fmt.Errorf("cassette %s is missing or empty; record it with LLM_CASSETTE_MODE=record", path)
+	}
+	return newReplayClient(c, model, promptVersion), nil
+}
+
+// newReplayClient replays a loaded cassette.
+func newReplayClient(c *cassette, model, promptVersion string) Client {
+	return &replayClient{cassette: c, model: model, promptVersion: promptVersion}
+}
+
+func (c *replayClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+func (c *replayClient) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+// Generate returns the recorded answer, reporting its usage as a live call
+// would.
+func (c *replayClient) Generate(ctx context.Context, req *Request) (*Response, error) {
+	hash := PromptHash(req)
+	it, ok := c.cassette.lookup(cassetteKey(hash, c.model, c.promptVersion))
+	if !ok {
+		prompt := StripCacheBoundaries(req.Prompt())
+		if len(prompt) > 200 {
+			prompt = prompt[:200] + "..."
+		}
+		err := &CassetteMissError{Path: c.cassette.path, PromptHash: hash, Model: c.model, PromptVersion: c.promptVersion, Prompt: prompt}
+		log.Printf("ERROR: %v", err)
+		return nil, err
+	}
+
+	resp := &Response{Text: it.Response.Text, Model: it.Response.Model, Usage: it.Response.Usage}
+	if resp.Model == "" {
+		resp.Model = c.model
+	}
+	for _, call := range it.Response.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments})
+	}
+	RecordUsage(ctx, resp.Model, resp.Usage)
+	return resp, nil
+}
+
+// Stream replays the recorded answer one word at a time.
+func (c *replayClient) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	resp, err := c.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range strings.SplitAfter(resp.Text, " ") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onChunk(word); err != nil {
+			return nil, fmt.Errorf("stream handler aborted: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// Ping always succeeds; replay needs no provider.
+func (c *replayClient) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("llm circuit breaker is open")
+
+// ProviderError is a failed call to an LLM provider. StatusCode is zero when
+// no HTTP response was received, e.g. on a network error.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	RetryAfter time.Duration // Wait requested by the provider, if any
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+	}
+	return fmt.Sprintf("%s: HTTP %d: %v", e.Provider, e.StatusCode, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the call may succeed if repeated: network errors,
+// timeouts, rate limits and server errors.
+func (e *ProviderError) Temporary() bool {
+	switch {
+	case e.StatusCode == 0:
+		return true
+	case e.StatusCode == http.StatusRequestTimeout, e.StatusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return e.StatusCode >= 500
+	}
+}
+
+// IsTemporary reports whether err is a transient provider failure worth
+// retrying. Cancellation and deadline errors never are.
+func IsTemporary(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var partial *partialStreamError
+	if errors.As(err, &partial) {
+		return false
+	}
+	var perr *ProviderError
+	return errors.As(err, &perr) && perr.Temporary()
+}
+
+// ParseRetryAfter reads a Retry-After header value given either in seconds or
+// as an HTTP date. It returns zero when the value is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// partialStreamError marks a stream that failed after chunks were delivered.
+// The caller has already seen part of the answer, so it must not be retried
+// or answered by another provider.
+type partialStreamError struct {
+	err error
+}
+
+func (e *partialStreamError) Error() string { return e.err.Error() }
+
+func (e *partialStreamError) Unwrap() error { return e.err }
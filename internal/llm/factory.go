@@ -7,15 +7,248 @@ import (
 	"strings"
 )
 
-// NewClientFactory reads the config and returns the appropriate LLM client.
+// NewClientFactory reads the config and returns the appropriate LLM client,
+// wrapped with retries, a circuit breaker per provider, the configured
+// fallback chain and a limit on concurrent calls. In cassette record mode the
+// answers are also saved; in replay mode they are served from the cassette
+// and no provider is used.
 func NewClientFactory(ctx context.Context, cfg *config.Config) (Client, error) {
-	provider := strings.ToLower(cfg.LLMProvider)
+	b, err := newClientBuilder(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return b.chain(cfg.LLMProvider, true)
+}
+
+// NewRouterFactory builds the clients of every role. The primary client is
+// the one NewClientFactory returns. LLM_ROLE_MODELS gives a role its own
+// "provider[:model]" in place of LLM_PROVIDER, and LLM_INTENT_MODELS does the
+// same for the synthesis calls of an intent; both keep LLM_FALLBACKS behind
+// them. Roles naming the same provider and model share a client, providers
+// share their circuit breaker across chains, and LLM_MAX_CONCURRENCY caps the
+// calls of all roles together.
+func NewRouterFactory(ctx context.Context, cfg *config.Config) (*Router, error) {
+	b, err := newClientBuilder(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := b.chain(cfg.LLMProvider, true)
+	if err != nil {
+		return nil, err
+	}
+	router := NewRouter(primary, PrimaryModel(cfg))
+
+	for name, spec := range cfg.LLMRoleModels {
+		role := ModelRole(strings.ToLower(name))
+		if !role.Valid() {
+			return nil, fmt.Errorf("unknown LLM role in LLM_ROLE_MODELS: %s. Supported roles: planner, analysis, synthesis, rerank, judge", name)
+		}
+		client, err := b.chain(spec, false)
+		if err != nil {
+			return nil, fmt.Errorf("LLM_ROLE_MODELS %s: %w", name, err)
+		}
+		router.SetRole(role, client, b.model(spec, false))
+	}
+	for intent, spec := range cfg.LLMIntentModels {
+		client, err := b.chain(spec, false)
+		if err != nil {
+			return nil, fmt.Errorf("LLM_INTENT_MODELS %s: %w", intent, err)
+		}
+		// Intents are upper case, e.g. COMPARE_TONE.
+		router.SetIntent(strings.ToUpper(intent), client, b.model(spec, false))
+	}
+	return router, nil
+}
+
+// clientBuilder builds fallback chains, reusing the providers and chains it
+// has already built.
+type clientBuilder struct {
+	ctx       context.Context
+	cfg       *config.Config
+	cassette  *cassette           // Set in record and replay mode
+	slots     chan struct{}       // Shared by every chain; nil for no limit
+	providers map[string]Fallback // By "provider:model"
+	chains    map[string]Client   // By the "provider:model" they start with
+}
+
+func newClientBuilder(ctx context.Context, cfg *config.Config) (*clientBuilder, error) {
+	b := &clientBuilder{
+		ctx:       ctx,
+		cfg:       cfg,
+		providers: make(map[string]Fallback),
+		chains:    make(map[string]Client),
+	}
+	switch cfg.LLMCassetteMode {
+	case "":
+	case CassetteRecord, CassetteReplay:
+		if cfg.LLMCassette == "" {
+			return nil, fmt.Errorf("LLM_CASSETTE_MODE=%s needs a cassette file. Please set the LLM_CASSETTE environment variable", cfg.LLMCassetteMode)
+		}
+		c, err := loadCassette(cfg.LLMCassette)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.LLMCassetteMode == CassetteReplay && len(c.interactions) == 0 {
+			return nil, fmt.Errorf("cassette %s is missing or empty; record it with LLM_CASSETTE_MODE=record", cfg.LLMCassette)
+		}
+		b.cassette = c
+	default:
+		return nil, fmt.Errorf("unknown LLM cassette mode: %s. Supported modes: record, replay", cfg.LLMCassetteMode)
+	}
+	if cfg.LLMMaxConcurrency > 0 {
+		b.slots = make(chan struct{}, cfg.LLMMaxConcurrency)
+	}
+	return b, nil
+}
+
+// parse splits a "provider[:model]" spec, filling in the provider's default
+// model. Model names may contain colons themselves, e.g. "ollama:llama3.1:8b".
+func (b *clientBuilder) parse(spec string, primary bool) (provider, model string) {
+	provider, model, _ = strings.Cut(spec, ":")
+	provider = strings.ToLower(provider)
+	if model == "" {
+		model = defaultModel(b.cfg, provider, primary)
+	}
+	return provider, model
+}
+
+func (b *clientBuilder) model(spec string, primary bool) string {
+	_, model := b.parse(spec, primary)
+	return model
+}
+
+// chain returns the client that calls spec first and LLM_FALLBACKS after it.
+// Cassettes are keyed by the model of spec.
+func (b *clientBuilder) chain(spec string, primary bool) (Client, error) {
+	provider, model := b.parse(spec, primary)
+	key := provider + ":" + model
+	if client, ok := b.chains[key]; ok {
+		return client, nil
+	}
+	if b.cfg.LLMCassetteMode == CassetteReplay {
+		client := newReplayClient(b.cassette, model, b.cfg.PromptVersion)
+		b.chains[key] = client
+		return client, nil
+	}
+
+	specs := append([]string{spec}, b.cfg.LLMFallbacks...)
+	chain := make([]Fallback, 0, len(specs))
+	for i, spec := range specs {
+		fallback, err := b.provider(spec, primary && i == 0)
+		if err != nil {
+			return nil, err
+		}
+		// The mock only stands in for a real model as a last resort.
+		fallback.Degraded = fallback.Name == "mock" && len(chain) > 0
+		chain = append(chain, fallback)
+	}
+
+	client := chain[0].Client
+	if len(chain) > 1 {
+		client = WithFallback(chain...)
+	}
+	if b.slots != nil {
+		client = withSlots(client, b.slots)
+	}
+	if b.cfg.LLMCassetteMode == CassetteRecord {
+		client = newRecorder(client, b.cassette, model, b.cfg.PromptVersion)
+	}
+	b.chains[key] = client
+	return client, nil
+}
+
+// provider returns the client of a single provider with its retries and
+// circuit breaker.
+func (b *clientBuilder) provider(spec string, primary bool) (Fallback, error) {
+	provider, model := b.parse(spec, primary)
+	if fallback, ok := b.providers[provider+":"+model]; ok {
+		return fallback, nil
+	}
+	client, err := newProviderClient(b.ctx, b.cfg, provider, model)
+	if err != nil {
+		return Fallback{}, err
+	}
+
+	name := provider
+	if provider != "mock" {
+		name = provider + ":" + model
+		if b.cfg.LLMMaxAttempts > 1 {
+			client = WithRetry(client, RetryPolicy{
+				MaxAttempts: b.cfg.LLMMaxAttempts,
+				BaseDelay:   b.cfg.LLMRetryBaseDelay,
+				MaxDelay:    b.cfg.LLMRetryMaxDelay,
+			})
+		}
+		if b.cfg.LLMBreakerFailures > 0 {
+			client = WithCircuitBreaker(client, name, BreakerConfig{
+				FailureThreshold: b.cfg.LLMBreakerFailures,
+				Cooldown:         b.cfg.LLMBreakerCooldown,
+			})
+		}
+	}
+	fallback := Fallback{Name: name, Client: client}
+	b.providers[provider+":"+model] = fallback
+	return fallback, nil
+}
+
+// PrimaryModel returns the model of the primary provider, the one answers
+// normally come from. Only the provider name is case-insensitive; the model
+// is kept as given.
+func PrimaryModel(cfg *config.Config) string {
+	provider, model, _ := strings.Cut(cfg.LLMProvider, ":")
+	if model == "" {
+		model = defaultModel(cfg, strings.ToLower(provider), true)
+	}
+	return model
+}
+
+// defaultOllamaURL is where a local Ollama server listens by default.
+const defaultOllamaURL = "http://localhost:11434"
+
+// defaultModel picks the model for a provider given without one. LLM_MODEL
+// applies to the primary provider only.
+func defaultModel(cfg *config.Config, provider string, primary bool) string {
+	if primary && cfg.LLMModel != "" {
+		return cfg.LLMModel
+	}
+	switch provider {
+	case "ollama":
+		return "llama3.1"
+	case "anthropic":
+		return "claude-3-5-sonnet-latest"
+	}
+	return cfg.OpenAIModel
+}
+
+// newProviderClient creates the client for a single provider. Each provider
+// has its own server address, so that a role or fallback on another
+// provider does not inherit LLM_BASE_URL.
+func newProviderClient(ctx context.Context, cfg *config.Config, provider, model string) (Client, error) {
 	switch provider {
 	case "openai":
-		return newOpenAIClient(ctx, cfg.OpenAIAPIKey, cfg.OpenAIModel)
+		return newOpenAIClient(ctx, cfg.OpenAIAPIKey, model)
+	case "openai-compatible":
+		return newOpenAICompatibleClient(ctx, cfg.LLMBaseURL, cfg.LLMAPIKey, model)
+	case "ollama":
+		return newOllamaClient(providerBaseURL(cfg, provider, cfg.OllamaBaseURL, defaultOllamaURL), model, ContextLimit(model, cfg.ModelContextLimits)), nil
+	case "anthropic":
+		return newAnthropicClient(providerBaseURL(cfg, provider, cfg.AnthropicBaseURL, defaultAnthropicURL), cfg.AnthropicAPIKey, model)
 	case "mock":
-		return newMockClient(), nil
+		return newMockClient(cfg.LLMMockFixture)
 	default:
-		return nil, fmt.Errorf("unknown or unsupported LLM provider: %s. Supported providers: openai, mock", cfg.LLMProvider)
+		return nil, fmt.Errorf("unknown or unsupported LLM provider: %s. Supported providers: openai, openai-compatible, ollama, anthropic, mock", provider)
+	}
+}
+
+// providerBaseURL returns the provider's own server address or, failing
+// that, LLM_BASE_URL when the provider is LLM_PROVIDER, or the default.
+func providerBaseURL(cfg *config.Config, provider, own, fallback string) string {
+	if own != "" {
+		return own
+	}
+	primary, _, _ := strings.Cut(cfg.LLMProvider, ":")
+	if strings.EqualFold(provider, primary) && cfg.LLMBaseURL != "" {
+		return cfg.LLMBaseURL
 	}
+	return fallback
 }
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Fallback is one provider in a fallback chain.
+type Fallback struct {
+	Name   string
+	Client Client
+	// Degraded marks answers from this provider as lower quality, e.g. the
+	// mock client used as a last resort.
+	Degraded bool
+}
+
+// fallbackClient tries each provider in turn until one answers.
+type fallbackClient struct {
+	chain []Fallback
+}
+
+// WithFallback answers with the first provider in the chain that succeeds.
+// Answers from a provider marked Degraded are flagged on the response and on
+// the context's usage meter.
+func WithFallback(chain ...Fallback) Client {
+	return &fallbackClient{chain: chain}
+}
+
+func (f *fallbackClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return f.Generate(ctx, NewRequest("", prompt))
+}
+
+func (f *fallbackClient) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return f.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+func (f *fallbackClient) Generate(ctx context.Context, req *Request) (*Response, error) {
+	return f.try(ctx, func(ctx context.Context, c Client) (*Response, error) {
+		return Generate(ctx, c, req)
+	})
+}
+
+func (f *fallbackClient) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	return f.try(ctx, func(ctx context.Context, c Client) (*Response, error) {
+		return streamRequest(ctx, c, req, onChunk)
+	})
+}
+
+// Ping checks the primary provider; a fallback answering is already degraded
+// service.
+func (f *fallbackClient) Ping(ctx context.Context) error {
+	if len(f.chain) == 0 {
+		return nil
+	}
+	return ping(ctx, f.chain[0].Client)
+}
+
+func (f *fallbackClient) try(ctx context.Context, call func(context.Context, Client) (*Response, error)) (*Response, error) {
+	var errs []error
+	for i, provider := range f.chain {
+		resp, err := call(ctx, provider.Client)
+		if err == nil {
+			if provider.Degraded {
+				resp.Degraded = true
+				MarkDegraded(ctx)
+			}
+			if i > 0 {
+				slog.Warn("LLM call served by fallback provider", "provider", provider.Name, "degraded", provider.Degraded)
+			}
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name, err))
+		var partial *partialStreamError
+		if ctx.Err() != nil || errors.As(err, &partial) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+// WithConcurrencyLimit caps the number of calls in flight; further calls wait
+// for a slot or for their context to end.
+func WithConcurrencyLimit(c Client, limit int) Client {
+	return withSlots(c, make(chan struct{}, limit))
+}
+
+// withSlots limits calls to the capacity of slots, which several clients may
+// share.
+func withSlots(c Client, slots chan struct{}) Client {
+	return &decorator{next: c, around: func(ctx context.Context, call callFunc) (*Response, error) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for an LLM call slot: %w", ctx.Err())
+		}
+		defer func() { <-slots }()
+		return call(ctx)
+	}}
+}
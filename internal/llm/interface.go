@@ -4,10 +4,36 @@ import "context"
 
 // Response is a standardized struct for any LLM's output.
 type Response struct {
-	Text string
+	Text  string
+	Model string // Model that produced the text, as reported by the provider
+	Usage Usage
+	// Degraded is set when a last-resort fallback produced the text.
+	Degraded bool
+	// ToolCalls are the calls the model made to the request's tools.
+	ToolCalls []ToolCall
+	// Cached is set when the answer came from the response cache; Usage is
+	// then that of the original call, and no tokens were consumed.
+	Cached bool
 }
 
 // Client is a universal interface for any generative AI model.
 type Client interface {
 	GenerateContent(ctx context.Context, prompt string) (*Response, error)
 }
+
+// HealthChecker is implemented by clients that can cheaply verify their
+// provider is reachable.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// StreamHandler receives each partial chunk of text as the model produces it.
+// Returning an error aborts the stream.
+type StreamHandler func(chunk string) error
+
+// StreamingClient is implemented by clients that can emit their output token-by-token.
+// The returned Response contains the fully assembled text.
+type StreamingClient interface {
+	Client
+	StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error)
+}
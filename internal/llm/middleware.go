@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// callFunc performs one call to the wrapped client.
+type callFunc func(ctx context.Context) (*Response, error)
+
+// decorator applies around to every generate and stream call made through it,
+// so one policy covers both. It forwards health checks to the wrapped client.
+type decorator struct {
+	next   Client
+	around func(ctx context.Context, call callFunc) (*Response, error)
+}
+
+func (d *decorator) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return d.Generate(ctx, NewRequest("", prompt))
+}
+
+func (d *decorator) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return d.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+func (d *decorator) Generate(ctx context.Context, req *Request) (*Response, error) {
+	return d.around(ctx, func(ctx context.Context) (*Response, error) {
+		return Generate(ctx, d.next, req)
+	})
+}
+
+func (d *decorator) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	return d.around(ctx, func(ctx context.Context) (*Response, error) {
+		return streamRequest(ctx, d.next, req, onChunk)
+	})
+}
+
+func (d *decorator) Ping(ctx context.Context) error {
+	return ping(ctx, d.next)
+}
+
+// streamRequest streams from clients that support it and delivers the whole
+// answer as a single chunk from those that do not. A failure after the first
+// chunk is reported as a partial stream.
+func streamRequest(ctx context.Context, c Client, req *Request, onChunk StreamHandler) (*Response, error) {
+	started := false
+	tracked := func(chunk string) error {
+		started = true
+		return onChunk(chunk)
+	}
+
+	var resp *Response
+	var err error
+	switch client := c.(type) {
+	case MessageClient:
+		resp, err = client.Stream(ctx, req, tracked)
+	case StreamingClient:
+		resp, err = client.StreamContent(ctx, req.Prompt(), tracked)
+	default:
+		resp, err = c.GenerateContent(ctx, req.Prompt())
+		if err != nil {
+			return nil, err
+		}
+		if err := onChunk(resp.Text); err != nil {
+			return nil, &partialStreamError{err: err}
+		}
+		return resp, nil
+	}
+
+	var partial *partialStreamError
+	if err != nil && started && !errors.As(err, &partial) {
+		return nil, &partialStreamError{err: err}
+	}
+	return resp, err
+}
+
+// ping checks the client's provider when it supports health checks.
+func ping(ctx context.Context, c Client) error {
+	if checker, ok := c.(HealthChecker); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
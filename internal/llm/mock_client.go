@@ -1,88 +1,306 @@
 package llm
 
 import (
+	"bytes"
 	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
 	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-// mockClient is a mock implementation for testing purposes.
-type mockClient struct{}
+// defaultMockFixture holds the rules used when no fixture file is configured.
+//
+//go:embed mock_fixture.yaml
+var defaultMockFixture []byte
 
-// newMockClient creates a mock LLM client for testing.
-func newMockClient() Client {
-	return &mockClient{}
+// mockFixture is the layout of a fixture file. JSON fixtures work as well,
+// since JSON is valid YAML.
+type mockFixture struct {
+	Rules []mockRule `yaml:"rules"`
 }
 
-// GenerateContent returns a mock response based on the prompt content.
-func (c *mockClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
-	// Simple mock responses based on prompt keywords
-	lowerPrompt := strings.ToLower(prompt)
+// mockRule answers the requests it matches. Rules are tried in order and
+// the first match wins.
+type mockRule struct {
+	Name    string        `yaml:"name"`
+	Match   mockMatch     `yaml:"match"`
+	Respond mockRespond   `yaml:"respond"`
+	Latency time.Duration `yaml:"latency"` // e.g. "250ms"
+
+	regex     *regexp.Regexp
+	text      *template.Template
+	arguments []*template.Template // One per tool call
+}
+
+// mockMatch lists the conditions a request must meet; all that are set must
+// hold. A rule without conditions matches every request.
+type mockMatch struct {
+	Template string `yaml:"template"` // Name of the prompt template, e.g. "planner"
+	Regex    string `yaml:"regex"`    // Searched for in the flattened prompt
+	URL      string `yaml:"url"`      // A URL the prompt contains; "*" for any URL
+}
+
+// mockRespond is the answer: static text, templated text, tool calls or an
+// error. Templates use text/template with the data of mockData.
+type mockRespond struct {
+	Text      string         `yaml:"text"`
+	Template  string         `yaml:"template"`
+	ToolCalls []mockToolCall `yaml:"tool_calls"`
+	Error     string         `yaml:"error"`
+	Status    int            `yaml:"status"` // HTTP status reported with the error, e.g. 503
+}
+
+// mockToolCall calls a tool. Arguments are a mapping, or a template that
+// renders a JSON object.
+type mockToolCall struct {
+	Name      string `yaml:"name"`
+	Arguments any    `yaml:"arguments"`
+}
+
+// mockData is what response templates can refer to.
+type mockData struct {
+	Prompt   string   // The flattened prompt, without cache markers
+	Template string   // Name of the prompt template, if any
+	URL      string   // The first URL in the prompt
+	URLs     []string // Every URL in the prompt, in order
+	Matches  []string // The regex match and its groups
+}
+
+var (
+	urlPattern = regexp.MustCompile(`https?://[^\s"'<>()]+`)
+
+	mockFuncs = template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"truncate": func(n int, s string) string {
+			if len(s) > n {
+				return s[:n]
+			}
+			return s
+		},
+	}
+)
 
-	// Check if this is a planner prompt (contains "Determine the intent" and "Respond in JSON format")
-	if strings.Contains(lowerPrompt, "determine the intent") && strings.Contains(lowerPrompt, "respond in json format") {
-		// Extract URL from the prompt if present
-		url := ""
-		if strings.Contains(prompt, "https://techcrunch.com/2025/10/02/last-chance-alert-founder-and-investor-bundle-savings-for-techcrunch-disrupt-2025-ends-tomorrow/") {
-			url = "https://techcrunch.com/2025/10/02/last-chance-alert-founder-and-investor-bundle-savings-for-techcrunch-disrupt-2025-ends-tomorrow/"
-		} else if strings.Contains(prompt, "https://techcrunch.com/2025/07/26/astronomer-winks-at-viral-notoriety-with-temporary-spokesperson-gwyneth-paltrow/") {
-			url = "https://techcrunch.com/2025/07/26/astronomer-winks-at-viral-notoriety-with-temporary-spokesperson-gwyneth-paltrow/"
-		} else if strings.Contains(prompt, "https://techcrunch.com/2025/07/26/allianz-life-says-majority-of-customers-personal-data-stolen-in-cyberattack/") {
-			url = "https://techcrunch.com/2025/07/26/allianz-life-says-majority-of-customers-personal-data-stolen-in-cyberattack/"
-		} else if strings.Contains(prompt, "https://techcrunch.com/2025/07/27/itch-io-is-the-latest-marketplace-to-crack-down-on-adult-games/") {
-			url = "https://techcrunch.com/2025/07/27/itch-io-is-the-latest-marketplace-to-crack-down-on-adult-games/"
-		} else if strings.Contains(prompt, "https://techcrunch.com/2025/07/26/tesla-vet-says-that-reviewing-real-products-not-mockups-is-the-key-to-staying-innovative/") {
-			url = "https://techcrunch.com/2025/07/26/tesla-vet-says-that-reviewing-real-products-not-mockups-is-the-key-to-staying-innovative/"
-		} else if strings.Contains(prompt, "https://edition.cnn.com/2025/07/24/tech/intel-layoffs-15-percent-q2-earnings") {
-			url = "https://edition.cnn.com/2025/07/24/tech/intel-layoffs-15-percent-q2-earnings"
+// mockClient answers from the rules of a fixture, for demos and tests that
+// should not depend on a provider.
+type mockClient struct {
+	rules []mockRule
+}
+
+// newMockClient creates a mock client from the fixture at path, or from the
+// built-in rules when path is empty.
+func newMockClient(path string) (Client, error) {
+	data := defaultMockFixture
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read mock fixture: %w", err)
 		}
+	} else {
+		path = "built-in mock fixture"
+	}
 
-		// Check if user is asking about articles in general
-		if strings.Contains(lowerPrompt, "what articles") || strings.Contains(lowerPrompt, "list articles") || strings.Contains(lowerPrompt, "articles do you have") {
-			return &Response{
-				Text: `{"intent": "UNKNOWN", "targets": [], "parameters": []}`,
-			}, nil
+	var fixture mockFixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for i := range fixture.Rules {
+		if err := fixture.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("%s: rule %d (%s): %w", path, i+1, fixture.Rules[i].Name, err)
 		}
+	}
+	return &mockClient{rules: fixture.Rules}, nil
+}
 
-		if url != "" {
-			return &Response{
-				Text: fmt.Sprintf(`{"intent": "SUMMARIZE", "targets": ["%s"], "parameters": []}`, url),
-			}, nil
+// compile checks the rule and parses its pattern and templates.
+func (r *mockRule) compile() error {
+	var err error
+	if r.Match.Regex != "" {
+		if r.regex, err = regexp.Compile(r.Match.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+
+	kinds := 0
+	for _, set := range []bool{r.Respond.Text != "", r.Respond.Template != "", len(r.Respond.ToolCalls) > 0, r.Respond.Error != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New("respond must set exactly one of text, template, tool_calls or error")
+	}
+
+	if r.Respond.Template != "" {
+		if r.text, err = template.New(r.Name).Funcs(mockFuncs).Parse(r.Respond.Template); err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+	}
+	for _, call := range r.Respond.ToolCalls {
+		source, ok := call.Arguments.(string)
+		if !ok {
+			data, err := json.Marshal(call.Arguments)
+			if err != nil {
+				return fmt.Errorf("invalid arguments for %s: %w", call.Name, err)
+			}
+			source = string(data)
 		}
+		tmpl, err := template.New(call.Name).Funcs(mockFuncs).Parse(source)
+		if err != nil {
+			return fmt.Errorf("invalid arguments template for %s: %w", call.Name, err)
+		}
+		r.arguments = append(r.arguments, tmpl)
+	}
+	return nil
+}
 
-		return &Response{
-			Text: `{"intent": "SUMMARIZE", "targets": [], "parameters": []}`,
-		}, nil
+// match reports whether the rule applies, with the regex submatches.
+func (r *mockRule) match(req *Request, data *mockData) ([]string, bool) {
+	if r.Match.Template != "" && r.Match.Template != req.Template {
+		return nil, false
+	}
+	if r.Match.URL == "*" && len(data.URLs) == 0 {
+		return nil, false
 	}
+	if r.Match.URL != "" && r.Match.URL != "*" && !strings.Contains(data.Prompt, r.Match.URL) {
+		return nil, false
+	}
+	if r.regex == nil {
+		return nil, true
+	}
+	matches := r.regex.FindStringSubmatch(data.Prompt)
+	return matches, matches != nil
+}
+
+// mockModel is reported as the model name for every mock response.
+const mockModel = "mock"
 
-	if strings.Contains(lowerPrompt, "summarize") || strings.Contains(lowerPrompt, "summary") {
-		return &Response{
-			Text: "This is a mock summary of the article. The article discusses the main topic and provides key insights about the subject matter.",
-		}, nil
+// GenerateContent answers the prompt sent as a single user message.
+func (c *mockClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+// Generate answers with the first matching rule, after its latency. Token
+// usage is approximated by word counts; options are ignored.
+func (c *mockClient) Generate(ctx context.Context, req *Request) (*Response, error) {
+	prompt := StripCacheBoundaries(req.Prompt())
+	data := &mockData{Prompt: prompt, Template: req.Template, URLs: urlPattern.FindAllString(prompt, -1)}
+	if len(data.URLs) > 0 {
+		data.URL = data.URLs[0]
 	}
 
-	if strings.Contains(lowerPrompt, "keywords") || strings.Contains(lowerPrompt, "key topics") {
-		return &Response{
-			Text: "Key topics: technology, innovation, business strategy, product development",
-		}, nil
+	for i := range c.rules {
+		rule := &c.rules[i]
+		matches, ok := rule.match(req, data)
+		if !ok {
+			continue
+		}
+		data.Matches = matches
+
+		if rule.Latency > 0 {
+			select {
+			case <-time.After(rule.Latency):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		resp, err := rule.respond(data)
+		if err != nil {
+			return nil, err
+		}
+		resp.Model = mockModel
+		resp.Usage = Usage{
+			PromptTokens:     len(strings.Fields(prompt)),
+			CompletionTokens: len(strings.Fields(resp.Text)),
+		}
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+		RecordUsage(ctx, resp.Model, resp.Usage)
+		return resp, nil
 	}
+	return nil, fmt.Errorf("no mock rule matches the prompt: %q", data.Prompt[:min(80, len(data.Prompt))])
+}
 
-	if strings.Contains(lowerPrompt, "sentiment") {
-		return &Response{
-			Text: "The sentiment of this article is generally positive, focusing on opportunities and growth.",
-		}, nil
+// respond builds the rule's answer.
+func (r *mockRule) respond(data *mockData) (*Response, error) {
+	switch {
+	case r.Respond.Error != "":
+		err := errors.New(r.Respond.Error)
+		if r.Respond.Status != 0 {
+			return nil, &ProviderError{Provider: "mock", StatusCode: r.Respond.Status, Err: err}
+		}
+		return nil, err
+	case r.text != nil:
+		text, err := execute(r.text, data)
+		if err != nil {
+			return nil, fmt.Errorf("mock rule %s: %w", r.Name, err)
+		}
+		return &Response{Text: text}, nil
+	case len(r.arguments) > 0:
+		resp := &Response{}
+		for i, call := range r.Respond.ToolCalls {
+			args, err := execute(r.arguments[i], data)
+			if err != nil {
+				return nil, fmt.Errorf("mock rule %s: %w", r.Name, err)
+			}
+			if !json.Valid([]byte(args)) {
+				return nil, fmt.Errorf("mock rule %s: arguments for %s are not valid JSON: %s", r.Name, call.Name, args)
+			}
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				ID:        fmt.Sprintf("mock-call-%d", i+1),
+				Name:      call.Name,
+				Arguments: json.RawMessage(args),
+			})
+		}
+		return resp, nil
 	}
+	return &Response{Text: r.Respond.Text}, nil
+}
 
-	if strings.Contains(lowerPrompt, "compare") {
-		return &Response{
-			Text: "When comparing these articles, the main differences lie in their focus areas and the perspectives they present.",
-		}, nil
+func execute(tmpl *template.Template, data *mockData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
 	}
+	return buf.String(), nil
+}
+
+// Ping always succeeds; the mock has no provider to reach.
+func (c *mockClient) Ping(ctx context.Context) error {
+	return nil
+}
 
-	// Default mock response
-	return &Response{
-		Text: fmt.Sprintf("Mock response to: %s", prompt[:min(50, len(prompt))]),
-	}, nil
+// StreamContent streams the response to the prompt one word at a time.
+func (c *mockClient) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+// Stream produces the same response as Generate, emitted one word at a time.
+func (c *mockClient) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	resp, err := c.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.SplitAfter(resp.Text, " ")
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onChunk(word); err != nil {
+			return nil, fmt.Errorf("stream handler aborted: %w", err)
+		}
+	}
+	return resp, nil
 }
 
 func min(a, b int) int {
@@ -0,0 +1,308 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ollamaClient talks to an Ollama server's /api/chat endpoint, so articles
+// can be analyzed by a locally hosted model.
+type ollamaClient struct {
+	baseURL       string
+	model         string
+	contextTokens int // Sent as num_ctx; Ollama otherwise truncates prompts to its default window
+	httpClient    *http.Client
+}
+
+// newOllamaClient creates a client for the Ollama server at baseURL. The server
+// is not contacted until the first call; /readyz reports whether it is up.
+// contextTokens is the window the token budget assumes for the model.
+func newOllamaClient(baseURL, model string, contextTokens int) Client {
+	return &ollamaClient{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		model:         model,
+		contextTokens: contextTokens,
+		httpClient:    &http.Client{},
+	}
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+// ollamaFunction is a tool definition or, with Arguments set, a call to one.
+type ollamaFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  *Schema         `json:"parameters,omitempty"`
+	Arguments   json.RawMessage `json:"arguments,omitempty"` // A JSON object, not a string as with OpenAI
+}
+
+type ollamaTool struct {
+	Type     string         `json:"type"`
+	Function ollamaFunction `json:"function"`
+}
+
+type ollamaToolCall struct {
+	Function ollamaFunction `json:"function"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   any             `json:"format,omitempty"` // "json" or a JSON schema
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+// ollamaChatResponse is the whole answer, or one line of a streamed answer.
+// Token counts are only set on the final ("done") line.
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+func (r *ollamaChatResponse) usage() Usage {
+	return Usage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+// Ping verifies the server is reachable by listing its local models.
+func (c *ollamaClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/tags", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama server unhealthy: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GenerateContent sends the prompt as a single user message.
+func (c *ollamaClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+// StreamContent streams the answer to the prompt sent as a single user message.
+func (c *ollamaClient) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+// Generate asks for the whole answer in a single response.
+func (c *ollamaClient) Generate(ctx context.Context, req *Request) (*Response, error) {
+	ctx, span := c.startSpan(ctx, "LLM.GenerateContent", req)
+	defer span.End()
+	start := time.Now()
+
+	body, err := c.chat(ctx, req, false)
+	if err != nil {
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("ollama API call failed: %w", err)
+	}
+	defer body.Close()
+
+	var result ollamaChatResponse
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		err = &ProviderError{Provider: "ollama", Err: fmt.Errorf("invalid response: %w", err)}
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("ollama API call failed: %w", err)
+	}
+
+	resp := c.finish(ctx, span, start, result.Model, result.Message.Content, result.usage())
+	for _, call := range result.Message.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{Name: call.Function.Name, Arguments: call.Function.Arguments})
+	}
+	return resp, nil
+}
+
+// Stream reads the newline-delimited JSON stream, passing each fragment to
+// onChunk.
+func (c *ollamaClient) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	ctx, span := c.startSpan(ctx, "LLM.StreamContent", req)
+	defer span.End()
+	start := time.Now()
+
+	body, err := c.chat(ctx, req, true)
+	if err != nil {
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("ollama streaming API call failed: %w", err)
+	}
+	defer body.Close()
+
+	var text strings.Builder
+	var final ollamaChatResponse
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			err = &ProviderError{Provider: "ollama", Err: fmt.Errorf("invalid stream line: %w", err)}
+			span.RecordError(err)
+			observeCall(c.model, start, Usage{}, err)
+			return nil, fmt.Errorf("ollama stream failed: %w", err)
+		}
+		if chunk.Error != "" {
+			err := &ProviderError{Provider: "ollama", Err: errors.New(chunk.Error)}
+			span.RecordError(err)
+			observeCall(c.model, start, Usage{}, err)
+			return nil, fmt.Errorf("ollama stream failed: %w", err)
+		}
+		if delta := chunk.Message.Content; delta != "" {
+			text.WriteString(delta)
+			if err := onChunk(delta); err != nil {
+				span.RecordError(err)
+				return nil, fmt.Errorf("stream handler aborted: %w", err)
+			}
+		}
+		if chunk.Done {
+			final = chunk
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		err = &ProviderError{Provider: "ollama", Err: err}
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("ollama stream failed: %w", err)
+	}
+
+	return c.finish(ctx, span, start, final.Model, text.String(), final.usage()), nil
+}
+
+// chat posts to /api/chat and returns the response body of a successful
+// call.
+func (c *ollamaClient) chat(ctx context.Context, req *Request, stream bool) (io.ReadCloser, error) {
+	messages := make([]ollamaMessage, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		messages = append(messages, ollamaMessage{Role: string(msg.Role), Content: StripCacheBoundaries(msg.Content)})
+	}
+	options := map[string]any{"temperature": req.Temperature} // 0 gives deterministic output, as with OpenAI
+	if c.contextTokens > 0 {
+		options["num_ctx"] = c.contextTokens
+	}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if len(req.Stop) > 0 {
+		options["stop"] = req.Stop
+	}
+	chatReq := ollamaChatRequest{
+		Model:    c.model,
+		Messages: messages,
+		Stream:   stream,
+		Options:  options,
+	}
+	switch {
+	case req.Output != nil:
+		chatReq.Format = req.Output.Schema
+	case req.ResponseFormat == FormatJSON:
+		chatReq.Format = "json"
+	}
+	for _, tool := range req.Tools {
+		chatReq.Tools = append(chatReq.Tools, ollamaTool{
+			Type:     "function",
+			Function: ollamaFunction{Name: tool.Name, Description: tool.Description, Parameters: tool.Parameters},
+		})
+	}
+
+	payload, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/chat", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &ProviderError{Provider: "ollama", Err: err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			message = apiErr.Error
+		}
+		return nil, &ProviderError{
+			Provider:   "ollama",
+			StatusCode: resp.StatusCode,
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Err:        errors.New(message),
+		}
+	}
+	return resp.Body, nil
+}
+
+func (c *ollamaClient) startSpan(ctx context.Context, name string, req *Request) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, name)
+	span.SetAttributes(
+		attribute.String("llm.provider", "ollama"),
+		attribute.String("llm.model", c.model),
+		attribute.String("llm.prompt", StripCacheBoundaries(req.Prompt())),
+	)
+	return ctx, span
+}
+
+// finish records usage, metrics and span attributes for a completed call.
+func (c *ollamaClient) finish(ctx context.Context, span trace.Span, start time.Time, model, text string, usage Usage) *Response {
+	if model == "" {
+		model = c.model
+	}
+	RecordUsage(ctx, model, usage)
+	observeCall(model, start, usage, nil)
+
+	span.SetAttributes(attribute.String("llm.response", text))
+	setUsageAttributes(span, usage)
+	slog.Info("LLM API response received",
+		"provider", "ollama",
+		"model", model,
+		"usage_total_tokens", usage.TotalTokens,
+		"usage_prompt_tokens", usage.PromptTokens,
+		"usage_completion_tokens", usage.CompletionTokens,
+	)
+	return &Response{Text: text, Model: model, Usage: usage}
+}
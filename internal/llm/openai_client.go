@@ -2,79 +2,282 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"article-chat-system/internal/metrics"
 
 	"github.com/sashabaranov/go-openai"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 )
 
-// openaiClient is the concrete implementation for the OpenAI provider.
+// openaiClient is the concrete implementation for the OpenAI provider and for
+// servers exposing the same API, such as llama.cpp and vLLM.
 type openaiClient struct {
-	client *openai.Client
-	model  string
+	client   *openai.Client
+	model    string
+	provider string // Reported in spans, logs and errors
 }
 
 var tracer = otel.Tracer("llm-openai-client") // Create a tracer for this package
 
+// observeCall records an LLM call's latency and, when it succeeded, the tokens
+// it consumed.
+func observeCall(model string, start time.Time, usage Usage, err error) {
+	metrics.ObserveSince(metrics.LLMDuration.WithLabelValues(model, metrics.Outcome(err)), start)
+	if err == nil {
+		metrics.LLMTokens.WithLabelValues(model, "prompt").Add(float64(usage.PromptTokens))
+		metrics.LLMTokens.WithLabelValues(model, "completion").Add(float64(usage.CompletionTokens))
+	}
+}
+
+// retryAfterKey carries the *time.Duration a retryAfterTransport fills in.
+type retryAfterKey struct{}
+
+// withRetryAfterHint returns a context whose request records the provider's
+// Retry-After header, if any, in the returned duration.
+func withRetryAfterHint(ctx context.Context) (context.Context, *time.Duration) {
+	hint := new(time.Duration)
+	return context.WithValue(ctx, retryAfterKey{}, hint), hint
+}
+
+// retryAfterTransport captures Retry-After headers, which go-openai does not
+// expose on its errors.
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		if hint, ok := req.Context().Value(retryAfterKey{}).(*time.Duration); ok {
+			*hint = ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+	}
+	return resp, err
+}
+
+// providerError classifies a go-openai error so the retry and circuit breaker
+// layers can tell transient failures from bad requests.
+func (c *openaiClient) providerError(err error, retryAfter time.Duration) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	perr := &ProviderError{Provider: c.provider, RetryAfter: retryAfter, Err: err}
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		perr.StatusCode = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		perr.StatusCode = reqErr.HTTPStatusCode
+	}
+	return perr
+}
+
+// openaiUsage converts go-openai's usage, including automatically cached
+// prompt tokens.
+func openaiUsage(u openai.Usage) Usage {
+	usage := Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+	if u.PromptTokensDetails != nil {
+		usage.CacheReadTokens = u.PromptTokensDetails.CachedTokens
+	}
+	return usage
+}
+
 // newOpenAIClient creates a client for interacting with OpenAI.
 func newOpenAIClient(ctx context.Context, apiKey string, model string) (Client, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("OpenAI API key is missing. Please set the OPENAI_API_KEY environment variable")
 	}
 
-	client := openai.NewClient(apiKey)
+	return dialOpenAI(ctx, "openai", openai.DefaultConfig(apiKey), model)
+}
+
+// newOpenAICompatibleClient creates a client for a self-hosted server that
+// implements the OpenAI API under baseURL (e.g. "http://localhost:8000/v1").
+// Such servers usually ignore the API key, so it may be empty.
+func newOpenAICompatibleClient(ctx context.Context, baseURL, apiKey, model string) (Client, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("the openai-compatible provider needs a base URL. Please set the LLM_BASE_URL environment variable")
+	}
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = strings.TrimSuffix(baseURL, "/")
+	return dialOpenAI(ctx, "openai-compatible", config, model)
+}
+
+// dialOpenAI creates the client and checks the server is reachable.
+func dialOpenAI(ctx context.Context, provider string, config openai.ClientConfig, model string) (Client, error) {
+	config.HTTPClient = &http.Client{Transport: &retryAfterTransport{base: http.DefaultTransport}}
+	client := openai.NewClientWithConfig(config)
 
 	// Test the connection by making a simple request
 	_, err := client.ListModels(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to OpenAI API: %w", err)
+		return nil, fmt.Errorf("failed to connect to %s API: %w", provider, err)
 	}
 
-	log.Println("Successfully initialized OpenAI client.")
+	log.Printf("Successfully initialized %s client.", provider)
 	return &openaiClient{
-		client: client,
-		model:  model,
+		client:   client,
+		model:    model,
+		provider: provider,
 	}, nil
 }
 
-// GenerateContent calls the OpenAI API and adapts its response to our universal format.
+// Ping verifies the API key and connectivity by listing the available models.
+func (c *openaiClient) Ping(ctx context.Context) error {
+	if _, err := c.client.ListModels(ctx); err != nil {
+		return fmt.Errorf("%s API unreachable: %w", c.provider, err)
+	}
+	return nil
+}
+
+// noJSONSchemaModels are the prefixes of OpenAI models that reject the
+// json_schema response format with a 400 and only accept json_object.
+var noJSONSchemaModels = []string{
+	"gpt-3.5", "gpt-4-", "gpt-4o-2024-05-13", "chatgpt-4o", "o1-mini", "o1-preview", "davinci", "babbage",
+}
+
+// supportsJSONSchema reports whether a model accepts the json_schema response
+// format. Fine-tuned models ("ft:gpt-3.5-turbo:...") and models named through
+// a proxy ("openai/gpt-3.5-turbo") are judged by their base model; models
+// other than OpenAI's, such as those of vLLM or llama.cpp, are assumed to
+// accept it.
+func supportsJSONSchema(model string) bool {
+	model = strings.ToLower(strings.TrimPrefix(model, "ft:"))
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	if model == "gpt-4" {
+		return false
+	}
+	for _, prefix := range noJSONSchemaModels {
+		if strings.HasPrefix(model, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// chatRequest converts a request to go-openai's form. OpenAI caches long
+// prompt prefixes automatically, so cache markers are dropped. A structured
+// output is requested through a non-strict json_schema response format, since
+// strict mode would require every field. Models without json_schema get the
+// schema in the system message and the json_object format instead; the answer
+// is then only checked against the schema by GenerateObject. Tools are sent
+// as functions.
+func (c *openaiClient) chatRequest(req *Request) openai.ChatCompletionRequest {
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages)+1)
+	for _, msg := range req.Messages {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    string(msg.Role),
+			Content: StripCacheBoundaries(msg.Content),
+		})
+	}
+	jsonSchema := supportsJSONSchema(c.model)
+	if req.Output != nil && !jsonSchema {
+		messages = withSchemaInstruction(messages, req.Output)
+	}
+	chatReq := openai.ChatCompletionRequest{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stop:        req.Stop,
+	}
+	switch {
+	case req.Output != nil && jsonSchema:
+		schema, _ := json.Marshal(req.Output.Schema)
+		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:        req.Output.Name,
+				Description: req.Output.Description,
+				Schema:      json.RawMessage(schema),
+			},
+		}
+	case req.Output != nil || req.ResponseFormat == FormatJSON:
+		chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	}
+	for _, tool := range req.Tools {
+		params, _ := json.Marshal(tool.Parameters)
+		chatReq.Tools = append(chatReq.Tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  json.RawMessage(params),
+			},
+		})
+	}
+	return chatReq
+}
+
+// withSchemaInstruction tells the model the schema its JSON answer must
+// follow, in the system message. The json_object format also requires the
+// messages to mention JSON.
+func withSchemaInstruction(messages []openai.ChatCompletionMessage, output *StructuredOutput) []openai.ChatCompletionMessage {
+	schema, _ := json.Marshal(output.Schema)
+	instruction := fmt.Sprintf("Reply with a single JSON object matching this JSON schema:\n%s", schema)
+	if len(messages) > 0 && messages[0].Role == string(RoleSystem) {
+		messages[0].Content += "\n\n" + instruction
+		return messages
+	}
+	return append([]openai.ChatCompletionMessage{{Role: string(RoleSystem), Content: instruction}}, messages...)
+}
+
+// GenerateContent sends the prompt as a single user message.
 func (c *openaiClient) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+// Generate calls the OpenAI API and adapts its response to our universal format.
+func (c *openaiClient) Generate(ctx context.Context, req *Request) (*Response, error) {
 	// 1. Start a new span. The 'ctx' carries the parent span's context.
 	ctx, span := tracer.Start(ctx, "LLM.GenerateContent")
 	defer span.End() // Ensure the span is ended when the function returns.
 
 	// 2. Add the prompt to the span as an attribute.
 	span.SetAttributes(
-		attribute.String("llm.provider", "openai"),
+		attribute.String("llm.provider", c.provider),
 		attribute.String("llm.model", c.model),
-		attribute.String("llm.prompt", prompt),
+		attribute.String("llm.prompt", StripCacheBoundaries(req.Prompt())),
 	)
+	start := time.Now()
+	ctx, retryAfter := withRetryAfterHint(ctx)
 
-	resp, err := c.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model:       c.model,
-			Temperature: 0.0, // Set temperature to 0 for deterministic output
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-		},
-	)
+	resp, err := c.client.CreateChatCompletion(ctx, c.chatRequest(req))
 	if err != nil {
 		span.RecordError(err) // Record any errors that occur.
-		return nil, fmt.Errorf("openai API call failed: %w", err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("openai API call failed: %w", c.providerError(err, *retryAfter))
+	}
+
+	usage := openaiUsage(resp.Usage)
+	model := resp.Model
+	if model == "" {
+		model = c.model
 	}
+	RecordUsage(ctx, model, usage)
+	observeCall(model, start, usage, nil)
 
 	if len(resp.Choices) == 0 {
 		responseText := "Received an empty response from the model."
 		span.SetAttributes(attribute.String("llm.response", responseText))
-		return &Response{Text: responseText}, nil
+		return &Response{Text: responseText, Model: model, Usage: usage}, nil
 	}
 
 	responseText := resp.Choices[0].Message.Content
@@ -84,7 +287,7 @@ func (c *openaiClient) GenerateContent(ctx context.Context, prompt string) (*Res
 	// 4. Add token usage information to the trace and log
 	// Log usage information for debugging
 	slog.Info("LLM API response received",
-		"provider", "openai",
+		"provider", c.provider,
 		"model", c.model,
 		"usage_total_tokens", resp.Usage.TotalTokens,
 		"usage_prompt_tokens", resp.Usage.PromptTokens,
@@ -92,15 +295,111 @@ func (c *openaiClient) GenerateContent(ctx context.Context, prompt string) (*Res
 		"usage_struct", resp.Usage, // Log the entire usage struct
 	)
 
-	if resp.Usage.TotalTokens > 0 {
-		span.SetAttributes(
-			attribute.Int("llm.usage.prompt_tokens", resp.Usage.PromptTokens),
-			attribute.Int("llm.usage.completion_tokens", resp.Usage.CompletionTokens),
-			attribute.Int("llm.usage.total_tokens", resp.Usage.TotalTokens),
+	setUsageAttributes(span, usage)
+
+	var toolCalls []ToolCall
+	for _, call := range resp.Choices[0].Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: json.RawMessage(call.Function.Arguments),
+		})
+	}
+
+	return &Response{
+		Text:      responseText,
+		Model:     model,
+		Usage:     usage,
+		ToolCalls: toolCalls,
+	}, nil
+}
+
+// StreamContent streams the answer to the prompt sent as a single user message.
+func (c *openaiClient) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+// Stream calls the OpenAI streaming API and forwards each delta to onChunk.
+func (c *openaiClient) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	ctx, span := tracer.Start(ctx, "LLM.StreamContent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("llm.provider", c.provider),
+		attribute.String("llm.model", c.model),
+		attribute.String("llm.prompt", StripCacheBoundaries(req.Prompt())),
+	)
+	start := time.Now()
+	ctx, retryAfter := withRetryAfterHint(ctx)
+
+	chatReq := c.chatRequest(req)
+	chatReq.Stream = true
+	chatReq.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	stream, err := c.client.CreateChatCompletionStream(ctx, chatReq)
+	if err != nil {
+		span.RecordError(err)
+		observeCall(c.model, start, Usage{}, err)
+		return nil, fmt.Errorf("openai streaming API call failed: %w", c.providerError(err, *retryAfter))
+	}
+	defer stream.Close()
+
+	var text strings.Builder
+	var usage *openai.Usage
+	model := c.model
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			span.RecordError(err)
+			observeCall(model, start, Usage{}, err)
+			return nil, fmt.Errorf("openai stream failed: %w", c.providerError(err, 0))
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+		text.WriteString(delta)
+		if err := onChunk(delta); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("stream handler aborted: %w", err)
+		}
+	}
+
+	responseText := text.String()
+	span.SetAttributes(attribute.String("llm.response", responseText))
+
+	var total Usage
+	if usage != nil {
+		total = openaiUsage(*usage)
+	}
+	RecordUsage(ctx, model, total)
+	observeCall(model, start, total, nil)
+
+	if usage != nil && usage.TotalTokens > 0 {
+		slog.Info("LLM API stream completed",
+			"provider", c.provider,
+			"model", c.model,
+			"usage_total_tokens", usage.TotalTokens,
+			"usage_prompt_tokens", usage.PromptTokens,
+			"usage_completion_tokens", usage.CompletionTokens,
 		)
 	}
+	setUsageAttributes(span, total)
 
 	return &Response{
-		Text: responseText,
+		Text:  responseText,
+		Model: model,
+		Usage: total,
 	}, nil
 }
@@ -0,0 +1,34 @@
+package llm
+
+import "strings"
+
+// CacheBoundary marks the end of a cacheable prefix in a prompt. Prompt
+// templates place it after their static parts (instructions, article
+// context); providers with explicit prompt caching cache everything before
+// each boundary, and the other clients strip it.
+const CacheBoundary = "<<<cache-boundary>>>"
+
+// StripCacheBoundaries removes the cache markers from a prompt.
+func StripCacheBoundaries(prompt string) string {
+	return strings.ReplaceAll(prompt, CacheBoundary, "")
+}
+
+// promptSegment is a piece of a prompt between cache boundaries.
+type promptSegment struct {
+	Text      string
+	Cacheable bool // Followed by a boundary
+}
+
+// splitCacheBoundaries cuts a prompt at its cache boundaries, dropping empty
+// segments.
+func splitCacheBoundaries(prompt string) []promptSegment {
+	parts := strings.Split(prompt, CacheBoundary)
+	segments := make([]promptSegment, 0, len(parts))
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		segments = append(segments, promptSegment{Text: part, Cacheable: i < len(parts)-1})
+	}
+	return segments
+}
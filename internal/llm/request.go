@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// Role identifies who authored a message.
+type Role string
+
+const (
+	RoleSystem    Role = "system"    // Instructions from the application
+	RoleUser      Role = "user"      // Input, including untrusted article text
+	RoleAssistant Role = "assistant" // Earlier model output
+)
+
+// Message is one role-tagged part of a request.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// ResponseFormat constrains the shape of the model's answer.
+type ResponseFormat string
+
+const (
+	FormatText ResponseFormat = ""     // Free text
+	FormatJSON ResponseFormat = "json" // A single JSON object
+)
+
+// Request is a message-based generation request. Zero-valued options keep
+// the client's defaults, as for a plain prompt.
+type Request struct {
+	Messages       []Message
+	Temperature    float32
+	MaxTokens      int
+	Stop           []string
+	ResponseFormat ResponseFormat
+	// Output, if set, is the schema a JSON answer must follow.
+	Output *StructuredOutput
+	// Tools, if set, are offered to the model, which may call any number of
+	// them. Calls are reported in Response.ToolCalls by Generate only.
+	Tools []Tool
+	// Template names the prompt template the request was rendered from, if
+	// any. Providers ignore it.
+	Template string
+	// NoCache makes the response cache pass the request to the provider and
+	// replace the cached answer with the fresh one.
+	NoCache bool
+}
+
+// NewRequest builds a request from system instructions and user input. An
+// empty system part is left out.
+func NewRequest(system, user string) *Request {
+	req := &Request{}
+	if system != "" {
+		req.Messages = append(req.Messages, Message{Role: RoleSystem, Content: system})
+	}
+	req.Messages = append(req.Messages, Message{Role: RoleUser, Content: user})
+	return req
+}
+
+// Prompt flattens the messages into a single prompt, for clients that only
+// accept one.
+func (r *Request) Prompt() string {
+	parts := make([]string, 0, len(r.Messages))
+	for _, msg := range r.Messages {
+		parts = append(parts, msg.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// MessageClient is implemented by clients that send role-tagged messages and
+// generation options to their provider.
+type MessageClient interface {
+	Client
+	Generate(ctx context.Context, req *Request) (*Response, error)
+	Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error)
+}
+
+// Generate sends the request through c, flattening it into a single prompt
+// when c does not accept messages. Such clients are asked to describe any
+// tool calls in JSON.
+func Generate(ctx context.Context, c Client, req *Request) (*Response, error) {
+	if mc, ok := c.(MessageClient); ok {
+		return mc.Generate(ctx, req)
+	}
+	if len(req.Tools) > 0 {
+		return generateWithTextTools(ctx, c, req)
+	}
+	return c.GenerateContent(ctx, req.Prompt())
+}
+
+// Stream streams the answer to the request from c. Clients that cannot stream
+// deliver the whole answer as a single chunk.
+func Stream(ctx context.Context, c Client, req *Request, onChunk StreamHandler) (*Response, error) {
+	return streamRequest(ctx, c, req, onChunk)
+}
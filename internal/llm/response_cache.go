@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"article-chat-system/internal/metrics"
+)
+
+// Response cache backends, selected with LLM_CACHE.
+const (
+	ResponseCachePostgres = "postgres"
+	ResponseCacheDisk     = "disk"
+)
+
+// CacheEntry is one cached answer.
+type CacheEntry struct {
+	Key           string          `json:"key"`
+	Model         string          `json:"model"`
+	PromptVersion string          `json:"prompt_version"`
+	Response      json.RawMessage `json:"response"` // The answer, in the cassette's response layout
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ResponseStore persists cached answers.
+type ResponseStore interface {
+	// GetResponse returns the entry stored under key, or nil when there is
+	// none, and marks it as used.
+	GetResponse(ctx context.Context, key string) (*CacheEntry, error)
+	// PutResponse adds or replaces an entry.
+	PutResponse(ctx context.Context, entry *CacheEntry) error
+	// PruneResponses deletes the entries created before cutoff, unless it is
+	// zero, and then the least recently used ones beyond maxEntries, if it is
+	// positive. It returns the number of entries deleted.
+	PruneResponses(ctx context.Context, cutoff time.Time, maxEntries int) (int, error)
+}
+
+// ResponseCacheConfig sets what a response cache keys on and how long it
+// serves answers.
+type ResponseCacheConfig struct {
+	Model         string
+	PromptVersion string
+	TTL           time.Duration // Older answers are misses; zero keeps them forever
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose LLM calls skip the response cache, as
+// if every request set NoCache.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func bypassCache(ctx context.Context, req *Request) bool {
+	bypass, _ := ctx.Value(noCacheKey{}).(bool)
+	return bypass || req.NoCache
+}
+
+// responseCache answers repeated requests from a store instead of the
+// provider.
+type responseCache struct {
+	next  Client
+	store ResponseStore
+	cfg   ResponseCacheConfig
+}
+
+// WithResponseCache wraps a client so that answers are stored and identical
+// requests are served from the store. Requests are identified by the model,
+// the prompt version, the prompt hash and the sampling options. Requests
+// with NoCache, or made with a WithoutCache context, always reach the
+// provider; their answers replace the cached ones. Failed and degraded
+// answers are not cached, nor are answers from a fallback model, which would
+// otherwise be served as the configured model's. A failing store only costs
+// the cache. The
+// store is pruned by PruneResponseCache, not by the calls.
+func WithResponseCache(next Client, store ResponseStore, cfg ResponseCacheConfig) Client {
+	return &responseCache{next: next, store: store, cfg: cfg}
+}
+
+// cacheKey extends the prompt hash with what else shapes the answer.
+func (c *responseCache) cacheKey(req *Request) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%g\x00%d\x00%q",
+		c.cfg.Model, c.cfg.PromptVersion, PromptHash(req), req.Temperature, req.MaxTokens, req.Stop)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *responseCache) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+func (c *responseCache) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+func (c *responseCache) Generate(ctx context.Context, req *Request) (*Response, error) {
+	key := c.cacheKey(req)
+	if resp := c.lookup(ctx, req, key); resp != nil {
+		return resp, nil
+	}
+	resp, err := Generate(ctx, c.next, req)
+	if err != nil {
+		return nil, err
+	}
+	c.save(ctx, key, resp)
+	return resp, nil
+}
+
+// Stream delivers a cached answer as a single chunk.
+func (c *responseCache) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	key := c.cacheKey(req)
+	if resp := c.lookup(ctx, req, key); resp != nil {
+		if err := onChunk(resp.Text); err != nil {
+			return nil, fmt.Errorf("stream handler aborted: %w", err)
+		}
+		return resp, nil
+	}
+	resp, err := streamRequest(ctx, c.next, req, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	c.save(ctx, key, resp)
+	return resp, nil
+}
+
+func (c *responseCache) Ping(ctx context.Context) error {
+	return ping(ctx, c.next)
+}
+
+// lookup returns the cached answer to the request, or nil.
+func (c *responseCache) lookup(ctx context.Context, req *Request, key string) *Response {
+	if bypassCache(ctx, req) {
+		metrics.LLMCacheLookups.WithLabelValues("bypass").Inc()
+		return nil
+	}
+	entry, err := c.store.GetResponse(ctx, key)
+	if err != nil {
+		log.Printf("WARNING: LLM response cache lookup failed: %v", err)
+	}
+	if entry == nil || (c.cfg.TTL > 0 && time.Since(entry.CreatedAt) > c.cfg.TTL) {
+		metrics.LLMCacheLookups.WithLabelValues("miss").Inc()
+		return nil
+	}
+
+	var cached cassetteResponse
+	if err := json.Unmarshal(entry.Response, &cached); err != nil {
+		log.Printf("WARNING: Ignoring unreadable LLM response cache entry %s: %v", key[:12], err)
+		metrics.LLMCacheLookups.WithLabelValues("miss").Inc()
+		return nil
+	}
+	metrics.LLMCacheLookups.WithLabelValues("hit").Inc()
+	resp := &Response{Text: cached.Text, Model: cached.Model, Usage: cached.Usage, Cached: true}
+	for _, call := range cached.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments})
+	}
+	return resp
+}
+
+// save stores an answer of the configured model.
+func (c *responseCache) save(ctx context.Context, key string, resp *Response) {
+	if resp.Degraded || !c.fromModel(resp.Model) {
+		return
+	}
+	cached := cassetteResponse{Text: resp.Text, Model: resp.Model, Usage: resp.Usage}
+	for _, call := range resp.ToolCalls {
+		cached.ToolCalls = append(cached.ToolCalls, cassetteToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments})
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	entry := &CacheEntry{Key: key, Model: c.cfg.Model, PromptVersion: c.cfg.PromptVersion, Response: data, CreatedAt: time.Now()}
+	if err := c.store.PutResponse(ctx, entry); err != nil {
+		log.Printf("WARNING: Failed to store LLM response in cache: %v", err)
+	}
+}
+
+// fromModel reports whether an answer reported as coming from model came
+// from the configured model. Providers report the snapshot an alias points
+// to, so "gpt-4o-2024-08-06" is gpt-4o and "claude-3-5-sonnet-20241022" is
+// claude-3-5-sonnet-latest. An answer without a model is taken as the
+// configured model's.
+func (c *responseCache) fromModel(model string) bool {
+	if model == "" || model == c.cfg.Model {
+		return true
+	}
+	snapshot, ok := strings.CutPrefix(model, strings.TrimSuffix(c.cfg.Model, "-latest")+"-")
+	return ok && snapshot != "" && unicode.IsDigit(rune(snapshot[0]))
+}
+
+// PruneResponseCache deletes the answers older than ttl and the least
+// recently used ones beyond maxEntries right away and then every interval,
+// until ctx is cancelled. Pruning scans the whole store, so it runs apart
+// from the calls rather than delaying one of them.
+func PruneResponseCache(ctx context.Context, store ResponseStore, ttl time.Duration, maxEntries int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var cutoff time.Time
+		if ttl > 0 {
+			cutoff = time.Now().Add(-ttl)
+		}
+		if n, err := store.PruneResponses(ctx, cutoff, maxEntries); err != nil && ctx.Err() == nil {
+			log.Printf("WARNING: Failed to prune LLM response cache: %v", err)
+		} else if n > 0 {
+			metrics.LLMCacheEvictions.Add(float64(n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
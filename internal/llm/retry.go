@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how transient provider failures are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts, including the first
+	BaseDelay   time.Duration // Delay before the first retry; doubled each time
+	MaxDelay    time.Duration // Upper bound on any delay, including a Retry-After
+}
+
+// DefaultRetryPolicy retries up to twice, starting at half a second.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 20 * time.Second}
+}
+
+// backoff returns the wait before retry n (1-based): full jitter over an
+// exponentially growing window. A Retry-After from the provider wins, unless
+// it exceeds MaxDelay; then backoff reports false and the call is not
+// retried, so that a fallback provider can answer instead of the request
+// hanging for as long as the provider asks.
+func (p RetryPolicy) backoff(n int, err error) (time.Duration, bool) {
+	var perr *ProviderError
+	if errors.As(err, &perr) && perr.RetryAfter > 0 {
+		return perr.RetryAfter, perr.RetryAfter <= p.MaxDelay
+	}
+	window := p.BaseDelay << (n - 1)
+	if window <= 0 || window > p.MaxDelay {
+		window = p.MaxDelay
+	}
+	if window <= 0 {
+		return 0, true
+	}
+	return rand.N(window) + 1, true
+}
+
+// WithRetry retries calls that fail with a temporary provider error, waiting
+// with exponential backoff and jitter between attempts.
+func WithRetry(c Client, policy RetryPolicy) Client {
+	return &decorator{next: c, around: func(ctx context.Context, call callFunc) (*Response, error) {
+		for attempt := 1; ; attempt++ {
+			resp, err := call(ctx)
+			if err == nil || attempt >= policy.MaxAttempts || !IsTemporary(err) {
+				return resp, err
+			}
+
+			wait, ok := policy.backoff(attempt, err)
+			if !ok {
+				slog.Warn("Not retrying LLM call; the provider asks to wait too long", "retry_after", wait, "max_delay", policy.MaxDelay, "error", err)
+				return nil, err
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return nil, err // The retry could not finish in time anyway.
+			}
+			slog.Warn("Retrying LLM call", "attempt", attempt, "wait", wait, "error", err)
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			case <-timer.C:
+			}
+		}
+	}}
+}
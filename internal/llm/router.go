@@ -0,0 +1,168 @@
+package llm
+
+import "context"
+
+// ModelRole names a kind of LLM call that can be served by its own model, so
+// that cheap calls such as planning need not run on the model that writes
+// answers. Not to be confused with the Role of a message.
+type ModelRole string
+
+const (
+	RolePlanner   ModelRole = "planner"   // Turns a query into a plan
+	RoleAnalysis  ModelRole = "analysis"  // Analyzes articles at ingestion
+	RoleSynthesis ModelRole = "synthesis" // Writes the answers of strategies
+	RoleRerank    ModelRole = "rerank"    // Orders search results by relevance
+	RoleJudge     ModelRole = "judge"     // Grades or compares answers
+)
+
+// ModelRoles lists every model role.
+var ModelRoles = []ModelRole{RolePlanner, RoleAnalysis, RoleSynthesis, RoleRerank, RoleJudge}
+
+// Valid reports whether r is one of ModelRoles.
+func (r ModelRole) Valid() bool {
+	for _, role := range ModelRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type intentKey struct{}
+
+// WithIntent returns a context whose synthesis calls are routed to the model
+// configured for the intent, if any.
+func WithIntent(ctx context.Context, intent string) context.Context {
+	return context.WithValue(ctx, intentKey{}, intent)
+}
+
+// IntentFrom returns the intent attached to the context, if any.
+func IntentFrom(ctx context.Context) string {
+	intent, _ := ctx.Value(intentKey{}).(string)
+	return intent
+}
+
+// route is a client and the model it calls.
+type route struct {
+	client Client
+	model  string
+}
+
+// Router hands each role its client. Roles without a client of their own use
+// the primary one.
+type Router struct {
+	primary route
+	roles   map[ModelRole]route
+	intents map[string]route
+}
+
+// NewRouter returns a router that sends every role to the primary client.
+func NewRouter(primary Client, model string) *Router {
+	return &Router{
+		primary: route{client: primary, model: model},
+		roles:   make(map[ModelRole]route),
+		intents: make(map[string]route),
+	}
+}
+
+// SetRole routes the calls of a role to a client.
+func (r *Router) SetRole(role ModelRole, client Client, model string) {
+	r.roles[role] = route{client: client, model: model}
+}
+
+// SetIntent routes the synthesis calls made for an intent, as marked with
+// WithIntent, to a client. It takes precedence over the synthesis role.
+func (r *Router) SetIntent(intent string, client Client, model string) {
+	r.intents[intent] = route{client: client, model: model}
+}
+
+// Primary returns the primary client.
+func (r *Router) Primary() Client {
+	return r.primary.client
+}
+
+func (r *Router) route(role ModelRole) route {
+	if rt, ok := r.roles[role]; ok {
+		return rt
+	}
+	return r.primary
+}
+
+// Client returns the client of a role. The synthesis client picks the model
+// of the intent in the call's context when one is configured.
+func (r *Router) Client(role ModelRole) Client {
+	if role == RoleSynthesis && len(r.intents) > 0 {
+		return &intentRouter{router: r}
+	}
+	return r.route(role).client
+}
+
+// Model returns the model a role calls. Synthesis may call other models for
+// intents with their own.
+func (r *Router) Model(role ModelRole) string {
+	return r.route(role).model
+}
+
+// IntentModel returns the model the synthesis calls of an intent use.
+func (r *Router) IntentModel(intent string) string {
+	if rt, ok := r.intents[intent]; ok {
+		return rt.model
+	}
+	return r.Model(RoleSynthesis)
+}
+
+// Wrap replaces every client with wrap's result, e.g. to add a response
+// cache keyed by the client's model. Clients shared by several roles are
+// wrapped once. Call it before handing out clients.
+func (r *Router) Wrap(wrap func(client Client, model string) Client) {
+	wrapped := make(map[Client]Client)
+	apply := func(rt route) route {
+		if c, ok := wrapped[rt.client]; ok {
+			return route{client: c, model: rt.model}
+		}
+		c := wrap(rt.client, rt.model)
+		wrapped[rt.client] = c
+		return route{client: c, model: rt.model}
+	}
+	r.primary = apply(r.primary)
+	for role, rt := range r.roles {
+		r.roles[role] = apply(rt)
+	}
+	for intent, rt := range r.intents {
+		r.intents[intent] = apply(rt)
+	}
+}
+
+// intentRouter sends each synthesis call to the client of the intent in its
+// context, or to the synthesis client.
+type intentRouter struct {
+	router *Router
+}
+
+func (c *intentRouter) target(ctx context.Context) Client {
+	if rt, ok := c.router.intents[IntentFrom(ctx)]; ok {
+		return rt.client
+	}
+	return c.router.route(RoleSynthesis).client
+}
+
+func (c *intentRouter) GenerateContent(ctx context.Context, prompt string) (*Response, error) {
+	return c.Generate(ctx, NewRequest("", prompt))
+}
+
+func (c *intentRouter) StreamContent(ctx context.Context, prompt string, onChunk StreamHandler) (*Response, error) {
+	return c.Stream(ctx, NewRequest("", prompt), onChunk)
+}
+
+func (c *intentRouter) Generate(ctx context.Context, req *Request) (*Response, error) {
+	return Generate(ctx, c.target(ctx), req)
+}
+
+func (c *intentRouter) Stream(ctx context.Context, req *Request, onChunk StreamHandler) (*Response, error) {
+	return streamRequest(ctx, c.target(ctx), req, onChunk)
+}
+
+// Ping checks the synthesis client.
+func (c *intentRouter) Ping(ctx context.Context) error {
+	return ping(ctx, c.router.route(RoleSynthesis).client)
+}
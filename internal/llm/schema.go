@@ -0,0 +1,245 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of JSON Schema used to describe structured output.
+type Schema struct {
+	Type        string             `json:"type,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Format      string             `json:"format,omitempty"` // Only "date" is validated
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+}
+
+// SchemaEnumer is implemented by string types that only take a fixed set of
+// values, such as the planner's intents.
+type SchemaEnumer interface {
+	SchemaEnum() []string
+}
+
+var (
+	enumerType = reflect.TypeOf((*SchemaEnumer)(nil)).Elem()
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// SchemaFor derives a schema from a Go value's type, following its json
+// tags. Fields are required unless tagged omitempty. Two more tags refine the
+// schema:
+//
+//	desc:"..."                         the field's description
+//	jsonschema:"optional,min=0,max=1"  optional field, numeric bounds
+//	jsonschema:"enum=a|b|c"            allowed string values
+//	jsonschema:"format=date"           a YYYY-MM-DD date string
+func SchemaFor(v any) *Schema {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+func schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(enumerType) {
+		values := reflect.Zero(t).Interface().(SchemaEnumer).SchemaEnum()
+		return &Schema{Type: "string", Enum: values}
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Description: "RFC 3339 timestamp"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	return &Schema{} // Any value
+}
+
+func structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := schemaForType(field.Type)
+		prop.Description = field.Tag.Get("desc")
+		required := !strings.Contains(","+opts+",", ",omitempty,")
+		for _, opt := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(opt, "=")
+			switch key {
+			case "optional":
+				required = false
+			case "min":
+				if f, err := strconv.ParseFloat(value, 64); err == nil {
+					prop.Minimum = &f
+				}
+			case "max":
+				if f, err := strconv.ParseFloat(value, 64); err == nil {
+					prop.Maximum = &f
+				}
+			case "enum":
+				prop.Enum = strings.Split(value, "|")
+			case "format":
+				prop.Format = value
+			}
+		}
+
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// Validate checks a decoded JSON value against the schema and reports every
+// violation, each prefixed with the path of the offending field. Null is
+// accepted for arrays and objects, which decode to nil.
+func (s *Schema) Validate(v any) error {
+	var errs []error
+	s.validate("", v, &errs)
+	return errors.Join(errs...)
+}
+
+func (s *Schema) validate(path string, v any, errs *[]error) {
+	fail := func(format string, args ...any) {
+		where := path
+		if where == "" {
+			where = "(root)"
+		}
+		*errs = append(*errs, fmt.Errorf("%s: %s", where, fmt.Sprintf(format, args...)))
+	}
+	if s == nil || s.Type == "" {
+		return
+	}
+	if v == nil {
+		if s.Type != "array" && s.Type != "object" {
+			fail("expected %s, got null", s.Type)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail("expected an object, got %s", jsonType(v))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fail("missing required field %q", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value, ok := obj[name]; ok {
+				s.Properties[name].validate(joinPath(path, name), value, errs)
+			}
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			fail("expected an array, got %s", jsonType(v))
+			return
+		}
+		for i, item := range items {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("expected a string, got %s", jsonType(v))
+			return
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			fail("%q is not one of %s", str, strings.Join(s.Enum, ", "))
+		}
+		if s.Format == "date" {
+			if _, err := time.Parse(time.DateOnly, str); err != nil {
+				fail("%q is not a YYYY-MM-DD date", str)
+			}
+		}
+	case "number", "integer":
+		num, ok := v.(float64)
+		if !ok {
+			fail("expected a %s, got %s", s.Type, jsonType(v))
+			return
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			fail("expected an integer, got %v", num)
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			fail("%v is below the minimum of %v", num, *s.Minimum)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			fail("%v is above the maximum of %v", num, *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("expected a boolean, got %s", jsonType(v))
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonType names the JSON type of a value decoded into an interface.
+func jsonType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "null"
+}
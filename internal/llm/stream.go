@@ -0,0 +1,17 @@
+package llm
+
+import "context"
+
+type streamHandlerKey struct{}
+
+// WithStreamHandler returns a context that asks downstream LLM calls to stream
+// their output to the given handler instead of returning it all at once.
+func WithStreamHandler(ctx context.Context, onChunk StreamHandler) context.Context {
+	return context.WithValue(ctx, streamHandlerKey{}, onChunk)
+}
+
+// StreamHandlerFromContext returns the stream handler attached to the context, if any.
+func StreamHandlerFromContext(ctx context.Context) (StreamHandler, bool) {
+	onChunk, ok := ctx.Value(streamHandlerKey{}).(StreamHandler)
+	return onChunk, ok && onChunk != nil
+}
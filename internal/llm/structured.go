@@ -0,0 +1,197 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"article-chat-system/internal/metrics"
+)
+
+// DefaultRepairAttempts is how many times GenerateObject asks the model to
+// correct an answer that does not match the schema.
+const DefaultRepairAttempts = 2
+
+// StructuredOutput describes the JSON object a request must produce.
+// Providers that support it constrain the answer to the schema: OpenAI
+// through a json_schema response format, Ollama through its format field and
+// Anthropic by forcing a call to a tool taking the object as input.
+type StructuredOutput struct {
+	Name        string // Identifier for the schema or tool, e.g. "query_plan"
+	Description string
+	Schema      *Schema
+}
+
+// Validator is implemented by output types with constraints a schema cannot
+// express. It runs after the answer has been decoded.
+type Validator interface {
+	Validate() error
+}
+
+// OutputError reports an answer that still did not match its schema once the
+// repair attempts were used up.
+type OutputError struct {
+	Name     string // Schema name
+	Raw      string // The model's last answer
+	Attempts int
+	Err      error
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("invalid %s after %d attempts: %v", e.Name, e.Attempts, e.Err)
+}
+
+func (e *OutputError) Unwrap() error { return e.Err }
+
+// GenerateObject sends the request in JSON mode and decodes the answer into
+// out, a pointer to a struct. The schema is derived from out unless the
+// request sets one. An answer that cannot be parsed or fails validation is
+// sent back with the error, asking the model to correct it, up to repairs
+// times. Provider errors are returned as is; retrying them is the client's
+// job.
+func GenerateObject(ctx context.Context, c Client, req *Request, out any, repairs int) (*Response, error) {
+	r := *req
+	r.Messages = append([]Message(nil), req.Messages...)
+	r.ResponseFormat = FormatJSON
+	if r.Output == nil {
+		r.Output = &StructuredOutput{Name: schemaName(out), Schema: SchemaFor(out)}
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := Generate(ctx, c, &r)
+		if err != nil {
+			return nil, err
+		}
+		err = DecodeObject(resp.Text, r.Output.Schema, out)
+		if err == nil {
+			outcome := "valid"
+			if attempt > 1 {
+				outcome = "repaired"
+			}
+			metrics.StructuredOutputs.WithLabelValues(r.Output.Name, outcome).Inc()
+			return resp, nil
+		}
+		if attempt > repairs {
+			metrics.StructuredOutputs.WithLabelValues(r.Output.Name, "invalid").Inc()
+			return nil, &OutputError{Name: r.Output.Name, Raw: resp.Text, Attempts: attempt, Err: err}
+		}
+
+		slog.Warn("Asking the model to repair its structured output", "schema", r.Output.Name, "attempt", attempt, "error", err)
+		r.Messages = append(r.Messages,
+			Message{Role: RoleAssistant, Content: resp.Text},
+			Message{Role: RoleUser, Content: repairInstruction(err)},
+		)
+	}
+}
+
+// repairInstruction tells the model what was wrong with its last answer.
+func repairInstruction(err error) string {
+	return "Your previous answer was not valid:\n" + err.Error() +
+		"\n\nReply with only the corrected JSON object, matching the required schema, and no other text."
+}
+
+// DecodeObject extracts the JSON object from a model's answer, checks it
+// against the schema and decodes it into out. Types implementing Validator
+// are validated as well.
+func DecodeObject(text string, schema *Schema, out any) error {
+	raw, err := ExtractJSON(text)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return fmt.Errorf("the answer is not valid JSON: %w", err)
+	}
+	if err := schema.Validate(value); err != nil {
+		return err
+	}
+	// Clear what an earlier, rejected answer left behind.
+	if v := reflect.ValueOf(out); v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+	if err := json.Unmarshal([]byte(raw), out); err != nil {
+		return fmt.Errorf("the answer does not match the expected structure: %w", err)
+	}
+	if v, ok := out.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// ExtractJSON returns the first complete JSON object in a model's answer,
+// which may wrap it in a Markdown code fence or surround it with prose.
+func ExtractJSON(text string) (string, error) {
+	start := strings.IndexByte(text, '{')
+	for start >= 0 {
+		if end := objectEnd(text[start:]); end > 0 {
+			candidate := text[start : start+end]
+			if json.Valid([]byte(candidate)) {
+				return candidate, nil
+			}
+		}
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += 1 + next
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("the answer is empty")
+	}
+	return "", errors.New("the answer does not contain a JSON object")
+}
+
+// objectEnd returns the length of the balanced {...} at the start of s, or 0
+// if it is not closed. Braces inside strings are ignored.
+func objectEnd(s string) int {
+	depth := 0
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && ch == '\\':
+			escaped = true
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '{':
+			depth++
+		case ch == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// schemaName turns the output's type name into a snake_case identifier, e.g.
+// QueryPlan into "query_plan".
+func schemaName(out any) string {
+	t := reflect.TypeOf(out)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" {
+		return "result"
+	}
+	var b strings.Builder
+	for i, r := range t.Name() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
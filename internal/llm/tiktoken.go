@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// tiktokenEncodings are the BPE vocabularies of OpenAI models, matched by
+// the longest prefix of the model name as with contextLimits.
+var tiktokenEncodings = map[string]string{
+	"gpt-3.5": tiktoken.MODEL_CL100K_BASE,
+	"gpt-4":   tiktoken.MODEL_CL100K_BASE,
+	"gpt-4o":  tiktoken.MODEL_O200K_BASE,
+	"gpt-4.1": tiktoken.MODEL_O200K_BASE,
+	"gpt-4.5": tiktoken.MODEL_O200K_BASE,
+	"gpt-5":   tiktoken.MODEL_O200K_BASE,
+	"o1":      tiktoken.MODEL_O200K_BASE,
+	"o3":      tiktoken.MODEL_O200K_BASE,
+	"o4":      tiktoken.MODEL_O200K_BASE,
+}
+
+var (
+	tiktokenMu    sync.Mutex
+	tiktokenCache = map[string]*tiktoken.Tiktoken{}
+)
+
+// TiktokenTokenizer counts tokens exactly with the vocabulary of an OpenAI
+// model.
+type TiktokenTokenizer struct {
+	enc *tiktoken.Tiktoken
+}
+
+// CountTokens counts the tokens of text. Special tokens such as
+// <|endoftext|> are counted as plain text, as the API does for user content.
+func (t TiktokenTokenizer) CountTokens(text string) int {
+	return len(t.enc.EncodeOrdinary(text))
+}
+
+// TokenizerFor returns the tokenizer of a model: its tiktoken vocabulary
+// for OpenAI models, and EstimateTokenizer for models whose vocabulary is
+// not published, such as Claude, or cannot be loaded.
+func TokenizerFor(model string) Tokenizer {
+	model = strings.TrimPrefix(model, "ft:")
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	best, encoding := -1, ""
+	for prefix, name := range tiktokenEncodings {
+		if strings.HasPrefix(model, prefix) && len(prefix) > best {
+			best, encoding = len(prefix), name
+		}
+	}
+	if encoding == "" {
+		return EstimateTokenizer{}
+	}
+
+	tiktokenMu.Lock()
+	defer tiktokenMu.Unlock()
+	enc, ok := tiktokenCache[encoding]
+	if !ok {
+		// The vocabularies are embedded, so that counting never downloads
+		// them at run time.
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+		var err error
+		if enc, err = tiktoken.GetEncoding(encoding); err != nil {
+			slog.Warn("Failed to load tokenizer, estimating tokens instead", "model", model, "encoding", encoding, "error", err)
+			return EstimateTokenizer{}
+		}
+		tiktokenCache[encoding] = enc
+	}
+	return TiktokenTokenizer{enc: enc}
+}
@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"article-chat-system/internal/metrics"
+)
+
+// toolCallsOutput labels tool calls in the structured output metrics.
+const toolCallsOutput = "tool_calls"
+
+// Tool is a function offered to the model. The model answers by calling it
+// with arguments matching Parameters instead of replying in text.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  *Schema // An object schema
+}
+
+// ToolCall is one call the model made.
+type ToolCall struct {
+	ID        string // Assigned by the provider, if it does
+	Name      string
+	Arguments json.RawMessage // A JSON object
+}
+
+// CallTools offers the request's tools to the model and returns its answer
+// with the calls it made. Each call must name one of the tools and pass
+// arguments matching its schema; invalid calls are sent back with the errors,
+// asking the model to correct them, up to repairs times. The model may also
+// call no tool at all, which is not an error. Clients that cannot call tools
+// natively are asked to describe their calls in JSON instead.
+func CallTools(ctx context.Context, c Client, req *Request, repairs int) (*Response, error) {
+	r := *req
+	r.Messages = append([]Message(nil), req.Messages...)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := Generate(ctx, c, &r)
+		if err != nil {
+			return nil, err
+		}
+		err = checkToolCalls(r.Tools, resp.ToolCalls)
+		if err == nil {
+			outcome := "valid"
+			if attempt > 1 {
+				outcome = "repaired"
+			}
+			metrics.StructuredOutputs.WithLabelValues(toolCallsOutput, outcome).Inc()
+			return resp, nil
+		}
+		if attempt > repairs {
+			metrics.StructuredOutputs.WithLabelValues(toolCallsOutput, "invalid").Inc()
+			return nil, &OutputError{Name: toolCallsOutput, Raw: describeToolCalls(resp), Attempts: attempt, Err: err}
+		}
+
+		slog.Warn("Asking the model to repair its tool calls", "attempt", attempt, "error", err)
+		r.Messages = append(r.Messages,
+			Message{Role: RoleAssistant, Content: describeToolCalls(resp)},
+			Message{Role: RoleUser, Content: "Your previous tool calls were not valid:\n" + err.Error() +
+				"\n\nCall the tools again with corrected arguments."},
+		)
+	}
+}
+
+// checkToolCalls validates every call against the tool it names.
+func checkToolCalls(tools []Tool, calls []ToolCall) error {
+	var errs []error
+	for i, call := range calls {
+		tool := findTool(tools, call.Name)
+		if tool == nil {
+			errs = append(errs, fmt.Errorf("call %d: there is no tool named %q", i+1, call.Name))
+			continue
+		}
+		var args any
+		if err := json.Unmarshal(call.Arguments, &args); err != nil {
+			errs = append(errs, fmt.Errorf("call %d (%s): the arguments are not valid JSON: %w", i+1, call.Name, err))
+			continue
+		}
+		if err := tool.Parameters.Validate(args); err != nil {
+			errs = append(errs, fmt.Errorf("call %d (%s): %w", i+1, call.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func findTool(tools []Tool, name string) *Tool {
+	for i := range tools {
+		if tools[i].Name == name {
+			return &tools[i]
+		}
+	}
+	return nil
+}
+
+// describeToolCalls renders an answer's calls as text, so they can be shown
+// back to the model or logged.
+func describeToolCalls(resp *Response) string {
+	var b strings.Builder
+	b.WriteString(resp.Text)
+	for _, call := range resp.ToolCalls {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "Called %s with %s", call.Name, call.Arguments)
+	}
+	return b.String()
+}
+
+// textToolCalls is the JSON answer asked of clients without native tool
+// calling.
+type textToolCalls struct {
+	ToolCalls []struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"tool_calls"`
+}
+
+// toolInstructions describes the tools in the prompt of a client that cannot
+// call them natively.
+func toolInstructions(tools []Tool) string {
+	var b strings.Builder
+	b.WriteString("## Tools\nYou can call these tools. Each takes a JSON object matching its parameter schema.\n")
+	for _, tool := range tools {
+		schema, _ := json.Marshal(tool.Parameters)
+		fmt.Fprintf(&b, "- %s: %s\n  Parameters: %s\n", tool.Name, tool.Description, schema)
+	}
+	b.WriteString(`To call tools, reply with only a JSON object of the form {"tool_calls": [{"name": "tool name", "arguments": {...}}]}.`)
+	return b.String()
+}
+
+// generateWithTextTools sends a request with tools to a client that only takes
+// a prompt, and reads the calls back from its JSON answer. An answer without
+// the JSON object calls no tool.
+func generateWithTextTools(ctx context.Context, c Client, req *Request) (*Response, error) {
+	resp, err := c.GenerateContent(ctx, req.Prompt()+"\n\n"+toolInstructions(req.Tools))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ExtractJSON(resp.Text)
+	if err != nil {
+		return resp, nil
+	}
+	var parsed textToolCalls
+	if json.Unmarshal([]byte(raw), &parsed) != nil || len(parsed.ToolCalls) == 0 {
+		return resp, nil
+	}
+	for _, call := range parsed.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{Name: call.Name, Arguments: call.Arguments})
+	}
+	resp.Text = ""
+	return resp, nil
+}
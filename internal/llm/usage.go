@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Usage counts the tokens consumed by one or more LLM calls.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	// Prompt tokens served from, and written to, the provider's prompt cache.
+	// Both are included in PromptTokens.
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+}
+
+// Add accumulates other into u.
+func (u *Usage) Add(other Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+}
+
+// setUsageAttributes adds the token counts to a span under the same keys for
+// every provider.
+func setUsageAttributes(span trace.Span, usage Usage) {
+	if usage.TotalTokens == 0 {
+		return
+	}
+	span.SetAttributes(
+		attribute.Int("llm.usage.prompt_tokens", usage.PromptTokens),
+		attribute.Int("llm.usage.completion_tokens", usage.CompletionTokens),
+		attribute.Int("llm.usage.total_tokens", usage.TotalTokens),
+		attribute.Int("llm.usage.cache_read_tokens", usage.CacheReadTokens),
+		attribute.Int("llm.usage.cache_write_tokens", usage.CacheWriteTokens),
+	)
+}
+
+// UsageMeter totals the usage of every LLM call made with a metered context.
+// It is safe for concurrent use.
+type UsageMeter struct {
+	mu       sync.Mutex
+	usage    Usage
+	byModel  map[string]Usage
+	model    string
+	answerBy string // Model of the latest synthesis call
+	calls    int
+	degraded bool
+}
+
+type (
+	usageMeterKey struct{}
+	synthesisKey  struct{}
+)
+
+// WithUsageMeter returns a context whose LLM calls are recorded in the returned meter.
+func WithUsageMeter(ctx context.Context) (context.Context, *UsageMeter) {
+	meter := &UsageMeter{byModel: make(map[string]Usage)}
+	return context.WithValue(ctx, usageMeterKey{}, meter), meter
+}
+
+// WithSynthesis marks the calls made with the context as writing answers, so
+// that the meter reports their model rather than that of the latest call,
+// which may have been a planner or analysis call.
+func WithSynthesis(ctx context.Context) context.Context {
+	return context.WithValue(ctx, synthesisKey{}, true)
+}
+
+// RecordUsage adds one call's usage to the meter attached to the context, if any.
+// Clients call it after every completed request.
+func RecordUsage(ctx context.Context, model string, usage Usage) {
+	meter, ok := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	if !ok {
+		return
+	}
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+	meter.usage.Add(usage)
+	perModel := meter.byModel[model]
+	perModel.Add(usage)
+	meter.byModel[model] = perModel
+	meter.model = model
+	if ctx.Value(synthesisKey{}) != nil {
+		meter.answerBy = model
+	}
+	meter.calls++
+}
+
+// MarkDegraded flags the metered request as answered by a degraded fallback.
+func MarkDegraded(ctx context.Context) {
+	meter, ok := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	if !ok {
+		return
+	}
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+	meter.degraded = true
+}
+
+// Usage returns the total usage recorded so far.
+func (m *UsageMeter) Usage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// ByModel returns the usage recorded so far for each model, for pricing
+// requests whose calls were served by different models.
+func (m *UsageMeter) ByModel() map[string]Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]Usage, len(m.byModel))
+	for model, usage := range m.byModel {
+		out[model] = usage
+	}
+	return out
+}
+
+// Model returns the model that wrote the answer: that of the latest call
+// marked with WithSynthesis or, when there was none, of the latest call.
+// ByModel lists every model that served the request.
+func (m *UsageMeter) Model() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.answerBy != "" {
+		return m.answerBy
+	}
+	return m.model
+}
+
+// Degraded reports whether any call was answered by a degraded fallback.
+func (m *UsageMeter) Degraded() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.degraded
+}
+
+// Calls returns the number of LLM calls recorded.
+func (m *UsageMeter) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
@@ -0,0 +1,254 @@
+// Package mcp serves the article corpus to agents over the Model Context
+// Protocol: stored articles are exposed as resources, and search, analysis
+// and ingestion as tools.
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
+)
+
+// ServerName and ServerVersion identify this server during initialization.
+const (
+	ServerName    = "article-chat-system"
+	ServerVersion = "1.0.0"
+)
+
+// resourceURIPrefix turns an article URL into a resource URI, e.g.
+// "article:https://example.com/story".
+const resourceURIPrefix = "article:"
+
+// toolHandler runs a tool with its raw JSON arguments.
+type toolHandler func(ctx context.Context, args json.RawMessage) (*CallToolResult, error)
+
+type registeredTool struct {
+	Tool
+	handler toolHandler
+}
+
+// UsageTracker enforces spending budgets and accounts for the LLM usage of
+// the tools, as it does for chat requests.
+type UsageTracker interface {
+	Check(ctx context.Context) error
+	Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error)
+}
+
+// Server answers MCP requests. It is transport-agnostic; see ServeStdio and
+// HTTPHandler.
+type Server struct {
+	logger           *slog.Logger
+	articleSvc       article.Service
+	processingFacade *processing.Facade
+	promptFactory    *prompts.Factory
+	usageTracker     UsageTracker
+	tools            []registeredTool
+}
+
+// NewServer is the constructor for the MCP server.
+func NewServer(logger *slog.Logger, articleSvc article.Service, processingFacade *processing.Facade, promptFactory *prompts.Factory) *Server {
+	s := &Server{
+		logger:           logger,
+		articleSvc:       articleSvc,
+		processingFacade: processingFacade,
+		promptFactory:    promptFactory,
+	}
+	s.registerTools()
+	return s
+}
+
+// SetUsageTracker registers the tracker that holds the tools' LLM calls to
+// the caller's budget and records their usage. The caller is the one the
+// context carries: the API key authenticated by the HTTP transport, or
+// anonymous on stdio.
+func (s *Server) SetUsageTracker(t UsageTracker) {
+	s.usageTracker = t
+}
+
+// Handle processes one JSON-RPC message and returns the encoded response, or
+// nil when the message was a notification.
+func (s *Server) Handle(ctx context.Context, payload []byte) []byte {
+	var req request
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return encodeResponse(response{JSONRPC: jsonRPCVersion, ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()}})
+	}
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		return encodeResponse(response{JSONRPC: jsonRPCVersion, ID: idOrNull(req.ID), Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}})
+	}
+
+	result, err := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		if err != nil {
+			s.logger.Warn("MCP notification failed", "method", req.Method, "error", err)
+		}
+		return nil
+	}
+
+	resp := response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			s.logger.Error("MCP request failed", "method", req.Method, "error", err)
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return encodeResponse(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	case "resources/list":
+		return s.listResources(ctx, req.Params)
+	case "resources/read":
+		return s.readResource(ctx, req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+func (s *Server) initialize(params json.RawMessage) (*initializeResult, error) {
+	var p initializeParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	// Agree on the client's version when we speak it, otherwise offer our latest.
+	version := supportedProtocolVersions[0]
+	for _, v := range supportedProtocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+	s.logger.Info("MCP client initialized", "client", p.ClientInfo.Name, "protocol_version", version)
+
+	return &initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		ServerInfo:   implementation{Name: ServerName, Version: ServerVersion},
+		Instructions: "Search and read a corpus of analyzed news articles. Use search_articles to find articles on a topic, then get_article or summarize_article for details.",
+	}, nil
+}
+
+func (s *Server) listTools() *listToolsResult {
+	result := &listToolsResult{Tools: make([]Tool, 0, len(s.tools))}
+	for _, t := range s.tools {
+		result.Tools = append(result.Tools, t.Tool)
+	}
+	return result
+}
+
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (*CallToolResult, error) {
+	var p callToolParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	for _, t := range s.tools {
+		if t.Name != p.Name {
+			continue
+		}
+		result, err := t.handler(ctx, p.Arguments)
+		if err != nil {
+			// Tool failures are reported to the model, which may retry differently.
+			s.logger.Warn("MCP tool call failed", "tool", p.Name, "error", err)
+			return &CallToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		return result, nil
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+}
+
+// listResources pages through the article catalog, reusing its cursor.
+func (s *Server) listResources(ctx context.Context, params json.RawMessage) (*listResourcesResult, error) {
+	var p listResourcesParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	page, err := s.articleSvc.ListArticles(ctx, repository.ArticleFilter{Cursor: p.Cursor, Limit: repository.MaxPageSize})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list articles: %w", err)
+	}
+
+	result := &listResourcesResult{Resources: []Resource{}, NextCursor: page.NextCursor}
+	for _, art := range page.Articles {
+		result.Resources = append(result.Resources, Resource{
+			URI:         resourceURIPrefix + art.URL,
+			Name:        art.Title,
+			Description: art.Excerpt,
+			MimeType:    "application/json",
+		})
+	}
+	return result, nil
+}
+
+func (s *Server) readResource(ctx context.Context, params json.RawMessage) (*readResourceResult, error) {
+	var p readResourceParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	articleURL, ok := strings.CutPrefix(p.URI, resourceURIPrefix)
+	if !ok || articleURL == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unsupported resource URI: " + p.URI}
+	}
+	art, found := s.articleSvc.GetArticle(ctx, articleURL)
+	if !found {
+		// MCP reserves -32002 for unknown resources.
+		return nil, &rpcError{Code: -32002, Message: "resource not found: " + p.URI}
+	}
+
+	text, err := json.MarshalIndent(art, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode article: %w", err)
+	}
+	return &readResourceResult{Contents: []resourceContents{{URI: p.URI, MimeType: "application/json", Text: string(text)}}}, nil
+}
+
+// decodeParams unmarshals request params, treating absent params as empty.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(bytes.TrimSpace(params)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func encodeResponse(resp response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: jsonRPCVersion, ID: resp.ID, Error: &rpcError{Code: codeInternalError, Message: "failed to encode response"}})
+	}
+	return data
+}
@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"article-chat-system/internal/llm"
+)
+
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+)
+
+// registerTools declares every tool, backed by the same services as the API.
+func (s *Server) registerTools() {
+	urlSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"url": map[string]interface{}{"type": "string", "description": "The article URL"},
+		},
+		"required": []string{"url"},
+	}
+
+	s.tools = []registeredTool{
+		{
+			Tool: Tool{
+				Name:        "search_articles",
+				Description: "Find the stored articles most semantically similar to a query.",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{"type": "string", "description": "What to search for"},
+						"limit": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": maxSearchLimit, "default": defaultSearchLimit},
+					},
+					"required": []string{"query"},
+				},
+			},
+			handler: s.searchArticles,
+		},
+		{
+			Tool:    Tool{Name: "get_article", Description: "Get a stored article with its summary, sentiment, topics and entities.", InputSchema: urlSchema},
+			handler: s.getArticle,
+		},
+		{
+			Tool:    Tool{Name: "summarize_article", Description: "Summarize a stored article.", InputSchema: urlSchema},
+			handler: s.summarizeArticle,
+		},
+		{
+			Tool: Tool{
+				Name:        "find_common_entities",
+				Description: "Count the most common named entities across the given articles, or across all articles when none are given.",
+				InputSchema: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"urls": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					},
+				},
+			},
+			handler: s.findCommonEntities,
+		},
+		{
+			Tool:    Tool{Name: "add_article", Description: "Fetch, analyze and store a new article.", InputSchema: urlSchema},
+			handler: s.addArticle,
+		},
+	}
+}
+
+type urlArgs struct {
+	URL string `json:"url"`
+}
+
+// decodeURLArgs reads the single "url" argument shared by several tools.
+func decodeURLArgs(raw json.RawMessage) (string, error) {
+	var args urlArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.URL) == "" {
+		return "", fmt.Errorf("'url' is required")
+	}
+	return args.URL, nil
+}
+
+// jsonResult renders a value as indented JSON text content.
+func jsonResult(v interface{}) (*CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return &CallToolResult{Content: []Content{{Type: "text", Text: string(data)}}}, nil
+}
+
+func (s *Server) searchArticles(ctx context.Context, raw json.RawMessage) (*CallToolResult, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, fmt.Errorf("'query' is required")
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultSearchLimit
+	}
+	if args.Limit > maxSearchLimit {
+		args.Limit = maxSearchLimit
+	}
+
+	articles, err := s.articleSvc.SearchSimilarArticles(ctx, args.Query, args.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	type hit struct {
+		URL       string  `json:"url"`
+		Title     string  `json:"title"`
+		Excerpt   string  `json:"excerpt"`
+		Sentiment string  `json:"sentiment"`
+		Relevance float64 `json:"relevance"`
+	}
+	hits := make([]hit, 0, len(articles))
+	for _, art := range articles {
+		hits = append(hits, hit{URL: art.URL, Title: art.Title, Excerpt: art.Excerpt, Sentiment: art.Sentiment, Relevance: art.Relevance})
+	}
+	return jsonResult(hits)
+}
+
+func (s *Server) getArticle(ctx context.Context, raw json.RawMessage) (*CallToolResult, error) {
+	articleURL, err := decodeURLArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	art, ok := s.articleSvc.GetArticle(ctx, articleURL)
+	if !ok {
+		return nil, fmt.Errorf("article not found: %s", articleURL)
+	}
+	return jsonResult(art)
+}
+
+// summarizeArticle returns the summary stored at ingestion, generating one
+// from the article text only when it is missing.
+func (s *Server) summarizeArticle(ctx context.Context, raw json.RawMessage) (*CallToolResult, error) {
+	articleURL, err := decodeURLArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	art, ok := s.articleSvc.GetArticle(ctx, articleURL)
+	if !ok {
+		return nil, fmt.Errorf("article not found: %s", articleURL)
+	}
+
+	summary := art.Summary
+	if summary == "" {
+		if art.TextContent == "" {
+			return nil, fmt.Errorf("no summary or text available for article: %s", articleURL)
+		}
+		prompt, err := s.promptFactory.CreateSummarizePrompt(art.TextContent)
+		if err != nil {
+			return nil, err
+		}
+		if s.usageTracker != nil {
+			if err := s.usageTracker.Check(ctx); err != nil {
+				return nil, err
+			}
+		}
+		ctx, meter := llm.WithUsageMeter(ctx)
+		summary, err = s.articleSvc.CallSynthesisLLM(ctx, prompt)
+		s.recordUsage(ctx, meter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize article: %w", err)
+		}
+	}
+	return &CallToolResult{Content: []Content{{Type: "text", Text: summary}}}, nil
+}
+
+// recordUsage accounts for the tokens a tool consumed, including those of a
+// call that failed.
+func (s *Server) recordUsage(ctx context.Context, meter *llm.UsageMeter) {
+	if s.usageTracker == nil {
+		return
+	}
+	if _, err := s.usageTracker.Record(context.WithoutCancel(ctx), "summary", meter.ByModel()); err != nil {
+		s.logger.Warn("Failed to record LLM usage", "error", err)
+	}
+}
+
+func (s *Server) findCommonEntities(ctx context.Context, raw json.RawMessage) (*CallToolResult, error) {
+	var args struct {
+		URLs []string `json:"urls"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	entities, err := s.articleSvc.FindCommonEntities(ctx, args.URLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find common entities: %w", err)
+	}
+	return jsonResult(entities)
+}
+
+func (s *Server) addArticle(ctx context.Context, raw json.RawMessage) (*CallToolResult, error) {
+	articleURL, err := decodeURLArgs(raw)
+	if err != nil {
+		return nil, err
+	}
+	art, err := s.processingFacade.AddNewArticle(ctx, articleURL)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(art)
+}
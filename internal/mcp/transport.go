@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxMessageSize bounds a single JSON-RPC message on either transport.
+const maxMessageSize = 4 << 20
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r is exhausted or the context is cancelled. Nothing
+// but protocol messages may be written to w, so logs must go elsewhere.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp := s.Handle(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// HTTPHandler serves the streamable HTTP transport in its simplest form: each
+// POST carries one message and is answered with a single JSON response, or
+// 202 Accepted for notifications. The server never initiates streams.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		resp := s.Handle(r.Context(), body)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp)
+	})
+}
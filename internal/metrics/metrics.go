@@ -0,0 +1,133 @@
+// Package metrics defines the Prometheus metrics the service exports on
+// /metrics. Collectors are registered with the default registry on import.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "article_chat"
+
+// Outcome label values.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+var (
+	// ChatDuration is the end-to-end latency of answering a chat query, by the
+	// intent the planner chose.
+	ChatDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "chat_duration_seconds",
+		Help:      "Time to answer a chat query, by intent and outcome.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 60},
+	}, []string{"intent", "outcome"})
+
+	// CacheLookups counts answer cache lookups; hits / (hits + misses) is the
+	// hit ratio.
+	CacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_lookups_total",
+		Help:      "Answer cache lookups, by result (hit or miss).",
+	}, []string{"result"})
+
+	// PlannerParseFailures counts planner responses that were not a valid plan.
+	PlannerParseFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "planner_parse_failures_total",
+		Help:      "Planner LLM responses that could not be parsed into a query plan.",
+	})
+
+	// StructuredOutputs counts structured LLM answers by schema and whether
+	// they were valid at once, valid after repair, or invalid.
+	StructuredOutputs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_structured_outputs_total",
+		Help:      "Structured LLM answers, by schema and outcome (valid, repaired or invalid).",
+	}, []string{"schema", "outcome"})
+
+	// LLMCacheLookups counts LLM response cache lookups by result (hit, miss
+	// or bypass).
+	LLMCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_cache_lookups_total",
+		Help:      "LLM response cache lookups, by result (hit, miss or bypass).",
+	}, []string{"result"})
+
+	// LLMCacheEvictions counts LLM response cache entries pruned for age or size.
+	LLMCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_cache_evictions_total",
+		Help:      "LLM response cache entries deleted because they expired or exceeded the size limit.",
+	})
+
+	// LLMDuration is the latency of calls to the LLM provider.
+	LLMDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "LLM provider call latency, by model and outcome.",
+		Buckets:   []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 60},
+	}, []string{"model", "outcome"})
+
+	// LLMTokens counts the tokens the provider reported as consumed.
+	LLMTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Tokens consumed by LLM calls, by model and type (prompt or completion).",
+	}, []string{"model", "type"})
+
+	// LLMCost is the spend on LLM calls in USD, priced with the usage price
+	// table.
+	LLMCost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_cost_usd_total",
+		Help:      "Cost of LLM calls in USD, by model.",
+	}, []string{"model"})
+
+	// TargetResolutions counts the plan targets the resolver mapped to an
+	// article, by how it matched them.
+	TargetResolutions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "target_resolutions_total",
+		Help:      "Plan targets resolved to articles, by method (exact, ordinal, url, title, vector, ambiguous or unresolved).",
+	}, []string{"method"})
+
+	// BudgetRejections counts requests rejected because a daily budget was spent.
+	BudgetRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "budget_rejections_total",
+		Help:      "Requests rejected by a spent daily LLM budget, by scope (caller or total).",
+	}, []string{"scope"})
+
+	// VectorSearchDuration is the latency of Weaviate searches.
+	VectorSearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "vector_search_duration_seconds",
+		Help:      "Weaviate search latency, by operation and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+
+	// IngestionStages counts article ingestion stages by result.
+	IngestionStages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingestion_stage_total",
+		Help:      "Article ingestion stages (fetch, analyze, store, index), by outcome.",
+	}, []string{"stage", "outcome"})
+)
+
+// Outcome maps an error to the outcome label value.
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+// ObserveSince records the time elapsed since start on the histogram.
+func ObserveSince(h prometheus.Observer, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
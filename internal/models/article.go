@@ -13,4 +13,5 @@ type Article struct {
 	Topics      []string  `json:"topics"`
 	Entities    []string  `json:"entities"`
 	ProcessedAt time.Time `json:"processed_at"`
+	Relevance   float64   `json:"relevance,omitempty"` // Set by vector search only
 }
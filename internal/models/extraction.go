@@ -1,6 +1,8 @@
 package models
 
-// EntityExtraction represents the structured response from LLM entity extraction
+// EntityExtraction represents the structured response from LLM entity extraction.
+// The jsonschema tags bound its scores and labels when it is requested as
+// structured output.
 type EntityExtraction struct {
 	Summary   string            `json:"summary"`
 	Entities  []Entity          `json:"entities"`
@@ -13,34 +15,34 @@ type EntityExtraction struct {
 // Entity represents a named entity with category and confidence
 type Entity struct {
 	Name       string  `json:"name"`
-	Category   string  `json:"category"`
-	Confidence float64 `json:"confidence"`
+	Category   string  `json:"category" jsonschema:"enum=person|organization|location|technology|other"`
+	Confidence float64 `json:"confidence" jsonschema:"min=0,max=1"`
 }
 
 // Keyword represents a keyword with relevance and context
 type Keyword struct {
 	Term      string  `json:"term"`
-	Relevance float64 `json:"relevance"`
+	Relevance float64 `json:"relevance" jsonschema:"min=0,max=1"`
 	Context   string  `json:"context"`
 }
 
 // Topic represents a topic with score and description
 type Topic struct {
 	Name        string  `json:"name"`
-	Score       float64 `json:"score"`
+	Score       float64 `json:"score" jsonschema:"min=0,max=1"`
 	Description string  `json:"description"`
 }
 
 // SentimentAnalysis represents sentiment analysis results
 type SentimentAnalysis struct {
-	Score      float64 `json:"score"`
-	Label      string  `json:"label"`
-	Confidence float64 `json:"confidence"`
+	Score      float64 `json:"score" jsonschema:"min=-1,max=1"`
+	Label      string  `json:"label" jsonschema:"enum=positive|negative|neutral"`
+	Confidence float64 `json:"confidence" jsonschema:"min=0,max=1"`
 }
 
 // ToneAnalysis represents tone analysis results
 type ToneAnalysis struct {
-	Style      string  `json:"style"`
-	Mood       string  `json:"mood"`
-	Confidence float64 `json:"confidence"`
+	Style      string  `json:"style" jsonschema:"enum=formal|casual|technical|conversational"`
+	Mood       string  `json:"mood" jsonschema:"enum=optimistic|pessimistic|neutral"`
+	Confidence float64 `json:"confidence" jsonschema:"min=0,max=1"`
 }
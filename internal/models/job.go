@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// JobStatus summarizes the progress of an ingestion job as a whole.
+type JobStatus string
+
+const (
+	JobQueued              JobStatus = "queued"
+	JobRunning             JobStatus = "running"
+	JobCompleted           JobStatus = "completed"
+	JobCompletedWithErrors JobStatus = "completed_with_errors"
+	JobFailed              JobStatus = "failed"
+)
+
+// JobItemStatus tracks a single URL within an ingestion job.
+type JobItemStatus string
+
+const (
+	JobItemPending    JobItemStatus = "pending"
+	JobItemProcessing JobItemStatus = "processing"
+	JobItemSucceeded  JobItemStatus = "succeeded"
+	JobItemSkipped    JobItemStatus = "skipped" // The article was already stored
+	JobItemFailed     JobItemStatus = "failed"
+)
+
+// Job is a batch of article URLs ingested asynchronously by the worker pool.
+type Job struct {
+	ID        string         `json:"id"`
+	Status    JobStatus      `json:"status"`
+	CreatedAt time.Time      `json:"created_at"`
+	Counts    map[string]int `json:"counts"`
+	Items     []JobItem      `json:"items,omitempty"`
+	User      string         `json:"-"` // Caller the job's LLM usage is charged to
+	APIKeyID  string         `json:"-"`
+}
+
+// JobItem is the per-URL state of an ingestion job.
+type JobItem struct {
+	ID            int64         `json:"-"`
+	JobID         string        `json:"-"`
+	URL           string        `json:"url"`
+	Status        JobItemStatus `json:"status"`
+	Attempts      int           `json:"attempts"`
+	LastError     string        `json:"last_error,omitempty"`
+	NextAttemptAt time.Time     `json:"next_attempt_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	User          string        `json:"-"` // Caller of the item's job, set by ClaimItem
+	APIKeyID      string        `json:"-"`
+}
+
+// Summarize derives the job status and per-status counts from its items.
+func (j *Job) Summarize() {
+	j.Counts = make(map[string]int)
+	for _, item := range j.Items {
+		j.Counts[string(item.Status)]++
+	}
+
+	pending := j.Counts[string(JobItemPending)]
+	processing := j.Counts[string(JobItemProcessing)]
+	failed := j.Counts[string(JobItemFailed)]
+	switch {
+	case pending == len(j.Items):
+		j.Status = JobQueued
+	case pending+processing > 0:
+		j.Status = JobRunning
+	case failed == len(j.Items):
+		j.Status = JobFailed
+	case failed > 0:
+		j.Status = JobCompletedWithErrors
+	default:
+		j.Status = JobCompleted
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Session is a multi-turn conversation between a user and the chat system.
+type Session struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	TurnCount int       `json:"turn_count"`
+	Turns     []Turn    `json:"turns,omitempty"`
+}
+
+// Turn is a single question/answer exchange within a session, including the
+// intent and article targets the planner resolved for it.
+type Turn struct {
+	Query     string    `json:"query"`
+	Answer    string    `json:"answer"`
+	Intent    string    `json:"intent"`
+	Targets   []string  `json:"targets"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// UsageRecord is the token usage and cost of one model within one request.
+type UsageRecord struct {
+	RequestID        string
+	CreatedAt        time.Time
+	User             string // Caller-supplied user ID, if any
+	APIKeyID         string // Fingerprint of the caller's API key, never the key itself
+	Operation        string // What the tokens were spent on, e.g. "chat"
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	CacheReadTokens  int
+	CacheWriteTokens int
+	CostUSD          float64
+}
+
+// UsageSummary totals the usage of one caller and model on one day. Requests
+// counts the distinct requests that used the model.
+type UsageSummary struct {
+	Day              string  `json:"day"` // UTC date, YYYY-MM-DD
+	User             string  `json:"user,omitempty"`
+	APIKeyID         string  `json:"api_key_id,omitempty"`
+	Model            string  `json:"model"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
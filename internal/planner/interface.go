@@ -2,9 +2,13 @@ package planner
 
 import (
 	"context"
+
+	"article-chat-system/internal/models"
 )
 
 // Service defines the contract for the planner.
+// History holds the prior turns of the conversation (oldest first) so that
+// follow-up questions can refer back to earlier answers; it may be empty.
 type Service interface {
-	CreatePlan(ctx context.Context, query string) (*QueryPlan, error)
+	CreatePlan(ctx context.Context, query string, history []models.Turn) (*QueryPlan, error)
 }
@@ -2,8 +2,10 @@ package planner
 
 import (
 	"context"
+	"fmt"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/vector"
 )
@@ -24,15 +26,123 @@ const (
 	IntentUnknown             QueryIntent = "UNKNOWN"
 )
 
+// SchemaEnum lists every intent, so plans naming any other are rejected.
+func (QueryIntent) SchemaEnum() []string {
+	return []string{
+		string(IntentSummarize), string(IntentKeywords), string(IntentSentiment),
+		string(IntentCompareTone), string(IntentFindTopic), string(IntentComparePositive),
+		string(IntentFindCommonEntities), string(IntentCompareAllSentiment),
+		string(IntentCompareMultiple), string(IntentUnknown),
+	}
+}
+
 // QueryPlan is the structured representation of a user's request.
 type QueryPlan struct {
+	Intent     QueryIntent `json:"intent" desc:"The single best intent for the query"`
+	Targets    []string    `json:"targets" desc:"URLs of the articles the query refers to"`
+	Parameters []string    `json:"parameters" desc:"Topics or other parameters named in the query"`
+	Question   string      `json:"question" desc:"The user's original question" jsonschema:"optional"`
+	Since      string      `json:"since,omitempty" desc:"Only consider articles added on or after this date" jsonschema:"format=date"`
+	Until      string      `json:"until,omitempty" desc:"Only consider articles added on or before this date" jsonschema:"format=date"`
+	// Steps lists every intent when the planner chose several. Intent and the
+	// date range are then the first step's, and Targets and Parameters
+	// combine those of all steps. Steps may take the articles found by
+	// earlier steps as input.
+	Steps []PlanStep `json:"steps,omitempty"`
+}
+
+// PlanStep is one intent of a plan with several.
+type PlanStep struct {
+	ID         string      `json:"id,omitempty"` // Referenced by the inputs of later steps
 	Intent     QueryIntent `json:"intent"`
 	Targets    []string    `json:"targets"`
 	Parameters []string    `json:"parameters"`
-	Question   string      `json:"question"`
+	Since      string      `json:"since,omitempty"`
+	Until      string      `json:"until,omitempty"`
+	// Inputs are the IDs of earlier steps whose sources are added to the
+	// step's targets when it runs.
+	Inputs []string `json:"inputs,omitempty"`
+}
+
+// Dependencies returns the indices of the steps each step takes input from.
+// Steps without an ID are named "step<n>", counting from 1. Inputs must name
+// earlier steps, which keeps the steps a graph without cycles.
+func Dependencies(steps []PlanStep) ([][]int, error) {
+	index := make(map[string]int, len(steps))
+	deps := make([][]int, len(steps))
+	for i, step := range steps {
+		for _, input := range step.Inputs {
+			j, ok := index[input]
+			if !ok {
+				return nil, fmt.Errorf("step %d (%s) takes input from %q, which is not an earlier step", i+1, step.Intent, input)
+			}
+			deps[i] = append(deps[i], j)
+		}
+		id := step.StepID(i)
+		if _, dup := index[id]; dup {
+			return nil, fmt.Errorf("step %d (%s) reuses the id %q", i+1, step.Intent, id)
+		}
+		index[id] = i
+	}
+	return deps, nil
+}
+
+// StepID returns the step's ID, or "step<n>" for the i-th step without one.
+func (s PlanStep) StepID(i int) string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return fmt.Sprintf("step%d", i+1)
+}
+
+// Plan returns the step as a plan of its own.
+func (s PlanStep) Plan(question string) *QueryPlan {
+	return &QueryPlan{
+		Intent:     s.Intent,
+		Targets:    s.Targets,
+		Parameters: s.Parameters,
+		Question:   question,
+		Since:      s.Since,
+		Until:      s.Until,
+	}
+}
+
+// Source is an article an answer was built from.
+type Source struct {
+	URL   string  `json:"url"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"` // Vector-search relevance; 1 for articles named in the query
+}
+
+// Result is the structured outcome of executing a plan.
+type Result struct {
+	Answer  string   `json:"answer"`
+	Sources []Source `json:"sources"`
+	// Steps holds the output of each step, in plan order, when the plan had
+	// several.
+	Steps []StepResult `json:"steps,omitempty"`
+}
+
+// StepResult is the output of one step of a plan.
+type StepResult struct {
+	ID      string      `json:"id"`
+	Intent  QueryIntent `json:"intent"`
+	Answer  string      `json:"answer,omitempty"`
+	Sources []Source    `json:"sources,omitempty"`
+	Error   string      `json:"error,omitempty"` // Why the step failed or was skipped
 }
 
 // IntentStrategy defines the interface for executing a query based on its intent.
+// Tool describes the strategy to the planner's model, which selects
+// strategies by calling their tools.
 type IntentStrategy interface {
-	Execute(ctx context.Context, plan *QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error)
+	Execute(ctx context.Context, plan *QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*Result, error)
+	Tool() llm.Tool
+}
+
+// Toolbox presents the registered strategies to the planner as tools and
+// turns the model's calls back into plan steps.
+type Toolbox interface {
+	Tools() []llm.Tool
+	Step(call llm.ToolCall) (PlanStep, error)
 }
@@ -2,12 +2,13 @@ package planner
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
 	"article-chat-system/internal/article"
 	"article-chat-system/internal/llm"
+	"article-chat-system/internal/metrics"
 	"article-chat-system/internal/models"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/repository"
@@ -20,21 +21,24 @@ type plannerService struct {
 	promptFactory *prompts.Factory
 	articleSvc    article.Service
 	vecRepo       *repository.VectorRepository
+	toolbox       Toolbox
 }
 
 // NewService is the constructor. It returns the public interface type.
-func NewService(llmClient llm.Client, promptFactory *prompts.Factory, articleSvc article.Service, vecRepo *repository.VectorRepository) Service {
+// The toolbox supplies the strategies the model may call.
+func NewService(llmClient llm.Client, promptFactory *prompts.Factory, articleSvc article.Service, vecRepo *repository.VectorRepository, toolbox Toolbox) Service {
 	// It returns a pointer to the unexported struct, which satisfies the interface.
 	return &plannerService{
 		llmClient:     llmClient,
 		promptFactory: promptFactory,
 		articleSvc:    articleSvc,
 		vecRepo:       vecRepo,
+		toolbox:       toolbox,
 	}
 }
 
 // CreatePlan's receiver is now the concrete struct pointer.
-func (s *plannerService) CreatePlan(ctx context.Context, query string) (*QueryPlan, error) {
+func (s *plannerService) CreatePlan(ctx context.Context, query string, history []models.Turn) (*QueryPlan, error) {
 	// 1. Find the top 5 most relevant articles using vector search.
 	relevantArticles, err := s.articleSvc.SearchSimilarArticles(ctx, query, 5)
 	if err != nil {
@@ -43,23 +47,123 @@ func (s *plannerService) CreatePlan(ctx context.Context, query string) (*QueryPl
 		relevantArticles = []*models.Article{}
 	}
 
-	// 2. Build the prompt using ONLY the relevant articles as context.
-	prompt, err := s.promptFactory.CreatePlannerPrompt(query, relevantArticles)
+	// 2. Articles resolved in earlier turns must stay in context so that
+	// "that article" or "it" can be mapped back to a URL.
+	relevantArticles = s.withPreviousTargets(ctx, relevantArticles, history)
+
+	// 3. Build the prompt using ONLY the relevant articles as context.
+	prompt, err := s.promptFactory.CreatePlannerPrompt(query, relevantArticles, history)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create planner prompt: %w", err)
 	}
 
-	resp, err := s.llmClient.GenerateContent(ctx, prompt)
+	// 4. Let the model pick strategies by calling their tools.
+	prompt.Tools = s.toolbox.Tools()
+	resp, err := llm.CallTools(ctx, s.llmClient, prompt, llm.DefaultRepairAttempts)
 	if err != nil {
+		var outErr *llm.OutputError
+		if errors.As(err, &outErr) {
+			log.Printf("Planner made invalid tool calls: %s", outErr.Raw)
+			metrics.PlannerParseFailures.Inc()
+			return nil, fmt.Errorf("failed to build plan from tool calls: %w", err)
+		}
 		return nil, fmt.Errorf("planner LLM call failed: %w", err)
 	}
 
-	var plan QueryPlan
-	if err := json.Unmarshal([]byte(resp.Text), &plan); err != nil {
-		log.Printf("Failed to parse JSON from planner, malformed text: %s", resp.Text)
-		return nil, fmt.Errorf("failed to unmarshal plan from LLM response: %w", err)
+	plan, err := s.planFromCalls(query, resp)
+	if err != nil {
+		metrics.PlannerParseFailures.Inc()
+		return nil, err
+	}
+	log.Printf("Successfully created plan. Intent: %s, Targets: %v, Steps: %d", plan.Intent, plan.Targets, len(plan.Steps))
+	return plan, nil
+}
+
+// planFromCalls turns the model's tool calls into a plan. A model that calls
+// no tool could not match the query to any strategy.
+func (s *plannerService) planFromCalls(query string, resp *llm.Response) (*QueryPlan, error) {
+	if len(resp.ToolCalls) == 0 {
+		log.Printf("Planner called no tool, answer: %s", resp.Text)
+		return &QueryPlan{Intent: IntentUnknown, Targets: []string{}, Parameters: []string{}, Question: query}, nil
 	}
 
-	log.Printf("Successfully created plan. Intent: %s, Targets: %v", plan.Intent, plan.Targets)
-	return &plan, nil
+	steps := make([]PlanStep, 0, len(resp.ToolCalls))
+	for _, call := range resp.ToolCalls {
+		step, err := s.toolbox.Step(call)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build plan from tool call %s: %w", call.Name, err)
+		}
+		steps = append(steps, step)
+	}
+
+	dropUnknownInputs(steps)
+	if _, err := Dependencies(steps); err != nil {
+		return nil, fmt.Errorf("failed to build plan from tool calls: %w", err)
+	}
+
+	plan := steps[0].Plan(query)
+	if len(steps) > 1 {
+		plan.Steps = steps
+		plan.Targets = combine(steps, func(step PlanStep) []string { return step.Targets })
+		plan.Parameters = combine(steps, func(step PlanStep) []string { return step.Parameters })
+	}
+	return plan, nil
+}
+
+// dropUnknownInputs removes the inputs that do not name an earlier step, so
+// that a step the model wired wrongly runs on its own targets rather than
+// failing the whole plan.
+func dropUnknownInputs(steps []PlanStep) {
+	known := make(map[string]bool, len(steps))
+	for i := range steps {
+		step := &steps[i]
+		var inputs []string
+		for _, input := range step.Inputs {
+			if !known[input] {
+				log.Printf("WARNING: Planner step %d (%s) takes input from %q, which is not an earlier step; ignoring it", i+1, step.Intent, input)
+				continue
+			}
+			inputs = append(inputs, input)
+		}
+		step.Inputs = inputs
+		known[step.StepID(i)] = true
+	}
+}
+
+// combine collects the distinct values of every step, in order.
+func combine(steps []PlanStep, values func(PlanStep) []string) []string {
+	seen := make(map[string]bool)
+	combined := []string{}
+	for _, step := range steps {
+		for _, value := range values(step) {
+			if !seen[value] {
+				seen[value] = true
+				combined = append(combined, value)
+			}
+		}
+	}
+	return combined
+}
+
+// withPreviousTargets prepends the articles targeted in earlier turns, most
+// recent first, skipping any already present in the search results.
+func (s *plannerService) withPreviousTargets(ctx context.Context, articles []*models.Article, history []models.Turn) []*models.Article {
+	seen := make(map[string]bool, len(articles))
+	for _, art := range articles {
+		seen[art.URL] = true
+	}
+
+	var previous []*models.Article
+	for i := len(history) - 1; i >= 0; i-- {
+		for _, url := range history[i].Targets {
+			if seen[url] {
+				continue
+			}
+			seen[url] = true
+			if art, ok := s.articleSvc.GetArticle(ctx, url); ok {
+				previous = append(previous, art)
+			}
+		}
+	}
+	return append(previous, articles...)
 }
@@ -2,14 +2,16 @@ package processing
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"article-chat-system/internal/article"
 	"article-chat-system/internal/llm"
+	"article-chat-system/internal/metrics"
 	"article-chat-system/internal/models"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/repository"
@@ -20,10 +22,18 @@ import (
 
 // Define a struct to match the JSON output from the LLM for initial analysis.
 type initialAnalysisResult struct {
-	Headline  string   `json:"headline"`
-	KeyPoints []string `json:"key_points"`
-	Sentiment string   `json:"sentiment"`
-	Entities  []string `json:"entities"`
+	Headline  string   `json:"headline" desc:"One sentence capturing the main point of the article"`
+	KeyPoints []string `json:"key_points" desc:"The 3 most important points"`
+	Sentiment string   `json:"sentiment" jsonschema:"enum=Positive|Negative|Neutral"`
+	Entities  []string `json:"entities" desc:"The top 5 named entities"`
+}
+
+// Validate rejects analyses that would leave the article without a summary.
+func (r *initialAnalysisResult) Validate() error {
+	if strings.TrimSpace(r.Headline) == "" {
+		return errors.New("headline: must not be empty")
+	}
+	return nil
 }
 
 type ParsedArticle struct {
@@ -52,27 +62,45 @@ func (f *Fetcher) FetchAndParse(ctx context.Context, url string) (*ParsedArticle
 	}, nil
 }
 
+// Long articles are analyzed in chunks of the context window that remains
+// after the prompt, each sharing chunkOverlapTokens with the one before.
+// At most maxParallelChunks chunks of one article are analyzed at a time.
+const (
+	chunkOverlapTokens = 150
+	maxParallelChunks  = 4
+)
+
 type Analyzer struct {
 	llmClient     llm.Client
 	promptFactory *prompts.Factory
+	budget        *llm.Budget
 }
 
+// NewAnalyzer creates an analyzer that assumes the default context window
+// until SetBudget gives it the model's.
 func NewAnalyzer(llmClient llm.Client, promptFactory *prompts.Factory) *Analyzer {
 	return &Analyzer{
 		llmClient:     llmClient,
 		promptFactory: promptFactory,
+		budget:        llm.NewBudget("", nil),
 	}
 }
 
+// SetBudget sets the token budget of the model that analyzes articles.
+func (a *Analyzer) SetBudget(b *llm.Budget) {
+	a.budget = b
+}
+
+// InitialAnalysis summarizes the article and extracts its sentiment and
+// entities. An article too long for the model's context window is split into
+// overlapping chunks by paragraph; the chunks are analyzed in parallel and
+// their analyses combined into one.
 func (a *Analyzer) InitialAnalysis(ctx context.Context, art *models.Article) error {
 	log.Printf("Performing comprehensive analysis for %s", art.Title)
 
 	// Log input content size before creating prompt
-	contentLength := len(art.TextContent)
-	contentWords := len(strings.Fields(art.TextContent))
-	estimatedTokens := contentLength / 4 // Rough estimate: 1 token ≈ 4 characters
 	log.Printf("Input content size - Characters: %d, Words: %d, Estimated tokens: %d",
-		contentLength, contentWords, estimatedTokens)
+		len(art.TextContent), len(strings.Fields(art.TextContent)), a.budget.CountTokens(art.TextContent))
 
 	// Use the new simpler initial analysis prompt for more reliable results
 	prompt, err := a.promptFactory.CreateInitialAnalysisPrompt(art.TextContent)
@@ -81,24 +109,16 @@ func (a *Analyzer) InitialAnalysis(ctx context.Context, art *models.Article) err
 		return fmt.Errorf("failed to create initial analysis prompt: %w", err)
 	}
 
-	// Log final prompt size
-	promptLength := len(prompt)
-	promptWords := len(strings.Fields(prompt))
-	promptTokens := promptLength / 4
-	log.Printf("Final prompt size - Characters: %d, Words: %d, Estimated tokens: %d",
-		promptLength, promptWords, promptTokens)
-
-	resp, err := a.llmClient.GenerateContent(ctx, prompt)
-	if err != nil {
-		log.Printf("Failed to generate initial analysis for %s: %v", art.Title, err)
-		return fmt.Errorf("failed to generate initial analysis: %w", err)
+	var analysis *initialAnalysisResult
+	if a.budget.Fits(prompt) {
+		log.Printf("Final prompt size - Estimated tokens: %d of %d", a.budget.PromptTokens(prompt), a.budget.ContextTokens)
+		analysis, err = a.analyze(ctx, prompt)
+	} else {
+		analysis, err = a.analyzeChunks(ctx, art)
 	}
-
-	// Parse the JSON response
-	var analysis initialAnalysisResult
-	if err := json.Unmarshal([]byte(resp.Text), &analysis); err != nil {
-		log.Printf("Failed to parse initial analysis JSON for %s: %v", art.Title, err)
-		return fmt.Errorf("failed to parse initial analysis JSON: %w", err)
+	if err != nil {
+		log.Printf("Initial analysis failed for %s: %v", art.Title, err)
+		return err
 	}
 
 	// Populate the main Article object with the richer data
@@ -111,13 +131,177 @@ func (a *Analyzer) InitialAnalysis(ctx context.Context, art *models.Article) err
 	return nil
 }
 
+// analyze asks the model for an initial analysis in the prompt's format.
+func (a *Analyzer) analyze(ctx context.Context, prompt *llm.Request) (*initialAnalysisResult, error) {
+	var analysis initialAnalysisResult
+	if _, err := llm.GenerateObject(ctx, a.llmClient, prompt, &analysis, llm.DefaultRepairAttempts); err != nil {
+		var outErr *llm.OutputError
+		if errors.As(err, &outErr) {
+			return nil, fmt.Errorf("failed to parse initial analysis JSON: %w", err)
+		}
+		return nil, fmt.Errorf("failed to generate initial analysis: %w", err)
+	}
+	return &analysis, nil
+}
+
+// analyzeChunks analyzes the article chunk by chunk and combines the results.
+// Chunks that fail are left out as long as one succeeds.
+func (a *Analyzer) analyzeChunks(ctx context.Context, art *models.Article) (*initialAnalysisResult, error) {
+	empty, err := a.promptFactory.CreateInitialAnalysisPrompt("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create initial analysis prompt: %w", err)
+	}
+	chunkTokens := a.budget.Remaining(empty)
+	if chunkTokens <= 2*chunkOverlapTokens {
+		return nil, fmt.Errorf("the context window of %d tokens leaves only %d for the article", a.budget.ContextTokens, chunkTokens)
+	}
+	chunks := a.budget.Chunk(art.TextContent, chunkTokens, chunkOverlapTokens)
+	log.Printf("Article %s exceeds the context window of %d tokens; analyzing it in %d chunks of up to %d tokens",
+		art.Title, a.budget.ContextTokens, len(chunks), chunkTokens)
+
+	// The prompts are rendered up front; the prompt loader is not safe for
+	// concurrent use.
+	chunkPrompts := make([]*llm.Request, len(chunks))
+	for i, chunk := range chunks {
+		if chunkPrompts[i], err = a.promptFactory.CreateInitialAnalysisPrompt(chunk); err != nil {
+			return nil, fmt.Errorf("failed to create initial analysis prompt: %w", err)
+		}
+	}
+
+	results := make([]*initialAnalysisResult, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, maxParallelChunks)
+	var wg sync.WaitGroup
+	for i, prompt := range chunkPrompts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = a.analyze(ctx, prompt)
+		}()
+	}
+	wg.Wait()
+
+	var parts []*initialAnalysisResult
+	for i, result := range results {
+		if errs[i] != nil {
+			log.Printf("WARNING: Analysis of chunk %d/%d of %s failed: %v", i+1, len(chunks), art.Title, errs[i])
+			continue
+		}
+		parts = append(parts, result)
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("every chunk failed: %w", errors.Join(errs...))
+	}
+	return a.reduce(ctx, art.Title, parts)
+}
+
+// reduce combines the analyses of consecutive chunks into one. When there
+// are too many to combine in a single prompt, neighbouring analyses are
+// combined in groups first.
+func (a *Analyzer) reduce(ctx context.Context, title string, parts []*initialAnalysisResult) (*initialAnalysisResult, error) {
+	for len(parts) > 1 {
+		groups, err := a.groupParts(title, parts)
+		if err != nil {
+			return nil, err
+		}
+		next := make([]*initialAnalysisResult, 0, len(groups))
+		for _, group := range groups {
+			if len(group) == 1 {
+				next = append(next, group[0])
+				continue
+			}
+			prompt, err := a.promptFactory.CreateReduceAnalysisPrompt(title, describeParts(group))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create reduce analysis prompt: %w", err)
+			}
+			combined, err := a.analyze(ctx, prompt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to combine chunk analyses: %w", err)
+			}
+			next = append(next, combined)
+		}
+		parts = next
+	}
+	return parts[0], nil
+}
+
+// groupParts packs consecutive analyses into groups whose reduce prompt fits
+// the context window. Every group but the last has at least two analyses, so
+// each round of reduce makes progress.
+func (a *Analyzer) groupParts(title string, parts []*initialAnalysisResult) ([][]*initialAnalysisResult, error) {
+	var groups [][]*initialAnalysisResult
+	start := 0
+	for start < len(parts) {
+		end := start + 1
+		for end < len(parts) {
+			prompt, err := a.promptFactory.CreateReduceAnalysisPrompt(title, describeParts(parts[start:end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create reduce analysis prompt: %w", err)
+			}
+			if !a.budget.Fits(prompt) {
+				break
+			}
+			end++
+		}
+		if end == start+1 && end < len(parts) {
+			return nil, fmt.Errorf("two chunk analyses do not fit the context window of %d tokens", a.budget.ContextTokens)
+		}
+		groups = append(groups, parts[start:end])
+		start = end
+	}
+	return groups, nil
+}
+
+// describeParts renders analyses for the reduce prompt.
+func describeParts(parts []*initialAnalysisResult) []string {
+	out := make([]string, len(parts))
+	for i, p := range parts {
+		out[i] = fmt.Sprintf("Headline: %s\nKey points:\n- %s\nSentiment: %s\nEntities: %s",
+			p.Headline, strings.Join(p.KeyPoints, "\n- "), p.Sentiment, strings.Join(p.Entities, ", "))
+	}
+	return out
+}
+
+var (
+	// ErrArticleNotFound is returned when an operation targets an article that is not stored.
+	ErrArticleNotFound = errors.New("article not found")
+	// ErrArticleExists is returned when adding an article that is already stored.
+	ErrArticleExists = errors.New("article already exists")
+)
+
+// AnswerCache is the part of the chat answer cache the facade needs to keep
+// cached answers consistent with the article stores.
+type AnswerCache interface {
+	InvalidateArticle(url string) int
+	InvalidateUntargeted() int
+}
+
+// TitleIndex is an index of the stored articles, such as the one the chat
+// target resolver matches titles against, that must be rebuilt whenever an
+// article is added, deleted or re-analyzed.
+type TitleIndex interface {
+	Invalidate()
+}
+
+// UsageRecorder holds ingestions to the daily budgets of the context's
+// caller and stores the LLM usage they cause.
+type UsageRecorder interface {
+	Check(ctx context.Context) error
+	Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error)
+}
+
 // Facade provides a simplified interface to the article processing subsystem.
 type Facade struct {
-	fetcher    *Fetcher
-	analyzer   *Analyzer
-	articleSvc article.Service
-	vectorSvc  vector.Service
-	vecRepo    *repository.VectorRepository
+	fetcher       *Fetcher
+	analyzer      *Analyzer
+	articleSvc    article.Service
+	vectorSvc     vector.Service
+	vecRepo       *repository.VectorRepository
+	answerCache   AnswerCache
+	titleIndex    TitleIndex
+	usageRecorder UsageRecorder
 }
 
 // NewFacade initializes the Facade with all its required subsystem components.
@@ -131,15 +315,47 @@ func NewFacade(llmClient llm.Client, articleSvc article.Service, promptFactory *
 	}
 }
 
+// SetTokenBudget sets the token budget of the model that analyzes articles,
+// which decides when an article is analyzed in chunks.
+func (f *Facade) SetTokenBudget(b *llm.Budget) {
+	f.analyzer.SetBudget(b)
+}
+
+// SetAnswerCache registers the chat answer cache to invalidate whenever an
+// article is added, deleted or re-analyzed.
+func (f *Facade) SetAnswerCache(c AnswerCache) {
+	f.answerCache = c
+}
+
+// SetTitleIndex registers the article index to invalidate whenever an
+// article is added, deleted or re-analyzed.
+func (f *Facade) SetTitleIndex(idx TitleIndex) {
+	f.titleIndex = idx
+}
+
+// SetUsageRecorder registers the recorder that accounts for the tokens spent
+// analyzing articles, under the "ingestion" operation. Once it is set, an
+// ingestion is refused with usage.ErrBudgetExceeded when the caller's daily
+// budget is spent.
+func (f *Facade) SetUsageRecorder(r UsageRecorder) {
+	f.usageRecorder = r
+}
+
 // AddNewArticle is the single method that hides the complex processing steps.
 func (f *Facade) AddNewArticle(ctx context.Context, url string) (*models.Article, error) {
 	log.Printf("FACADE: Starting to process new article from URL: %s", url)
 	if _, ok := f.articleSvc.GetArticle(ctx, url); ok {
-		return nil, fmt.Errorf("article from URL %s already exists", url)
+		return nil, fmt.Errorf("%w: %s", ErrArticleExists, url)
+	}
+	if err := f.checkBudget(ctx); err != nil {
+		return nil, err
 	}
+	ctx, meter := llm.WithUsageMeter(ctx)
+	defer f.recordUsage(ctx, meter)
 
 	// 1. Coordinate the Fetcher
 	parsedArticle, err := f.fetcher.FetchAndParse(ctx, url)
+	recordStage("fetch", err)
 	if err != nil {
 		return nil, fmt.Errorf("fetcher failed: %w", err)
 	}
@@ -153,31 +369,211 @@ func (f *Facade) AddNewArticle(ctx context.Context, url string) (*models.Article
 	}
 
 	// 2. Coordinate the Analyzer
-	if err := f.analyzer.InitialAnalysis(ctx, newArticle); err != nil {
+	err = f.analyzer.InitialAnalysis(ctx, newArticle)
+	recordStage("analyze", err)
+	if err != nil {
 		log.Printf("WARNING: Initial analysis failed for %s: %v", url, err)
 	}
 
 	// 3. Coordinate the Article Service to store the final result
-	if err := f.articleSvc.StoreArticle(ctx, newArticle); err != nil {
+	err = f.articleSvc.StoreArticle(ctx, newArticle)
+	recordStage("store", err)
+	if err != nil {
 		return nil, fmt.Errorf("failed to store article: %w", err)
 	}
 
+	f.invalidateTitles()
+	f.invalidateUntargetedAnswers()
+
 	// 4. Save content to Weaviate for vectorization and search (if available)
+	f.indexVectors(ctx, newArticle)
+
+	log.Printf("FACADE: Successfully processed and stored new article: %s", newArticle.Title)
+	return newArticle, nil
+}
+
+// DeleteArticle removes an article from the vector stores and PostgreSQL and
+// drops every cached answer that referenced it.
+func (f *Facade) DeleteArticle(ctx context.Context, url string) error {
+	log.Printf("FACADE: Deleting article: %s", url)
+	if _, ok := f.articleSvc.GetArticle(ctx, url); !ok {
+		return fmt.Errorf("%w: %s", ErrArticleNotFound, url)
+	}
+
+	// Remove the vectors first: if this fails the PostgreSQL row is kept, so
+	// the delete can simply be retried instead of leaving an orphaned object.
+	if err := f.removeVectors(ctx, url); err != nil {
+		return err
+	}
+
+	deleted, err := f.articleSvc.DeleteArticle(ctx, url)
+	if err != nil {
+		return fmt.Errorf("failed to delete article: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: %s", ErrArticleNotFound, url)
+	}
+
+	f.invalidateAnswers(url)
+	f.invalidateTitles()
+	log.Printf("FACADE: Successfully deleted article: %s", url)
+	return nil
+}
+
+// ReanalyzeArticle fetches a stored article again, re-runs the analysis and
+// rewrites it in the vector stores and PostgreSQL.
+func (f *Facade) ReanalyzeArticle(ctx context.Context, url string) (*models.Article, error) {
+	log.Printf("FACADE: Re-analyzing article: %s", url)
+	previous, ok := f.articleSvc.GetArticle(ctx, url)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrArticleNotFound, url)
+	}
+	if err := f.checkBudget(ctx); err != nil {
+		return nil, err
+	}
+	ctx, meter := llm.WithUsageMeter(ctx)
+	defer f.recordUsage(ctx, meter)
+
+	parsedArticle, err := f.fetcher.FetchAndParse(ctx, url)
+	recordStage("fetch", err)
+	if err != nil {
+		return nil, fmt.Errorf("fetcher failed: %w", err)
+	}
+
+	updated := &models.Article{
+		URL:         url,
+		Title:       parsedArticle.Title,
+		Excerpt:     parsedArticle.Excerpt,
+		TextContent: parsedArticle.TextContent,
+		ProcessedAt: time.Now(),
+	}
+
+	// Unlike a first ingestion, a failed analysis must not overwrite the
+	// existing, previously analyzed row with an empty one. Cached answers
+	// would only repeat the previous analysis.
+	err = f.analyzer.InitialAnalysis(llm.WithoutCache(ctx), updated)
+	recordStage("analyze", err)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+
+	// Write the vectors first: if a vector store fails, PostgreSQL still
+	// holds the previous analysis and the re-analysis can simply be retried.
+	if err := f.writeVectors(ctx, updated); err != nil {
+		return nil, fmt.Errorf("failed to update article vectors: %w", err)
+	}
+
+	err = f.articleSvc.StoreArticle(ctx, updated)
+	recordStage("store", err)
+	if err != nil {
+		// Put the previous analysis back so the vector stores match PostgreSQL.
+		if restoreErr := f.writeVectors(ctx, previous); restoreErr != nil {
+			log.Printf("WARNING: Failed to restore the previous vectors of %s: %v", url, restoreErr)
+		}
+		return nil, fmt.Errorf("failed to store article: %w", err)
+	}
+
+	f.invalidateAnswers(url)
+	f.invalidateTitles()
+	log.Printf("FACADE: Successfully re-analyzed article: %s", updated.Title)
+	return updated, nil
+}
+
+// invalidateTitles drops the article index, if one is registered.
+func (f *Facade) invalidateTitles() {
+	if f.titleIndex != nil {
+		f.titleIndex.Invalidate()
+	}
+}
+
+// checkBudget refuses an ingestion once the caller's daily budget is spent.
+func (f *Facade) checkBudget(ctx context.Context) error {
+	if f.usageRecorder == nil {
+		return nil
+	}
+	return f.usageRecorder.Check(ctx)
+}
+
+// recordUsage accounts for the tokens an ingestion consumed, including
+// those of one that failed or was cut short.
+func (f *Facade) recordUsage(ctx context.Context, meter *llm.UsageMeter) {
+	if f.usageRecorder == nil {
+		return
+	}
+	if _, err := f.usageRecorder.Record(context.WithoutCancel(ctx), "ingestion", meter.ByModel()); err != nil {
+		log.Printf("WARNING: Failed to record LLM usage: %v", err)
+	}
+}
+
+// recordStage counts the outcome of one ingestion stage.
+func recordStage(stage string, err error) {
+	metrics.IngestionStages.WithLabelValues(stage, metrics.Outcome(err)).Inc()
+}
+
+// indexVectors writes the article to the vector stores that are available.
+// Vectorization failures, including a store that has not connected yet, are
+// logged but do not fail the ingestion.
+func (f *Facade) indexVectors(ctx context.Context, art *models.Article) {
+	if err := f.writeVectors(ctx, art); err != nil {
+		log.Printf("WARNING: Failed to vectorize article %s: %v", art.URL, err)
+	}
+}
+
+// writeVectors creates or updates the article in every configured vector
+// store and reports the stores that failed, including those that have not
+// connected yet.
+func (f *Facade) writeVectors(ctx context.Context, art *models.Article) error {
+	var errs []error
 	if f.vecRepo != nil {
-		if err := f.vecRepo.SaveArticle(ctx, newArticle); err != nil {
-			log.Printf("WARNING: Failed to save article vector for %s: %v", url, err)
-			// We can choose to not fail the whole operation if vectorization fails.
+		err := f.vecRepo.SaveArticle(ctx, art)
+		recordStage("index", err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to save article vector: %w", err))
 		}
 	}
+	if f.vectorSvc != nil {
+		if err := f.vectorSvc.IndexArticle(ctx, art); err != nil {
+			errs = append(errs, fmt.Errorf("failed to index article in vector database: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	// 5. Index the article in the vector database (if available)
+// removeVectors deletes the article from the configured vector stores. Both
+// stores share the same object ID, so a missing object is expected. A store
+// that has not connected yet fails the removal with vector.ErrUnavailable:
+// the caller must keep the article until the object can be removed too.
+func (f *Facade) removeVectors(ctx context.Context, url string) error {
+	if f.vecRepo != nil {
+		if err := f.vecRepo.DeleteArticle(ctx, url); err != nil {
+			return fmt.Errorf("failed to remove article vector: %w", err)
+		}
+	}
 	if f.vectorSvc != nil {
-		if err := f.vectorSvc.IndexArticle(ctx, newArticle); err != nil {
-			log.Printf("WARNING: Failed to index article in vector database: %v", err)
-			// Don't fail the entire operation if vector indexing fails
+		if err := f.vectorSvc.RemoveArticle(ctx, url); err != nil && !vector.IsNotFound(err) {
+			return fmt.Errorf("failed to remove article from vector database: %w", err)
 		}
 	}
+	return nil
+}
 
-	log.Printf("FACADE: Successfully processed and stored new article: %s", newArticle.Title)
-	return newArticle, nil
+// invalidateAnswers drops cached chat answers that may have used the article.
+func (f *Facade) invalidateAnswers(url string) {
+	if f.answerCache == nil {
+		return
+	}
+	if n := f.answerCache.InvalidateArticle(url); n > 0 {
+		log.Printf("FACADE: Invalidated %d cached answers for %s", n, url)
+	}
+}
+
+// invalidateUntargetedAnswers drops cached chat answers that were not tied to
+// specific articles, since a new article may change them.
+func (f *Facade) invalidateUntargetedAnswers() {
+	if f.answerCache == nil {
+		return
+	}
+	if n := f.answerCache.InvalidateUntargeted(); n > 0 {
+		log.Printf("FACADE: Invalidated %d cached answers not tied to an article", n)
+	}
 }
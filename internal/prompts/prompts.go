@@ -1,6 +1,7 @@
 package prompts
 
 import (
+	"article-chat-system/internal/llm"
 	"article-chat-system/internal/models"
 	"bytes" // Use bytes.Buffer instead of strings.Builder for templates
 	"fmt"
@@ -8,29 +9,49 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"gopkg.in/yaml.v3"
 )
 
+// templateFuncs are available to every prompt template.
+var templateFuncs = template.FuncMap{
+	// cacheBoundary ends a static prefix that providers may cache.
+	"cacheBoundary": func() template.HTML { return template.HTML(llm.CacheBoundary) },
+}
+
+// PromptTemplate is a prompt file. System holds the instructions and Template
+// the user part, which carries the (untrusted) article text and query.
 type PromptTemplate struct {
+	System   string `yaml:"system"`
 	Template string `yaml:"template"`
 }
 
+// systemTemplate names the system part among a prompt's templates.
+const systemTemplate = "system"
+
 type Loader struct {
+	Version   string // Prompt set in use, e.g. "v1"
 	PromptDir string
 	Cache     map[string]*template.Template
+
+	mu sync.RWMutex // Guards Cache; the steps of a plan render prompts in parallel
 }
 
 func NewLoader(version string) (*Loader, error) {
 	promptDir := filepath.Join("configs", "prompts", version)
 	return &Loader{
+		Version:   version,
 		PromptDir: promptDir,
 		Cache:     make(map[string]*template.Template),
 	}, nil
 }
 
 func (l *Loader) LoadPrompt(name string) (*template.Template, error) {
-	if t, ok := l.Cache[name]; ok {
+	l.mu.RLock()
+	t, ok := l.Cache[name]
+	l.mu.RUnlock()
+	if ok {
 		return t, nil
 	}
 
@@ -45,11 +66,21 @@ func (l *Loader) LoadPrompt(name string) (*template.Template, error) {
 		return nil, fmt.Errorf("failed to unmarshal prompt YAML from %s: %w", filePath, err)
 	}
 
-	t, err := template.New(name).Parse(pt.Template)
+	t, err = template.New(name).Funcs(templateFuncs).Parse(pt.Template)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
 	}
+	if pt.System != "" {
+		if _, err := t.New(systemTemplate).Parse(pt.System); err != nil {
+			return nil, fmt.Errorf("failed to parse system template %s: %w", name, err)
+		}
+	}
 
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Cache == nil {
+		l.Cache = make(map[string]*template.Template)
+	}
 	l.Cache[name] = t
 	return t, nil
 }
@@ -63,21 +94,51 @@ func NewFactory(loader *Loader) (*Factory, error) { // Return error from constru
 	return &Factory{Loader: loader}, nil
 }
 
-func (f *Factory) executeTemplate(name string, data interface{}) (string, error) {
+// Version reports the prompt set the factory renders from.
+func (f *Factory) Version() string {
+	return f.Loader.Version
+}
+
+// executeTemplate renders a prompt's system and user parts into a request.
+func (f *Factory) executeTemplate(name string, data interface{}) (*llm.Request, error) {
 	tmpl, err := f.Loader.LoadPrompt(name)
 	if err != nil {
-		return "", fmt.Errorf("failed to load prompt template %s: %w", name, err)
+		return nil, fmt.Errorf("failed to load prompt template %s: %w", name, err)
+	}
+
+	var user bytes.Buffer
+	if err := tmpl.Execute(&user, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	var system bytes.Buffer
+	if sys := tmpl.Lookup(systemTemplate); sys != nil {
+		if err := sys.Execute(&system, data); err != nil {
+			return nil, fmt.Errorf("failed to execute system template %s: %w", name, err)
+		}
 	}
+	req := llm.NewRequest(system.String(), user.String())
+	req.Template = name
+	return req, nil
+}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+// jsonRequest renders a prompt whose answer must be a JSON object.
+func (f *Factory) jsonRequest(name string, data interface{}) (*llm.Request, error) {
+	req, err := f.executeTemplate(name, data)
+	if err != nil {
+		return nil, err
 	}
-	return buf.String(), nil
+	req.ResponseFormat = llm.FormatJSON
+	return req, nil
 }
 
+// maxHistoryAnswerChars truncates prior answers in the planner prompt; the
+// planner only needs enough of them to resolve references.
+const maxHistoryAnswerChars = 300
+
 // --- FIX: CreatePlannerPrompt now uses the external template ---
-func (f *Factory) CreatePlannerPrompt(query string, articles []*models.Article) (string, error) {
+// The planner answers by calling the strategies' tools, which the planner
+// service adds to the request.
+func (f *Factory) CreatePlannerPrompt(query string, articles []*models.Article, history []models.Turn) (*llm.Request, error) {
 	var articleInfo []string
 	for _, art := range articles {
 		articleInfo = append(articleInfo, fmt.Sprintf("- %s (%s)", art.Title, art.URL))
@@ -85,39 +146,77 @@ func (f *Factory) CreatePlannerPrompt(query string, articles []*models.Article)
 	data := struct {
 		Query    string
 		Articles string
+		History  string
 	}{
 		Query:    query,
 		Articles: strings.Join(articleInfo, "\n"),
+		History:  formatHistory(history),
 	}
 	return f.executeTemplate("planner", data)
 }
 
+// formatHistory renders prior turns, including the intent and targets the
+// planner resolved for each, as a plain-text transcript.
+func formatHistory(history []models.Turn) string {
+	var lines []string
+	for _, turn := range history {
+		answer := turn.Answer
+		if len(answer) > maxHistoryAnswerChars {
+			answer = answer[:maxHistoryAnswerChars] + "..."
+		}
+		lines = append(lines,
+			fmt.Sprintf("User: %s", turn.Query),
+			fmt.Sprintf("Resolved intent: %s; targets: [%s]", turn.Intent, strings.Join(turn.Targets, ", ")),
+			fmt.Sprintf("Assistant: %s", answer),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
+
 // CreateSummarizePrompt generates a prompt for summarizing an article.
-func (f *Factory) CreateSummarizePrompt(articleContent string) (string, error) {
+func (f *Factory) CreateSummarizePrompt(articleContent string) (*llm.Request, error) {
 	data := struct{ Content string }{Content: articleContent}
 	return f.executeTemplate("summarize", data)
 }
 
 // CreateKeywordsPrompt generates a prompt for extracting keywords.
-func (f *Factory) CreateKeywordsPrompt(articleTitle, articleContent string) (string, error) {
+func (f *Factory) CreateKeywordsPrompt(articleTitle, articleContent string) (*llm.Request, error) {
 	data := struct{ Title, Content string }{Title: articleTitle, Content: articleContent}
 	return f.executeTemplate("keywords", data)
 }
 
 // CreateInitialAnalysisPrompt generates a prompt for comprehensive initial analysis.
-func (f *Factory) CreateInitialAnalysisPrompt(content string) (string, error) {
+func (f *Factory) CreateInitialAnalysisPrompt(content string) (*llm.Request, error) {
 	data := struct{ Content string }{Content: content}
-	return f.executeTemplate("initial_analysis", data)
+	return f.jsonRequest("initial_analysis", data)
+}
+
+// CreateReduceAnalysisPrompt generates a prompt for combining the initial
+// analyses of an article's parts into one.
+func (f *Factory) CreateReduceAnalysisPrompt(title string, parts []string) (*llm.Request, error) {
+	numbered := make([]string, len(parts))
+	for i, part := range parts {
+		numbered[i] = fmt.Sprintf("Part %d:\n%s", i+1, part)
+	}
+	data := struct{ Title, Parts string }{Title: title, Parts: strings.Join(numbered, "\n\n")}
+	return f.jsonRequest("reduce_analysis", data)
+}
+
+// CreateSynthesizeStepsPrompt generates a prompt for answering a question from
+// the answers of the steps of a plan.
+func (f *Factory) CreateSynthesizeStepsPrompt(question string, parts []string) (*llm.Request, error) {
+	data := struct{ Question, Parts string }{Question: question, Parts: strings.Join(parts, "\n\n")}
+	return f.executeTemplate("synthesize_steps", data)
 }
 
 // CreateEntityExtractionPrompt generates a prompt for comprehensive entity extraction.
-func (f *Factory) CreateEntityExtractionPrompt(title, excerpt string) (string, error) {
+func (f *Factory) CreateEntityExtractionPrompt(title, excerpt string) (*llm.Request, error) {
 	data := struct{ Title, Excerpt string }{Title: title, Excerpt: excerpt}
-	return f.executeTemplate("entity_extraction", data)
+	return f.jsonRequest("entity_extraction", data)
 }
 
 // CreateFindTopicPrompt generates a prompt for finding and synthesizing articles about a topic
-func (f *Factory) CreateFindTopicPrompt(topic string, articles []*models.Article) (string, error) {
+func (f *Factory) CreateFindTopicPrompt(topic string, articles []*models.Article) (*llm.Request, error) {
 	data := map[string]interface{}{
 		"Topic":    topic,
 		"Articles": articles,
@@ -126,7 +225,7 @@ func (f *Factory) CreateFindTopicPrompt(topic string, articles []*models.Article
 }
 
 // CreateComparePositivityPrompt generates a prompt for comparing positivity across articles
-func (f *Factory) CreateComparePositivityPrompt(topic string, articles []*models.Article) (string, error) {
+func (f *Factory) CreateComparePositivityPrompt(topic string, articles []*models.Article) (*llm.Request, error) {
 	data := map[string]interface{}{
 		"Topic":    topic,
 		"Articles": articles,
@@ -135,7 +234,7 @@ func (f *Factory) CreateComparePositivityPrompt(topic string, articles []*models
 }
 
 // CreateCompareMultiplePrompt generates a prompt for comparing multiple articles
-func (f *Factory) CreateCompareMultiplePrompt(articles []*models.Article) (string, error) {
+func (f *Factory) CreateCompareMultiplePrompt(articles []*models.Article) (*llm.Request, error) {
 	data := map[string]interface{}{
 		"Articles": articles,
 	}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that EncodeCursor did not produce.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	// DefaultPageSize is used when a listing does not ask for a page size.
+	DefaultPageSize = 20
+	// MaxPageSize caps how many articles a single page may return.
+	MaxPageSize = 100
+)
+
+// pageCursor is the keyset position of the last article on a page. Listings
+// are ordered by (processed_at, url) descending, so the next page starts
+// strictly after this pair.
+type pageCursor struct {
+	ProcessedAt time.Time `json:"p"`
+	URL         string    `json:"u"`
+}
+
+// EncodeCursor builds the opaque cursor that resumes a listing after the given article.
+func EncodeCursor(processedAt time.Time, url string) string {
+	data, _ := json.Marshal(pageCursor{ProcessedAt: processedAt.UTC(), URL: url})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.URL == "" || c.ProcessedAt.IsZero() {
+		return time.Time{}, "", fmt.Errorf("%w: missing position", ErrInvalidCursor)
+	}
+	return c.ProcessedAt, c.URL, nil
+}
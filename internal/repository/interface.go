@@ -3,6 +3,7 @@ package repository
 import (
 	"article-chat-system/internal/models"
 	"context"
+	"time"
 )
 
 // EntityCount represents an entity with its frequency count
@@ -11,10 +12,74 @@ type EntityCount struct {
 	Count  int    `json:"count"`
 }
 
+// ArticleFilter narrows down an article listing. Zero-valued fields are ignored.
+type ArticleFilter struct {
+	Sentiment       string    // Case-insensitive match on the sentiment label
+	Topic           string    // Case-insensitive match on any topic
+	Entity          string    // Case-insensitive match on any entity
+	SourceDomain    string    // Host of the article URL, subdomains included
+	ProcessedAfter  time.Time // Inclusive lower bound on processed_at
+	ProcessedBefore time.Time // Exclusive upper bound on processed_at
+	Text            string    // Substring match on title, excerpt or summary
+	Cursor          string    // Opaque cursor returned by a previous page
+	Limit           int       // Page size; DefaultPageSize when zero
+}
+
+// ArticlePage is one page of an article listing, newest first.
+type ArticlePage struct {
+	Articles   []*models.Article `json:"articles"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
 // ArticleRepository defines the interface for article persistence.
 type ArticleRepository interface {
 	Save(ctx context.Context, art *models.Article) error
 	FindByURL(ctx context.Context, url string) (*models.Article, error)
-	FindAll(ctx context.Context) ([]*models.Article, error)
+	List(ctx context.Context, filter ArticleFilter) (*ArticlePage, error)
+	Delete(ctx context.Context, url string) (bool, error)
 	FindTopEntities(ctx context.Context, articleURLs []string, limit int) ([]EntityCount, error)
 }
+
+// SessionRepository defines the interface for conversation session persistence.
+type SessionRepository interface {
+	CreateSession(ctx context.Context) (*models.Session, error)
+	FindSession(ctx context.Context, id string) (*models.Session, error)
+	ListSessions(ctx context.Context, limit int) ([]*models.Session, error)
+	AppendTurn(ctx context.Context, sessionID string, turn *models.Turn) error
+	DeleteSession(ctx context.Context, id string) (bool, error)
+}
+
+// JobRepository defines the interface for the durable ingestion job queue.
+type JobRepository interface {
+	// CreateJob stores a job for the URLs, charged to the given user and API
+	// key, which may be empty.
+	CreateJob(ctx context.Context, urls []string, user, apiKeyID string) (*models.Job, error)
+	FindJob(ctx context.Context, id string) (*models.Job, error)
+	// ClaimItem locks the next runnable item for the lease duration and
+	// increments its attempt count. It returns nil when nothing is runnable.
+	ClaimItem(ctx context.Context, lease time.Duration) (*models.JobItem, error)
+	CompleteItem(ctx context.Context, id int64, status models.JobItemStatus, lastError string) error
+	RetryItem(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	// ReleaseItem puts a claimed item back in the queue, due at once, and
+	// takes back the attempt its claim counted.
+	ReleaseItem(ctx context.Context, id int64) error
+}
+
+// UsageFilter selects usage records. Zero-valued fields are ignored.
+type UsageFilter struct {
+	User      string
+	APIKeyID  string
+	Anonymous bool      // Only the records of callers without a user or API key
+	From      time.Time // Inclusive
+	To        time.Time // Exclusive
+}
+
+// UsageRepository defines the interface for LLM usage accounting.
+type UsageRepository interface {
+	SaveUsage(ctx context.Context, records []*models.UsageRecord) error
+	// SummarizeUsage totals the matching records per day, caller and model,
+	// newest day first.
+	SummarizeUsage(ctx context.Context, filter UsageFilter) ([]*models.UsageSummary, error)
+	// TotalCost sums the cost of the matching records.
+	TotalCost(ctx context.Context, filter UsageFilter) (float64, error)
+}
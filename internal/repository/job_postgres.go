@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"article-chat-system/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostgresJobRepository stores ingestion jobs in PostgreSQL. Workers claim
+// items with SELECT ... FOR UPDATE SKIP LOCKED, so any number of workers and
+// server instances can consume the same queue without double-processing.
+type PostgresJobRepository struct {
+	DB *sql.DB
+}
+
+// NewPostgresJobRepository creates a job repository on an existing database connection.
+func NewPostgresJobRepository(db *sql.DB) *PostgresJobRepository {
+	return &PostgresJobRepository{DB: db}
+}
+
+// CreateJob inserts a job and one pending item per URL in a single transaction.
+func (r *PostgresJobRepository) CreateJob(ctx context.Context, urls []string, user, apiKeyID string) (*models.Job, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	job := &models.Job{ID: uuid.NewString(), User: user, APIKeyID: apiKeyID}
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO ingestion_jobs (id, user_id, api_key_id) VALUES ($1, $2, $3) RETURNING created_at`, job.ID, user, apiKeyID,
+	).Scan(&job.CreatedAt); err != nil {
+		return nil, fmt.Errorf("error creating job: %w", err)
+	}
+
+	insert := `
+		INSERT INTO ingestion_job_items (job_id, url)
+		VALUES ($1, $2)
+		RETURNING id, status, attempts, next_attempt_at, updated_at
+	`
+	for _, url := range urls {
+		item := models.JobItem{JobID: job.ID, URL: url, User: user, APIKeyID: apiKeyID}
+		if err := tx.QueryRowContext(ctx, insert, job.ID, url).Scan(
+			&item.ID, &item.Status, &item.Attempts, &item.NextAttemptAt, &item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error creating job item: %w", err)
+		}
+		job.Items = append(job.Items, item)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing job: %w", err)
+	}
+	job.Summarize()
+	return job, nil
+}
+
+// FindJob retrieves a job with all of its items.
+func (r *PostgresJobRepository) FindJob(ctx context.Context, id string) (*models.Job, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil // A malformed ID cannot match any job
+	}
+
+	job := &models.Job{ID: id}
+	err := r.DB.QueryRowContext(ctx, `SELECT created_at FROM ingestion_jobs WHERE id = $1`, id).Scan(&job.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding job: %w", err)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, job_id, url, status, attempts, COALESCE(last_error, ''), next_attempt_at, updated_at
+		FROM ingestion_job_items WHERE job_id = $1 ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error finding job items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.JobItem
+		if err := rows.Scan(
+			&item.ID, &item.JobID, &item.URL, &item.Status, &item.Attempts,
+			&item.LastError, &item.NextAttemptAt, &item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning job item: %w", err)
+		}
+		job.Items = append(job.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error finding job items: %w", err)
+	}
+
+	job.Summarize()
+	return job, nil
+}
+
+// ClaimItem locks the next due item. Items left in "processing" by a worker
+// that died are reclaimed once their lease has expired.
+func (r *PostgresJobRepository) ClaimItem(ctx context.Context, lease time.Duration) (*models.JobItem, error) {
+	query := `
+		UPDATE ingestion_job_items AS i
+		SET status = 'processing',
+			attempts = i.attempts + 1,
+			locked_until = now() + make_interval(secs => $1),
+			updated_at = now()
+		FROM ingestion_jobs AS j
+		WHERE j.id = i.job_id AND i.id = (
+			SELECT id FROM ingestion_job_items
+			WHERE (status = 'pending' AND next_attempt_at <= now())
+				OR (status = 'processing' AND locked_until < now())
+			ORDER BY next_attempt_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING i.id, i.job_id, i.url, i.status, i.attempts, COALESCE(i.last_error, ''), i.next_attempt_at, i.updated_at,
+			j.user_id, j.api_key_id
+	`
+	var item models.JobItem
+	err := r.DB.QueryRowContext(ctx, query, lease.Seconds()).Scan(
+		&item.ID, &item.JobID, &item.URL, &item.Status, &item.Attempts,
+		&item.LastError, &item.NextAttemptAt, &item.UpdatedAt,
+		&item.User, &item.APIKeyID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil // Nothing to do
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error claiming job item: %w", err)
+	}
+	return &item, nil
+}
+
+// CompleteItem records the final outcome of an item and releases its lease.
+func (r *PostgresJobRepository) CompleteItem(ctx context.Context, id int64, status models.JobItemStatus, lastError string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE ingestion_job_items
+		SET status = $2, last_error = NULLIF($3, ''), locked_until = NULL, updated_at = now()
+		WHERE id = $1`, id, status, lastError)
+	if err != nil {
+		return fmt.Errorf("error completing job item: %w", err)
+	}
+	return nil
+}
+
+// RetryItem puts a failed item back in the queue, due at nextAttemptAt.
+func (r *PostgresJobRepository) RetryItem(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE ingestion_job_items
+		SET status = 'pending', last_error = $2, next_attempt_at = $3, locked_until = NULL, updated_at = now()
+		WHERE id = $1`, id, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("error scheduling job item retry: %w", err)
+	}
+	return nil
+}
+
+// ReleaseItem returns an interrupted item to the queue without counting the
+// attempt, e.g. when its worker shuts down mid-ingestion.
+func (r *PostgresJobRepository) ReleaseItem(ctx context.Context, id int64) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE ingestion_job_items
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), next_attempt_at = now(), locked_until = NULL, updated_at = now()
+		WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error releasing job item: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"article-chat-system/internal/llm"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiskLLMCacheRepository stores cached LLM answers as one JSON file per entry
+// under a directory, for deployments without PostgreSQL access or for local
+// development. A file's modification time records its last use.
+type DiskLLMCacheRepository struct {
+	Dir string
+}
+
+// NewDiskLLMCacheRepository creates an LLM cache in dir, creating the
+// directory if needed.
+func NewDiskLLMCacheRepository(dir string) (*DiskLLMCacheRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating LLM cache directory: %w", err)
+	}
+	return &DiskLLMCacheRepository{Dir: dir}, nil
+}
+
+// path spreads entries over subdirectories named after the key's first two
+// characters. Keys are hex digests.
+func (r *DiskLLMCacheRepository) path(key string) string {
+	return filepath.Join(r.Dir, key[:2], key+".json")
+}
+
+// GetResponse returns the entry stored under key and records the access.
+func (r *DiskLLMCacheRepository) GetResponse(ctx context.Context, key string) (*llm.CacheEntry, error) {
+	if len(key) < 2 {
+		return nil, nil
+	}
+	path := r.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil // A miss is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading LLM cache: %w", err)
+	}
+	var entry llm.CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("error parsing LLM cache entry %s: %w", path, err)
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return &entry, nil
+}
+
+// PutResponse adds or replaces an entry. The file is written under a
+// temporary name first, so readers never see a partial entry.
+func (r *DiskLLMCacheRepository) PutResponse(ctx context.Context, entry *llm.CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding LLM cache entry: %w", err)
+	}
+	path := r.path(entry.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error writing LLM cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error writing LLM cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing LLM cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing LLM cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing LLM cache: %w", err)
+	}
+	return nil
+}
+
+// PruneResponses deletes expired entries, then the least recently used ones
+// beyond maxEntries. An entry whose creation time cannot be read, say one
+// being replaced, is not taken for expired; it only counts towards
+// maxEntries.
+func (r *DiskLLMCacheRepository) PruneResponses(ctx context.Context, cutoff time.Time, maxEntries int) (int, error) {
+	type file struct {
+		path    string
+		usedAt  time.Time
+		created time.Time // Zero when unknown
+	}
+	var files []file
+	err := filepath.WalkDir(r.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Deleted concurrently
+		}
+		f := file{path: path, usedAt: info.ModTime()}
+		if !cutoff.IsZero() {
+			var entry llm.CacheEntry
+			if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &entry) == nil {
+				f.created = entry.CreatedAt
+			}
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error scanning LLM cache: %w", err)
+	}
+
+	deleted := 0
+	remove := func(path string) {
+		if os.Remove(path) == nil {
+			deleted++
+		}
+	}
+	kept := files[:0]
+	for _, f := range files {
+		if !cutoff.IsZero() && !f.created.IsZero() && f.created.Before(cutoff) {
+			remove(f.path)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if maxEntries > 0 && len(kept) > maxEntries {
+		sort.Slice(kept, func(i, j int) bool { return kept[i].usedAt.After(kept[j].usedAt) })
+		for _, f := range kept[maxEntries:] {
+			remove(f.path)
+		}
+	}
+	return deleted, nil
+}
+
+// OpenLLMCache opens the LLM response cache backend named by LLM_CACHE:
+// "postgres" uses the llm_cache table, "disk" the files under dir.
+func OpenLLMCache(backend, dir string, db *sql.DB) (llm.ResponseStore, error) {
+	switch backend {
+	case llm.ResponseCachePostgres:
+		return NewPostgresLLMCacheRepository(db), nil
+	case llm.ResponseCacheDisk:
+		return NewDiskLLMCacheRepository(dir)
+	}
+	return nil, fmt.Errorf("unknown LLM cache backend: %s. Supported backends: postgres, disk", backend)
+}
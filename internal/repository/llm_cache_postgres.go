@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"article-chat-system/internal/llm"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresLLMCacheRepository stores cached LLM answers in PostgreSQL.
+type PostgresLLMCacheRepository struct {
+	DB *sql.DB
+}
+
+// NewPostgresLLMCacheRepository creates an LLM cache on an existing database connection.
+func NewPostgresLLMCacheRepository(db *sql.DB) *PostgresLLMCacheRepository {
+	return &PostgresLLMCacheRepository{DB: db}
+}
+
+// GetResponse returns the entry stored under key and records the access.
+func (r *PostgresLLMCacheRepository) GetResponse(ctx context.Context, key string) (*llm.CacheEntry, error) {
+	entry := &llm.CacheEntry{Key: key}
+	query := `
+		UPDATE llm_cache SET accessed_at = now() WHERE key = $1
+		RETURNING model, prompt_version, response, created_at
+	`
+	err := r.DB.QueryRowContext(ctx, query, key).Scan(&entry.Model, &entry.PromptVersion, &entry.Response, &entry.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil // A miss is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading LLM cache: %w", err)
+	}
+	return entry, nil
+}
+
+// PutResponse adds or replaces an entry.
+func (r *PostgresLLMCacheRepository) PutResponse(ctx context.Context, entry *llm.CacheEntry) error {
+	query := `
+		INSERT INTO llm_cache (key, model, prompt_version, response, created_at, accessed_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (key) DO UPDATE SET
+			response = EXCLUDED.response,
+			created_at = EXCLUDED.created_at,
+			accessed_at = EXCLUDED.accessed_at
+	`
+	if _, err := r.DB.ExecContext(ctx, query, entry.Key, entry.Model, entry.PromptVersion, []byte(entry.Response), entry.CreatedAt); err != nil {
+		return fmt.Errorf("error writing LLM cache: %w", err)
+	}
+	return nil
+}
+
+// PruneResponses deletes expired entries, then the least recently used ones
+// beyond maxEntries.
+func (r *PostgresLLMCacheRepository) PruneResponses(ctx context.Context, cutoff time.Time, maxEntries int) (int, error) {
+	var deleted int64
+	if !cutoff.IsZero() {
+		res, err := r.DB.ExecContext(ctx, `DELETE FROM llm_cache WHERE created_at < $1`, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("error pruning expired LLM cache entries: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	if maxEntries > 0 {
+		query := `
+			DELETE FROM llm_cache WHERE key IN (
+				SELECT key FROM llm_cache ORDER BY accessed_at DESC OFFSET $1
+			)
+		`
+		res, err := r.DB.ExecContext(ctx, query, maxEntries)
+		if err != nil {
+			return int(deleted), fmt.Errorf("error pruning LLM cache to size: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	return int(deleted), nil
+}
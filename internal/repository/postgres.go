@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/lib/pq"
 )
@@ -35,18 +36,20 @@ func NewPostgresRepositoryWithDB(db *sql.DB) *PostgresRepository {
 // Save inserts or updates an article in the database.
 func (r *PostgresRepository) Save(ctx context.Context, art *models.Article) error {
 	query := `
-		INSERT INTO articles (url, title, excerpt, summary, sentiment, topics, entities, processed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO articles (url, title, excerpt, text_content, summary, sentiment, topics, entities, processed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (url) DO UPDATE SET
 			title = EXCLUDED.title,
 			excerpt = EXCLUDED.excerpt,
+			text_content = EXCLUDED.text_content,
 			summary = EXCLUDED.summary,
 			sentiment = EXCLUDED.sentiment,
 			topics = EXCLUDED.topics,
-			entities = EXCLUDED.entities;
+			entities = EXCLUDED.entities,
+			processed_at = EXCLUDED.processed_at;
 	`
 	_, err := r.DB.ExecContext(ctx, query,
-		art.URL, art.Title, art.Excerpt,
+		art.URL, art.Title, art.Excerpt, art.TextContent,
 		art.Summary, art.Sentiment, pq.Array(art.Topics), pq.Array(art.Entities), art.ProcessedAt,
 	)
 	return err
@@ -55,9 +58,9 @@ func (r *PostgresRepository) Save(ctx context.Context, art *models.Article) erro
 // FindByURL retrieves an article by its URL.
 func (r *PostgresRepository) FindByURL(ctx context.Context, url string) (*models.Article, error) {
 	var art models.Article
-	query := `SELECT url, title, excerpt, summary, sentiment, topics, entities, processed_at FROM articles WHERE url = $1`
+	query := `SELECT url, title, excerpt, COALESCE(text_content, ''), summary, sentiment, topics, entities, processed_at FROM articles WHERE url = $1`
 	err := r.DB.QueryRowContext(ctx, query, url).Scan(
-		&art.URL, &art.Title, &art.Excerpt,
+		&art.URL, &art.Title, &art.Excerpt, &art.TextContent,
 		&art.Summary, &art.Sentiment, pq.Array(&art.Topics), pq.Array(&art.Entities), &art.ProcessedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -69,16 +72,84 @@ func (r *PostgresRepository) FindByURL(ctx context.Context, url string) (*models
 	return &art, nil
 }
 
-// FindAll retrieves all articles.
-func (r *PostgresRepository) FindAll(ctx context.Context) ([]*models.Article, error) {
+// Delete removes an article by its URL and reports whether a row was deleted.
+func (r *PostgresRepository) Delete(ctx context.Context, url string) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM articles WHERE url = $1`, url)
+	if err != nil {
+		return false, fmt.Errorf("error deleting article: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error deleting article: %w", err)
+	}
+	return n > 0, nil
+}
+
+// List returns one page of articles matching the filter, newest first.
+// Listings omit the full text content; use FindByURL to load it.
+func (r *PostgresRepository) List(ctx context.Context, filter ArticleFilter) (*ArticlePage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	var conditions []string
+	var args []interface{}
+	// where appends a condition whose placeholders all refer to the same new argument.
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.Sentiment != "" {
+		where("sentiment ILIKE $?", escapeLike(filter.Sentiment))
+	}
+	if filter.Topic != "" {
+		where("EXISTS (SELECT 1 FROM unnest(topics) AS t WHERE t ILIKE $?)", escapeLike(filter.Topic))
+	}
+	if filter.Entity != "" {
+		where("EXISTS (SELECT 1 FROM unnest(entities) AS e WHERE e ILIKE $?)", escapeLike(filter.Entity))
+	}
+	if filter.SourceDomain != "" {
+		where(`(substring(url from '^[a-zA-Z]+://([^/:?#]+)') ILIKE $? OR substring(url from '^[a-zA-Z]+://([^/:?#]+)') ILIKE '%.' || $?)`,
+			escapeLike(strings.TrimPrefix(strings.ToLower(filter.SourceDomain), "www.")))
+	}
+	if !filter.ProcessedAfter.IsZero() {
+		where("processed_at >= $?", filter.ProcessedAfter)
+	}
+	if !filter.ProcessedBefore.IsZero() {
+		where("processed_at < $?", filter.ProcessedBefore)
+	}
+	if filter.Text != "" {
+		where("(title ILIKE $? OR excerpt ILIKE $? OR summary ILIKE $?)", "%"+escapeLike(filter.Text)+"%")
+	}
+	if filter.Cursor != "" {
+		processedAt, url, err := DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, processedAt, url)
+		conditions = append(conditions, fmt.Sprintf("(processed_at, url) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
 	query := `SELECT url, title, excerpt, summary, sentiment, topics, entities, processed_at FROM articles`
-	rows, err := r.DB.QueryContext(ctx, query)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// Fetch one extra row to learn whether another page exists.
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY processed_at DESC, url DESC LIMIT $%d", len(args))
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error finding all articles: %w", err)
+		return nil, fmt.Errorf("error listing articles: %w", err)
 	}
 	defer rows.Close()
 
-	var articles []*models.Article
+	page := &ArticlePage{Articles: []*models.Article{}}
 	for rows.Next() {
 		var art models.Article
 		if err := rows.Scan(
@@ -87,9 +158,23 @@ func (r *PostgresRepository) FindAll(ctx context.Context) ([]*models.Article, er
 		); err != nil {
 			return nil, fmt.Errorf("error scanning article: %w", err)
 		}
-		articles = append(articles, &art)
+		page.Articles = append(page.Articles, &art)
 	}
-	return articles, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing articles: %w", err)
+	}
+
+	if len(page.Articles) > limit {
+		page.Articles = page.Articles[:limit]
+		last := page.Articles[limit-1]
+		page.NextCursor = EncodeCursor(last.ProcessedAt, last.URL)
+	}
+	return page, nil
+}
+
+// escapeLike escapes the LIKE wildcards in user input so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
 
 // FindTopEntities finds the most common entities across articles using efficient PostgreSQL query
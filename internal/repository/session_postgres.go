@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"article-chat-system/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PostgresSessionRepository stores chat sessions and their turns in PostgreSQL.
+type PostgresSessionRepository struct {
+	DB *sql.DB
+}
+
+// NewPostgresSessionRepository creates a session repository on an existing database connection.
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{DB: db}
+}
+
+// CreateSession starts a new, empty session.
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context) (*models.Session, error) {
+	sess := &models.Session{ID: uuid.NewString()}
+	query := `INSERT INTO chat_sessions (id) VALUES ($1) RETURNING created_at, updated_at`
+	if err := r.DB.QueryRowContext(ctx, query, sess.ID).Scan(&sess.CreatedAt, &sess.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("error creating session: %w", err)
+	}
+	return sess, nil
+}
+
+// FindSession retrieves a session together with all of its turns in chronological order.
+func (r *PostgresSessionRepository) FindSession(ctx context.Context, id string) (*models.Session, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil // A malformed ID cannot match any session
+	}
+
+	var sess models.Session
+	query := `SELECT id, created_at, updated_at FROM chat_sessions WHERE id = $1`
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&sess.ID, &sess.CreatedAt, &sess.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding session: %w", err)
+	}
+
+	rows, err := r.DB.QueryContext(ctx,
+		`SELECT query, answer, COALESCE(intent, ''), targets, created_at FROM chat_turns WHERE session_id = $1 ORDER BY created_at, id`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error finding session turns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var turn models.Turn
+		if err := rows.Scan(&turn.Query, &turn.Answer, &turn.Intent, pq.Array(&turn.Targets), &turn.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning session turn: %w", err)
+		}
+		sess.Turns = append(sess.Turns, turn)
+	}
+	sess.TurnCount = len(sess.Turns)
+	return &sess, rows.Err()
+}
+
+// ListSessions returns the most recently active sessions without their turns.
+func (r *PostgresSessionRepository) ListSessions(ctx context.Context, limit int) ([]*models.Session, error) {
+	query := `
+		SELECT s.id, s.created_at, s.updated_at, COUNT(t.id)
+		FROM chat_sessions s
+		LEFT JOIN chat_turns t ON t.session_id = s.id
+		GROUP BY s.id
+		ORDER BY s.updated_at DESC
+		LIMIT $1
+	`
+	rows, err := r.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		var sess models.Session
+		if err := rows.Scan(&sess.ID, &sess.CreatedAt, &sess.UpdatedAt, &sess.TurnCount); err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		sessions = append(sessions, &sess)
+	}
+	return sessions, rows.Err()
+}
+
+// AppendTurn records a completed exchange and bumps the session's activity time.
+func (r *PostgresSessionRepository) AppendTurn(ctx context.Context, sessionID string, turn *models.Turn) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO chat_turns (session_id, query, answer, intent, targets)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	if err := tx.QueryRowContext(ctx, insert,
+		sessionID, turn.Query, turn.Answer, turn.Intent, pq.Array(turn.Targets),
+	).Scan(&turn.CreatedAt); err != nil {
+		return fmt.Errorf("error appending turn: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE chat_sessions SET updated_at = $2 WHERE id = $1`, sessionID, turn.CreatedAt); err != nil {
+		return fmt.Errorf("error updating session: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteSession removes a session and, through the foreign key cascade, all its turns.
+// It reports whether a session was actually deleted.
+func (r *PostgresSessionRepository) DeleteSession(ctx context.Context, id string) (bool, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, nil
+	}
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM chat_sessions WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("error deleting session: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error deleting session: %w", err)
+	}
+	return n > 0, nil
+}
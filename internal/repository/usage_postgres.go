@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"article-chat-system/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PostgresUsageRepository stores LLM usage records in PostgreSQL.
+type PostgresUsageRepository struct {
+	DB *sql.DB
+}
+
+// NewPostgresUsageRepository creates a usage repository on an existing database connection.
+func NewPostgresUsageRepository(db *sql.DB) *PostgresUsageRepository {
+	return &PostgresUsageRepository{DB: db}
+}
+
+// SaveUsage inserts the records of one request in a single transaction.
+func (r *PostgresUsageRepository) SaveUsage(ctx context.Context, records []*models.UsageRecord) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO llm_usage (request_id, created_at, user_id, api_key_id, operation, model,
+			prompt_tokens, completion_tokens, total_tokens, cache_read_tokens, cache_write_tokens, cost_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, rec := range records {
+		if _, err := tx.ExecContext(ctx, insert,
+			rec.RequestID, rec.CreatedAt, rec.User, rec.APIKeyID, rec.Operation, rec.Model,
+			rec.PromptTokens, rec.CompletionTokens, rec.TotalTokens, rec.CacheReadTokens, rec.CacheWriteTokens, rec.CostUSD,
+		); err != nil {
+			return fmt.Errorf("error saving usage: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing usage: %w", err)
+	}
+	return nil
+}
+
+// usageWhere builds the WHERE clause for a usage filter.
+func usageWhere(filter UsageFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.User != "" {
+		add("user_id = $%d", filter.User)
+	}
+	if filter.APIKeyID != "" {
+		add("api_key_id = $%d", filter.APIKeyID)
+	}
+	if filter.Anonymous {
+		conditions = append(conditions, "user_id = '' AND api_key_id = ''")
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// SummarizeUsage totals the matching records per UTC day, caller and model.
+func (r *PostgresUsageRepository) SummarizeUsage(ctx context.Context, filter UsageFilter) ([]*models.UsageSummary, error) {
+	where, args := usageWhere(filter)
+	query := `
+		SELECT to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, user_id, api_key_id, model,
+			COUNT(DISTINCT request_id), SUM(prompt_tokens), SUM(completion_tokens), SUM(total_tokens), SUM(cost_usd)
+		FROM llm_usage ` + where + `
+		GROUP BY day, user_id, api_key_id, model
+		ORDER BY day DESC, user_id, api_key_id, model
+	`
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error summarizing usage: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*models.UsageSummary
+	for rows.Next() {
+		var s models.UsageSummary
+		if err := rows.Scan(&s.Day, &s.User, &s.APIKeyID, &s.Model,
+			&s.Requests, &s.PromptTokens, &s.CompletionTokens, &s.TotalTokens, &s.CostUSD); err != nil {
+			return nil, fmt.Errorf("error scanning usage summary: %w", err)
+		}
+		summaries = append(summaries, &s)
+	}
+	return summaries, rows.Err()
+}
+
+// TotalCost sums the cost of the matching records.
+func (r *PostgresUsageRepository) TotalCost(ctx context.Context, filter UsageFilter) (float64, error) {
+	where, args := usageWhere(filter)
+	var total float64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COALESCE(SUM(cost_usd), 0) FROM llm_usage `+where, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error totaling usage cost: %w", err)
+	}
+	return total, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync/atomic"
+	"time"
 
+	"article-chat-system/internal/metrics"
 	"article-chat-system/internal/models"
+	"article-chat-system/internal/vector"
 
-	"github.com/google/uuid"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/graphql"
 	weaviate_models "github.com/weaviate/weaviate/entities/models"
@@ -17,9 +20,24 @@ const ArticleClassName = "Article"
 
 type VectorRepository struct {
 	client *weaviate.Client
+	ready  atomic.Bool
 }
 
+// NewVectorRepository creates a repository and connects it to Weaviate.
 func NewVectorRepository(host, scheme string) (*VectorRepository, error) {
+	repo, err := OpenVectorRepository(host, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Connect(context.Background()); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// OpenVectorRepository creates a repository without contacting Weaviate. Its
+// methods return vector.ErrUnavailable until Connect succeeds.
+func OpenVectorRepository(host, scheme string) (*VectorRepository, error) {
 	// The client config now correctly uses separate fields for Scheme and Host.
 	cfg := weaviate.Config{
 		Host:   host,
@@ -29,12 +47,33 @@ func NewVectorRepository(host, scheme string) (*VectorRepository, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not create weaviate client: %w", err)
 	}
+	return &VectorRepository{client: client}, nil
+}
 
-	repo := &VectorRepository{client: client}
-	if err := repo.ensureSchemaExists(context.Background()); err != nil {
-		return nil, fmt.Errorf("failed to ensure weaviate schema: %w", err)
+// Connect ensures the schema exists and marks the repository ready.
+func (r *VectorRepository) Connect(ctx context.Context) error {
+	if err := r.ensureSchemaExists(ctx); err != nil {
+		return fmt.Errorf("failed to ensure weaviate schema: %w", err)
 	}
-	return repo, nil
+	r.ready.Store(true)
+	return nil
+}
+
+// Ready reports whether the repository has connected to Weaviate.
+func (r *VectorRepository) Ready() bool {
+	return r.ready.Load()
+}
+
+// Ping checks that Weaviate is up and ready to serve requests.
+func (r *VectorRepository) Ping(ctx context.Context) error {
+	ready, err := r.client.Misc().ReadyChecker().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("weaviate readiness check failed: %w", err)
+	}
+	if !ready {
+		return fmt.Errorf("weaviate is not ready")
+	}
+	return nil
 }
 
 func (r *VectorRepository) ensureSchemaExists(ctx context.Context) error {
@@ -142,7 +181,11 @@ func (r *VectorRepository) ensureSchemaExists(ctx context.Context) error {
 }
 
 // SaveArticle lets Weaviate create the vector automatically from the content.
+// Saving an article again updates its object.
 func (r *VectorRepository) SaveArticle(ctx context.Context, art *models.Article) error {
+	if !r.Ready() {
+		return vector.ErrUnavailable
+	}
 	properties := map[string]interface{}{
 		"url":       art.URL,
 		"title":     art.Title,
@@ -153,16 +196,8 @@ func (r *VectorRepository) SaveArticle(ctx context.Context, art *models.Article)
 		"entities":  art.Entities,
 	}
 
-	// Generate a UUID v5 (SHA-1 hash) based on a namespace and the article's URL.
-	// This ensures the same URL always results in the same UUID.
-	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(art.URL))
-
-	_, err := r.client.Data().Creator().
-		WithClassName(ArticleClassName).
-		WithID(id.String()). // Explicitly set the generated UUID as the ID.
-		WithProperties(properties).
-		Do(ctx)
-
+	// The ID is derived from the URL so the same article always maps to the same object.
+	err := vector.UpsertObject(ctx, r.client, ArticleClassName, vector.ObjectID(art.URL), properties)
 	if err != nil {
 		return fmt.Errorf("failed to save article to Weaviate: %w", err)
 	}
@@ -171,8 +206,29 @@ func (r *VectorRepository) SaveArticle(ctx context.Context, art *models.Article)
 	return nil
 }
 
+// DeleteArticle removes an article's object. Deleting an article that was
+// never vectorized is not an error.
+func (r *VectorRepository) DeleteArticle(ctx context.Context, url string) error {
+	if !r.Ready() {
+		return vector.ErrUnavailable
+	}
+	err := r.client.Data().Deleter().
+		WithClassName(ArticleClassName).
+		WithID(vector.ObjectID(url)).
+		Do(ctx)
+	if err != nil && !vector.IsNotFound(err) {
+		return fmt.Errorf("failed to delete article from Weaviate: %w", err)
+	}
+
+	log.Printf("Deleted article from Weaviate: %s", url)
+	return nil
+}
+
 // SearchSimilarArticles finds relevant articles using a text query.
 func (r *VectorRepository) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	if !r.Ready() {
+		return nil, vector.ErrUnavailable
+	}
 	nearText := r.client.GraphQL().NearTextArgBuilder().WithConcepts([]string{queryText})
 	fields := []graphql.Field{
 		{Name: "url"},
@@ -182,14 +238,17 @@ func (r *VectorRepository) SearchSimilarArticles(ctx context.Context, queryText
 		{Name: "sentiment"},
 		{Name: "topics"},
 		{Name: "entities"},
+		{Name: "_additional", Fields: []graphql.Field{{Name: "certainty"}}},
 	}
 
+	start := time.Now()
 	result, err := r.client.GraphQL().Get().
 		WithClassName(ArticleClassName).
 		WithFields(fields...).
 		WithNearText(nearText).
 		WithLimit(limit).
 		Do(ctx)
+	metrics.ObserveSince(metrics.VectorSearchDuration.WithLabelValues("similar_articles", metrics.Outcome(err)), start)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to search articles in Weaviate: %w", err)
@@ -209,6 +268,11 @@ func (r *VectorRepository) SearchSimilarArticles(ctx context.Context, queryText
 			Excerpt:   getString(itemMap["excerpt"]),
 			Sentiment: getString(itemMap["sentiment"]),
 		}
+		if additional, ok := itemMap["_additional"].(map[string]interface{}); ok {
+			if certainty, ok := additional["certainty"].(float64); ok {
+				article.Relevance = certainty
+			}
+		}
 
 		// Parse topics array
 		if topics, ok := itemMap["topics"].([]interface{}); ok {
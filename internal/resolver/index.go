@@ -0,0 +1,72 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"article-chat-system/internal/repository"
+)
+
+// indexMaxAge bounds how long the index is trusted. Articles stored or
+// deleted by another process, which cannot invalidate it, are picked up
+// after at most this long.
+const indexMaxAge = time.Minute
+
+// indexedArticle is a stored article in the forms targets are matched
+// against.
+type indexedArticle struct {
+	URL     string
+	Title   string
+	normURL string   // The URL as normalizeURL leaves it
+	words   []string // The words of the title and of the URL slug
+}
+
+// titleIndex holds every stored article, so that resolving a target does not
+// list the catalog again.
+type titleIndex struct {
+	mu       sync.Mutex
+	articles []indexedArticle // Nil until loaded or after Invalidate
+	loadedAt time.Time
+}
+
+// get returns the indexed articles, listing them when the index is empty or
+// older than indexMaxAge.
+func (idx *titleIndex) get(ctx context.Context, list func(context.Context, repository.ArticleFilter) (*repository.ArticlePage, error)) ([]indexedArticle, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.articles != nil && time.Since(idx.loadedAt) < indexMaxAge {
+		return idx.articles, nil
+	}
+
+	articles := []indexedArticle{}
+	filter := repository.ArticleFilter{Limit: repository.MaxPageSize}
+	for {
+		page, err := list(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list articles: %w", err)
+		}
+		for _, art := range page.Articles {
+			articles = append(articles, indexedArticle{
+				URL:     art.URL,
+				Title:   art.Title,
+				normURL: normalizeURL(art.URL),
+				words:   append(words(art.Title), slugWords(art.URL)...),
+			})
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	idx.articles, idx.loadedAt = articles, time.Now()
+	return articles, nil
+}
+
+// invalidate empties the index, so that the next target lists the catalog.
+func (idx *titleIndex) invalidate() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.articles = nil
+}
@@ -0,0 +1,45 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resolution methods, reported as the method label of the
+// target_resolutions_total metric.
+const (
+	MethodExact      = "exact"      // The target is an article URL
+	MethodOrdinal    = "ordinal"    // "the second one", counted in the previous turn's targets
+	MethodURL        = "url"        // A URL differing in form, or a fragment of one
+	MethodTitle      = "title"      // Words of the title or URL slug
+	MethodVector     = "vector"     // A description, matched by vector search
+	MethodAmbiguous  = "ambiguous"  // Several articles match equally well
+	MethodUnresolved = "unresolved" // Nothing matches; the target is kept as is
+)
+
+// Candidate is an article a target may refer to.
+type Candidate struct {
+	URL   string  `json:"url"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"` // How well the article matches, from 0 to 1
+}
+
+// AmbiguousError reports a target that several articles match equally well.
+// The question cannot be answered until the user picks one.
+type AmbiguousError struct {
+	Target     string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("%q matches %d articles", e.Target, len(e.Candidates))
+}
+
+// Question asks the user which of the candidates they mean.
+func (e *AmbiguousError) Question() string {
+	lines := []string{fmt.Sprintf("Several articles match %q. Which one do you mean?", e.Target)}
+	for i, c := range e.Candidates {
+		lines = append(lines, fmt.Sprintf("%d. %s (%s)", i+1, c.Title, c.URL))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// normalizeURL reduces a URL, or the fragment of one, to the form articles
+// are compared in: no scheme, "www.", query, fragment or trailing slash, and
+// a lower-case host.
+func normalizeURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if u, err := url.Parse("//" + s); err == nil && u.Host != "" {
+		s = strings.ToLower(u.Host) + u.EscapedPath()
+	} else if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimPrefix(s, "www.")
+	return strings.TrimRight(s, "/")
+}
+
+// looksLikeURL reports whether a target is a URL or a fragment of one rather
+// than words, e.g. "techcrunch.com/2025/07/26/tesla-vet".
+func looksLikeURL(target string) bool {
+	if strings.ContainsAny(target, " \t") {
+		return false
+	}
+	return strings.Contains(target, "://") || strings.Contains(target, "/") || strings.HasPrefix(target, "www.")
+}
+
+// stopWords carry no hint of which article is meant.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "this": true, "that": true, "about": true,
+	"on": true, "of": true, "in": true, "and": true, "by": true, "from": true,
+	"to": true, "for": true, "with": true, "article": true, "articles": true,
+	"story": true, "piece": true, "post": true, "one": true, "https": true,
+	"http": true, "www": true, "com": true,
+}
+
+// words splits text into lower-case words, dropping stop words.
+func words(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopWords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// slugWords are the words of the last segment of an article's URL.
+func slugWords(rawURL string) []string {
+	return words(path.Base(normalizeURL(rawURL)))
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5,
+	"sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10,
+	"1st": 1, "2nd": 2, "3rd": 3, "4th": 4, "5th": 5,
+	"6th": 6, "7th": 7, "8th": 8, "9th": 9, "10th": 10,
+	"last": -1, "latter": -1, "former": 1,
+}
+
+// ordinal returns the position a target such as "the second one", "#2" or
+// "the last article" names, counting from 1, or -1 for the last. It reports
+// false for targets with other words.
+func ordinal(target string) (int, bool) {
+	ws := words(target)
+	if len(ws) == 1 {
+		if n, ok := ordinalWords[ws[0]]; ok {
+			return n, true
+		}
+		if n, err := strconv.Atoi(ws[0]); err == nil && n > 0 && strings.Contains(target, "#") {
+			return n, true
+		}
+	}
+	if len(ws) == 2 && ws[0] == "number" {
+		if n, err := strconv.Atoi(ws[1]); err == nil && n > 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// titleScore is the share of the target's words found among an article's
+// words, allowing for plurals and typos.
+func titleScore(target, article []string) float64 {
+	if len(target) == 0 {
+		return 0
+	}
+	found := 0
+	for _, t := range target {
+		for _, a := range article {
+			if similarWords(t, a) {
+				found++
+				break
+			}
+		}
+	}
+	return float64(found) / float64(len(target))
+}
+
+// similarWords matches equal words, a word and its longer form such as
+// "layoff" and "layoffs", and words of five letters or more one edit apart.
+func similarWords(a, b string) bool {
+	if a == b {
+		return true
+	}
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	if len(short) >= 4 && strings.HasPrefix(long, short) && len(long)-len(short) <= 3 {
+		return true
+	}
+	return len(short) >= 5 && editDistance(a, b) <= 1
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent letters that turn a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,265 @@
+package resolver
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/metrics"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+)
+
+// Defaults for the thresholds of a Resolver.
+const (
+	DefaultMinTitleScore = 0.6
+	DefaultMinRelevance  = 0.75
+	DefaultTieMargin     = 0.02
+)
+
+// vectorCandidates is how many vector-search hits are weighed for a target.
+const vectorCandidates = 3
+
+// maxCandidates caps the articles a clarifying question offers.
+const maxCandidates = 5
+
+// Resolver maps the targets the planner wrote, which may be titles, URL
+// fragments, ordinals or descriptions, to the URLs of stored articles.
+type Resolver struct {
+	articleSvc article.Service
+	// MinTitleScore is the share of a target's words an article's title or
+	// URL must contain to match.
+	MinTitleScore float64
+	// MinRelevance is the vector-search relevance a description must reach.
+	MinRelevance float64
+	// TieMargin is how close the scores of two URL matches, or the
+	// relevance of two hits, must be for them to tie.
+	TieMargin float64
+
+	index titleIndex
+}
+
+// NewResolver is the constructor for the target resolver.
+func NewResolver(articleSvc article.Service) *Resolver {
+	return &Resolver{
+		articleSvc:    articleSvc,
+		MinTitleScore: DefaultMinTitleScore,
+		MinRelevance:  DefaultMinRelevance,
+		TieMargin:     DefaultTieMargin,
+	}
+}
+
+// Invalidate drops the index of stored articles, so that the next target
+// is matched against the catalog as it is now. It is called whenever an
+// article is added, deleted or re-analyzed.
+func (r *Resolver) Invalidate() {
+	r.index.invalidate()
+}
+
+// resolution is the state of resolving the targets of one plan.
+type resolution struct {
+	history  []models.Turn
+	resolved map[string]string
+}
+
+// Resolve replaces the targets of the plan and of its steps with article
+// URLs. Targets nothing matches are kept, so that the strategy reports the
+// article missing. It returns an *AmbiguousError when several articles match
+// a target equally well.
+func (r *Resolver) Resolve(ctx context.Context, plan *planner.QueryPlan, history []models.Turn) error {
+	res := &resolution{history: history, resolved: make(map[string]string)}
+	targets, err := r.resolveAll(ctx, res, plan.Targets)
+	if err != nil {
+		return err
+	}
+	plan.Targets = targets
+	for i := range plan.Steps {
+		if plan.Steps[i].Targets, err = r.resolveAll(ctx, res, plan.Steps[i].Targets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAll resolves a list of targets, dropping blanks and duplicates.
+func (r *Resolver) resolveAll(ctx context.Context, res *resolution, targets []string) ([]string, error) {
+	out := make([]string, 0, len(targets))
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		url, ok := res.resolved[target]
+		if !ok {
+			var err error
+			if url, err = r.resolve(ctx, res, target); err != nil {
+				return nil, err
+			}
+			res.resolved[target] = url
+		}
+		if !seen[url] {
+			seen[url] = true
+			out = append(out, url)
+		}
+	}
+	return out, nil
+}
+
+// resolve tries each way of matching a target in turn, from the most to the
+// least precise.
+func (r *Resolver) resolve(ctx context.Context, res *resolution, target string) (string, error) {
+	if _, ok := r.articleSvc.GetArticle(ctx, target); ok {
+		return r.found(target, MethodExact, target), nil
+	}
+	if n, ok := ordinal(target); ok {
+		if url, ok := previousTarget(res.history, n); ok {
+			return r.found(target, MethodOrdinal, url), nil
+		}
+		return r.unresolved(target), nil
+	}
+
+	catalog, err := r.index.get(ctx, r.articleSvc.ListArticles)
+	if err != nil {
+		return "", err
+	}
+	if looksLikeURL(target) {
+		candidates := r.matchURL(target, catalog)
+		if len(candidates) == 1 {
+			return r.found(target, MethodURL, candidates[0].URL), nil
+		}
+		if len(candidates) > 1 {
+			return "", r.ambiguous(target, candidates)
+		}
+	}
+
+	candidates := r.matchTitle(target, catalog)
+	if len(candidates) == 1 {
+		return r.found(target, MethodTitle, candidates[0].URL), nil
+	}
+	if len(candidates) > 1 {
+		return "", r.ambiguous(target, candidates)
+	}
+
+	if !looksLikeURL(target) && len(words(target)) > 0 {
+		candidates := r.matchDescription(ctx, target)
+		if len(candidates) == 1 {
+			return r.found(target, MethodVector, candidates[0].URL), nil
+		}
+		if len(candidates) > 1 {
+			return "", r.ambiguous(target, candidates)
+		}
+	}
+	return r.unresolved(target), nil
+}
+
+func (r *Resolver) found(target, method, url string) string {
+	metrics.TargetResolutions.WithLabelValues(method).Inc()
+	if method != MethodExact {
+		log.Printf("Resolved target %q to %s by %s", target, url, method)
+	}
+	return url
+}
+
+func (r *Resolver) unresolved(target string) string {
+	metrics.TargetResolutions.WithLabelValues(MethodUnresolved).Inc()
+	log.Printf("WARNING: No article matches the target %q", target)
+	return target
+}
+
+// ambiguous offers the best matching candidates, best first.
+func (r *Resolver) ambiguous(target string, candidates []Candidate) error {
+	metrics.TargetResolutions.WithLabelValues(MethodAmbiguous).Inc()
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+	return &AmbiguousError{Target: target, Candidates: candidates}
+}
+
+// previousTarget returns the n-th target of the latest turn that had any,
+// counting from 1, or the last one for a negative n.
+func previousTarget(history []models.Turn, n int) (string, bool) {
+	for i := len(history) - 1; i >= 0; i-- {
+		targets := history[i].Targets
+		if len(targets) == 0 {
+			continue
+		}
+		if n < 0 {
+			return targets[len(targets)-1], true
+		}
+		if n <= len(targets) {
+			return targets[n-1], true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// matchURL returns the article whose URL equals the target once both are
+// normalized or, failing that, the ones containing it whose URL it covers
+// best, within TieMargin.
+func (r *Resolver) matchURL(target string, catalog []indexedArticle) []Candidate {
+	norm := normalizeURL(target)
+	if norm == "" {
+		return nil
+	}
+	var partial []Candidate
+	for _, art := range catalog {
+		if art.normURL == norm {
+			return []Candidate{{URL: art.URL, Title: art.Title, Score: 1}}
+		}
+		if strings.Contains(art.normURL, norm) {
+			partial = append(partial, Candidate{URL: art.URL, Title: art.Title, Score: float64(len(norm)) / float64(len(art.normURL))})
+		}
+	}
+	sort.SliceStable(partial, func(i, j int) bool { return partial[i].Score > partial[j].Score })
+	for i, c := range partial {
+		if partial[0].Score-c.Score > r.TieMargin {
+			return partial[:i]
+		}
+	}
+	return partial
+}
+
+// matchTitle returns the articles whose title and URL slug best cover the
+// target's words, if they reach MinTitleScore. Several are returned when
+// they tie.
+func (r *Resolver) matchTitle(target string, catalog []indexedArticle) []Candidate {
+	targetWords := words(target)
+	var best []Candidate
+	for _, art := range catalog {
+		score := titleScore(targetWords, art.words)
+		if score < r.MinTitleScore {
+			continue
+		}
+		switch {
+		case len(best) == 0 || score > best[0].Score:
+			best = []Candidate{{URL: art.URL, Title: art.Title, Score: score}}
+		case score == best[0].Score:
+			best = append(best, Candidate{URL: art.URL, Title: art.Title, Score: score})
+		}
+	}
+	return best
+}
+
+// matchDescription returns the vector-search hit for the target if it is
+// relevant enough, or the hits that tie with it.
+func (r *Resolver) matchDescription(ctx context.Context, target string) []Candidate {
+	hits, err := r.articleSvc.SearchSimilarArticles(ctx, target, vectorCandidates)
+	if err != nil {
+		log.Printf("WARNING: Vector search for target %q failed: %v", target, err)
+		return nil
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Relevance > hits[j].Relevance })
+	var candidates []Candidate
+	for _, hit := range hits {
+		if hit.Relevance < r.MinRelevance || (len(candidates) > 0 && candidates[0].Score-hit.Relevance > r.TieMargin) {
+			break
+		}
+		candidates = append(candidates, Candidate{URL: hit.URL, Title: hit.Title, Score: hit.Relevance})
+	}
+	return candidates
+}
@@ -0,0 +1,21 @@
+package session
+
+import (
+	"article-chat-system/internal/models"
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a session ID does not match any stored session.
+var ErrNotFound = errors.New("session not found")
+
+// Service defines the contract for conversation session operations.
+type Service interface {
+	Start(ctx context.Context) (*models.Session, error)
+	Get(ctx context.Context, id string) (*models.Session, error)
+	List(ctx context.Context, limit int) ([]*models.Session, error)
+	Delete(ctx context.Context, id string) error
+	History(ctx context.Context, id string) ([]models.Turn, error)
+	Resume(ctx context.Context, id string) (string, []models.Turn, error)
+	RecordTurn(ctx context.Context, id string, turn *models.Turn) error
+}
@@ -0,0 +1,91 @@
+package session
+
+import (
+	"context"
+
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/repository"
+)
+
+// maxHistoryTurns bounds how many prior turns are handed to the planner so the
+// prompt does not grow without limit in long conversations.
+const maxHistoryTurns = 5
+
+// SessionService persists conversations through the session repository.
+type SessionService struct {
+	repo repository.SessionRepository
+}
+
+// NewService is the constructor for the session service.
+func NewService(repo repository.SessionRepository) *SessionService {
+	return &SessionService{repo: repo}
+}
+
+// Start creates a new, empty session.
+func (s *SessionService) Start(ctx context.Context) (*models.Session, error) {
+	return s.repo.CreateSession(ctx)
+}
+
+// Get returns a session with all of its turns.
+func (s *SessionService) Get(ctx context.Context, id string) (*models.Session, error) {
+	sess, err := s.repo.FindSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sess == nil {
+		return nil, ErrNotFound
+	}
+	return sess, nil
+}
+
+// List returns the most recently active sessions.
+func (s *SessionService) List(ctx context.Context, limit int) ([]*models.Session, error) {
+	return s.repo.ListSessions(ctx, limit)
+}
+
+// Delete removes a session and its turns.
+func (s *SessionService) Delete(ctx context.Context, id string) error {
+	deleted, err := s.repo.DeleteSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// History returns the most recent turns of a session, oldest first.
+func (s *SessionService) History(ctx context.Context, id string) ([]models.Turn, error) {
+	sess, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	turns := sess.Turns
+	if len(turns) > maxHistoryTurns {
+		turns = turns[len(turns)-maxHistoryTurns:]
+	}
+	return turns, nil
+}
+
+// Resume returns the session a chat request belongs to and its prior turns,
+// starting a new session when id is empty.
+func (s *SessionService) Resume(ctx context.Context, id string) (string, []models.Turn, error) {
+	if id == "" {
+		sess, err := s.Start(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		return sess.ID, nil, nil
+	}
+	history, err := s.History(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	return id, history, nil
+}
+
+// RecordTurn appends a completed exchange to the session.
+func (s *SessionService) RecordTurn(ctx context.Context, id string, turn *models.Turn) error {
+	return s.repo.AppendTurn(ctx, id, turn)
+}
@@ -5,26 +5,52 @@ import (
 	"fmt"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/vector"
 )
 
-type strategyStep func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error)
+type strategyStep func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error)
 
 type BaseStrategy struct {
 	doExecute strategyStep
+	tool      llm.Tool
 }
 
-func (s *BaseStrategy) Execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+// Tool describes the strategy to the planner.
+func (s *BaseStrategy) Tool() llm.Tool {
+	return s.tool
+}
+
+func (s *BaseStrategy) Execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	if err := s.validateInput(plan); err != nil {
-		return err.Error(), nil
+		return newResult(err.Error()), nil
 	}
-	result, err := s.doExecute(ctx, plan, articleSvc, promptFactory, vectorSvc)
+	result, err := s.doExecute(streamFormatted(ctx), plan, articleSvc, promptFactory, vectorSvc)
 	if err != nil {
-		return "", fmt.Errorf("error during specific execution: %w", err)
+		return nil, fmt.Errorf("error during specific execution: %w", err)
 	}
-	return s.formatResponse(result), nil
+	result.Answer = s.formatResponse(result.Answer)
+	return result, nil
+}
+
+// streamFormatted prefixes the first streamed chunk the way formatResponse
+// prefixes the answer, so that the streamed tokens add up to the answer.
+func streamFormatted(ctx context.Context) context.Context {
+	onChunk, ok := llm.StreamHandlerFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	started := false
+	return llm.WithStreamHandler(ctx, func(chunk string) error {
+		if !started {
+			started = true
+			chunk = answerPrefix + chunk
+		}
+		return onChunk(chunk)
+	})
 }
 
 func (s *BaseStrategy) validateInput(plan *planner.QueryPlan) error {
@@ -34,6 +60,33 @@ func (s *BaseStrategy) validateInput(plan *planner.QueryPlan) error {
 	return nil
 }
 
+// answerPrefix introduces every strategy's answer.
+const answerPrefix = "🤖 Here is your answer:\n\n"
+
 func (s *BaseStrategy) formatResponse(response string) string {
-	return fmt.Sprintf("🤖 Here is your answer:\n\n%s", response)
+	return answerPrefix + response
+}
+
+// newResult builds a Result citing the given articles as its sources.
+// Articles without a vector-search relevance were named in the query itself
+// and are reported with full confidence.
+func newResult(answer string, sources ...*models.Article) *planner.Result {
+	result := &planner.Result{Answer: answer, Sources: []planner.Source{}}
+	for _, art := range sources {
+		score := art.Relevance
+		if score == 0 {
+			score = 1
+		}
+		result.Sources = append(result.Sources, planner.Source{URL: art.URL, Title: art.Title, Score: score})
+	}
+	return result
+}
+
+// synthesize calls the LLM with the prompt and cites the given articles.
+func synthesize(ctx context.Context, articleSvc article.Service, prompt *llm.Request, sources ...*models.Article) (*planner.Result, error) {
+	answer, err := articleSvc.CallSynthesisLLM(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(answer, sources...), nil
 }
@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/vector"
@@ -18,25 +19,26 @@ type CompareAllSentimentStrategy struct {
 func NewCompareAllSentimentStrategy() *CompareAllSentimentStrategy {
 	s := &CompareAllSentimentStrategy{}
 	s.doExecute = s.compareAllSentiment
+	s.tool = newTool(planner.IntentCompareAllSentiment, "Compare the sentiment of all articles about a topic.", topicArgs{})
 	return s
 }
 
-func (s *CompareAllSentimentStrategy) compareAllSentiment(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *CompareAllSentimentStrategy) compareAllSentiment(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("COMPARE ALL SENTIMENT STRATEGY: Executing...")
 
 	if len(plan.Parameters) == 0 {
-		return "Please specify the topic for sentiment comparison.", nil
+		return newResult("Please specify the topic for sentiment comparison."), nil
 	}
 	topic := plan.Parameters[0]
 
 	// 1. Find relevant articles using vector search
-	relevantArticles, err := articleSvc.SearchSimilarArticles(ctx, topic, 5)
+	relevantArticles, err := searchTopic(ctx, articleSvc, topic, 5, plan)
 	if err != nil {
-		return "", fmt.Errorf("failed to find articles for sentiment comparison: %w", err)
+		return nil, fmt.Errorf("failed to find articles for sentiment comparison: %w", err)
 	}
 
 	if len(relevantArticles) == 0 {
-		return fmt.Sprintf("I could not find any articles discussing '%s' to compare sentiment.", topic), nil
+		return newResult(fmt.Sprintf("I could not find any articles discussing '%s' to compare sentiment.", topic)), nil
 	}
 
 	// 2. Analyze sentiment for each article
@@ -47,7 +49,10 @@ func (s *CompareAllSentimentStrategy) compareAllSentiment(ctx context.Context, p
 			sentimentResults = append(sentimentResults, fmt.Sprintf("Article %d: %s - Sentiment: %s", i+1, article.Title, article.Sentiment))
 		} else {
 			// Fallback: analyze sentiment using LLM
-			prompt := fmt.Sprintf("Analyze the sentiment of this article about %s. Return only the sentiment (positive/negative/neutral) and a brief explanation:\n\nTitle: %s\nSummary: %s", topic, article.Title, article.Summary)
+			prompt := llm.NewRequest(
+				fmt.Sprintf("Analyze the sentiment of the article the user provides about %s. Return only the sentiment (positive/negative/neutral) and a brief explanation.", topic),
+				fmt.Sprintf("Title: %s\nSummary: %s", article.Title, article.Summary),
+			)
 			sentiment, err := articleSvc.CallSynthesisLLM(ctx, prompt)
 			if err != nil {
 				sentimentResults = append(sentimentResults, fmt.Sprintf("Article %d: %s - Sentiment: Unable to analyze", i+1, article.Title))
@@ -68,5 +73,5 @@ func (s *CompareAllSentimentStrategy) compareAllSentiment(ctx context.Context, p
 		result += "Compare the sentiment patterns above to identify trends and differences."
 	}
 
-	return result, nil
+	return newResult(result, relevantArticles...), nil
 }
@@ -19,13 +19,14 @@ type CompareMultipleStrategy struct {
 func NewCompareMultipleStrategy() *CompareMultipleStrategy {
 	s := &CompareMultipleStrategy{}
 	s.doExecute = s.compareMultipleArticles
+	s.tool = newTool(planner.IntentCompareMultiple, "Analyze and compare several articles, e.g. \"compare articles A, B and C\".", comparisonArgs{})
 	return s
 }
 
-func (s *CompareMultipleStrategy) compareMultipleArticles(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *CompareMultipleStrategy) compareMultipleArticles(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("COMPARE MULTIPLE STRATEGY: Executing...")
 	if len(plan.Targets) < 2 {
-		return "Please specify at least two articles to compare.", nil
+		return newResult("Please specify at least two articles to compare."), nil
 	}
 
 	// 1. Fetch all target articles from the database.
@@ -33,7 +34,7 @@ func (s *CompareMultipleStrategy) compareMultipleArticles(ctx context.Context, p
 	for _, url := range plan.Targets {
 		art, ok := articleSvc.GetArticle(ctx, url)
 		if !ok {
-			return "", fmt.Errorf("could not find article with URL: %s", url)
+			return nil, fmt.Errorf("could not find article with URL: %s", url)
 		}
 		articlesToCompare = append(articlesToCompare, art)
 	}
@@ -41,9 +42,9 @@ func (s *CompareMultipleStrategy) compareMultipleArticles(ctx context.Context, p
 	// 2. Create the advanced comparison prompt.
 	prompt, err := promptFactory.CreateCompareMultiplePrompt(articlesToCompare)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 3. Call the LLM for the final, synthesized analysis.
-	return articleSvc.CallSynthesisLLM(ctx, prompt)
+	return synthesize(ctx, articleSvc, prompt, articlesToCompare...)
 }
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/vector"
@@ -19,19 +21,21 @@ type CompareToneStrategy struct {
 func NewCompareToneStrategy() *CompareToneStrategy {
 	s := &CompareToneStrategy{}
 	s.doExecute = s.compareToneArticles
+	s.tool = newTool(planner.IntentCompareTone, "Compare the tone and writing style of two articles.", comparisonArgs{})
 	return s
 }
 
-func (s *CompareToneStrategy) compareToneArticles(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *CompareToneStrategy) compareToneArticles(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("COMPARE TONE STRATEGY: Performing tone comparison logic...")
 
 	if len(plan.Targets) < 2 {
-		return "Please provide at least two articles to compare tone.", nil
+		return newResult("Please provide at least two articles to compare tone."), nil
 	}
 
 	// Get articles from database
 	var articles []string
 	var summaries []string
+	var sources []*models.Article
 
 	for i, target := range plan.Targets {
 		if i >= 2 { // Limit to 2 articles for comparison
@@ -40,22 +44,26 @@ func (s *CompareToneStrategy) compareToneArticles(ctx context.Context, plan *pla
 
 		art, ok := articleSvc.GetArticle(ctx, target)
 		if !ok {
-			return fmt.Sprintf("Article not found: %s", target), nil
+			return newResult(fmt.Sprintf("Article not found: %s", target)), nil
 		}
 
+		sources = append(sources, art)
 		articles = append(articles, fmt.Sprintf("Article %d: %s", i+1, art.Title))
 		summaries = append(summaries, fmt.Sprintf("Article %d Summary: %s", i+1, art.Summary))
 	}
 
 	// Create comparison prompt
 	comparisonContent := strings.Join(summaries, "\n\n")
-	prompt := fmt.Sprintf("Compare the tone and writing style of these two articles:\n\n%s\n\nIdentify key differences in tone, formality, perspective, and overall approach. Provide specific examples from the summaries.", comparisonContent)
+	prompt := llm.NewRequest(
+		"Compare the tone and writing style of the two articles the user provides. Identify key differences in tone, formality, perspective, and overall approach. Provide specific examples from the summaries.",
+		comparisonContent,
+	)
 
 	// Call LLM for tone comparison
 	result, err := articleSvc.CallSynthesisLLM(ctx, prompt)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate tone comparison: %w", err)
+		return nil, fmt.Errorf("failed to generate tone comparison: %w", err)
 	}
 
-	return result, nil
+	return newResult(result, sources...), nil
 }
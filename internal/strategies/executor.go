@@ -2,17 +2,37 @@ package strategies
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/vector"
 )
 
+// Default limits for the steps of multi-step plans.
+const (
+	DefaultStepTimeout = 60 * time.Second
+	DefaultMaxParallel = 4
+)
+
 // Executor holds the map of all available strategies.
 type Executor struct {
 	Strategies map[planner.QueryIntent]planner.IntentStrategy
+	// StepTimeout bounds each step of a multi-step plan; zero means none.
+	StepTimeout time.Duration
+	// MaxParallel is how many steps of a plan run at once; zero means all
+	// that are ready.
+	MaxParallel int
 }
 
 // NewExecutor creates and initializes the map of all strategies.
@@ -30,18 +50,247 @@ func NewExecutor() *Executor {
 			planner.IntentCompareAllSentiment: NewCompareAllSentimentStrategy(),
 			planner.IntentCompareMultiple:     NewCompareMultipleStrategy(),
 		},
+		StepTimeout: DefaultStepTimeout,
+		MaxParallel: DefaultMaxParallel,
+	}
+}
+
+// Tools describes every registered strategy to the planner, sorted by name
+// so that the planner prompt stays the same between requests.
+func (e *Executor) Tools() []llm.Tool {
+	tools := make([]llm.Tool, 0, len(e.Strategies))
+	for _, strategy := range e.Strategies {
+		tools = append(tools, strategy.Tool())
 	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// Step dispatches a tool call to the strategy it names and converts its
+// arguments into a plan step. A topic becomes the step's first parameter.
+func (e *Executor) Step(call llm.ToolCall) (planner.PlanStep, error) {
+	for intent, strategy := range e.Strategies {
+		if strategy.Tool().Name != call.Name {
+			continue
+		}
+		var args toolArguments
+		if len(call.Arguments) > 0 {
+			if err := json.Unmarshal(call.Arguments, &args); err != nil {
+				return planner.PlanStep{}, fmt.Errorf("invalid arguments for %s: %w", call.Name, err)
+			}
+		}
+		step := planner.PlanStep{
+			ID:         args.ID,
+			Intent:     intent,
+			Targets:    args.Targets,
+			Parameters: []string{},
+			Since:      args.Since,
+			Until:      args.Until,
+			Inputs:     args.Inputs,
+		}
+		if step.Targets == nil {
+			step.Targets = []string{}
+		}
+		if args.Topic != "" {
+			step.Parameters = append(step.Parameters, args.Topic)
+		}
+		return step, nil
+	}
+	return planner.PlanStep{}, fmt.Errorf("no strategy provides the tool %q", call.Name)
 }
 
 // ExecutePlan finds the correct strategy for the plan's intent and executes it.
-// This method acts as a smart dispatcher, delegating the work.
-func (e *Executor) ExecutePlan(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+// This method acts as a smart dispatcher, delegating the work. A plan with
+// several steps runs them as a graph and synthesizes one answer from theirs.
+func (e *Executor) ExecutePlan(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+	if len(plan.Steps) > 1 {
+		return e.executeSteps(ctx, plan, articleSvc, promptFactory, vectorSvc)
+	}
+	return e.execute(ctx, plan, articleSvc, promptFactory, vectorSvc)
+}
+
+// stepRun is the outcome of one step of a plan.
+type stepRun struct {
+	result *planner.Result
+	err    error
+	used   bool // A step that succeeded took this step's sources as input
+	done   chan struct{}
+}
+
+// executeSteps runs the plan's steps, each as soon as the steps it takes
+// input from have finished, and up to MaxParallel at once. A step gets the
+// sources of its inputs as extra targets and is skipped when one of them
+// failed. The answers no other step built on are then synthesized into one,
+// noting the steps that failed; sources are listed once, in step order.
+func (e *Executor) executeSteps(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+	deps, err := planner.Dependencies(plan.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*stepRun, len(plan.Steps))
+	for i := range runs {
+		runs[i] = &stepRun{done: make(chan struct{})}
+	}
+	var slots chan struct{}
+	if e.MaxParallel > 0 {
+		slots = make(chan struct{}, e.MaxParallel)
+	}
+	// Only the final answer streams; the steps' answers are material for it.
+	stepCtx := llm.WithStreamHandler(ctx, nil)
+
+	var wg sync.WaitGroup
+	for i, step := range plan.Steps {
+		wg.Add(1)
+		go func(i int, step planner.PlanStep) {
+			defer wg.Done()
+			run := runs[i]
+			defer close(run.done)
+			run.result, run.err = e.executeStep(stepCtx, plan.Question, step, deps[i], runs, slots, articleSvc, promptFactory, vectorSvc)
+			if run.err != nil {
+				run.err = fmt.Errorf("step %d (%s) failed: %w", i+1, step.Intent, run.err)
+			}
+		}(i, step)
+	}
+	wg.Wait()
+
+	combined := newResult("")
+	seen := make(map[string]bool)
+	var firstErr error
+	for i, run := range runs {
+		if run.err != nil {
+			if firstErr == nil {
+				firstErr = run.err
+			}
+			continue
+		}
+		for _, dep := range deps[i] {
+			runs[dep].used = true
+		}
+		for _, source := range run.result.Sources {
+			if !seen[source.URL] {
+				seen[source.URL] = true
+				combined.Sources = append(combined.Sources, source)
+			}
+		}
+	}
+
+	for i, run := range runs {
+		step := plan.Steps[i]
+		out := planner.StepResult{ID: step.StepID(i), Intent: step.Intent}
+		if run.err != nil {
+			out.Error = run.err.Error()
+		} else {
+			out.Answer, out.Sources = run.result.Answer, run.result.Sources
+		}
+		combined.Steps = append(combined.Steps, out)
+	}
+
+	var answers, parts []string
+	for i, run := range runs {
+		step := plan.Steps[i]
+		switch {
+		case run.err != nil:
+			parts = append(parts, fmt.Sprintf("Step %d (%s) could not be answered: %v", i+1, step.Intent, run.err))
+		case !run.used:
+			answers = append(answers, run.result.Answer)
+			parts = append(parts, fmt.Sprintf("Step %d (%s):\n%s", i+1, step.Intent, run.result.Answer))
+		}
+	}
+	if len(answers) == 0 {
+		return nil, firstErr
+	}
+	if firstErr == nil && len(answers) == 1 {
+		combined.Answer = answers[0]
+		return combined, nil
+	}
+	combined.Answer = e.synthesize(ctx, plan.Question, parts, answers, articleSvc, promptFactory)
+	return combined, nil
+}
+
+// executeStep waits for the steps a step takes input from, then runs it with
+// their sources added to its targets, within StepTimeout.
+func (e *Executor) executeStep(ctx context.Context, question string, step planner.PlanStep, deps []int, runs []*stepRun, slots chan struct{}, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+	targets := append([]string{}, step.Targets...)
+	for _, dep := range deps {
+		select {
+		case <-runs[dep].done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if runs[dep].err != nil {
+			return nil, fmt.Errorf("skipped because step %d failed", dep+1)
+		}
+		for _, source := range runs[dep].result.Sources {
+			if !slices.Contains(targets, source.URL) {
+				targets = append(targets, source.URL)
+			}
+		}
+	}
+	step.Targets = targets
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if e.StepTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.StepTimeout)
+		defer cancel()
+	}
+
+	result, err := e.execute(ctx, step.Plan(question), articleSvc, promptFactory, vectorSvc)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %s: %w", e.StepTimeout, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// synthesize writes one answer to the question from the parts the steps
+// produced. Without a prompt factory, or when the synthesis call fails, the
+// steps' answers are joined by a rule instead. The call is bounded by
+// StepTimeout like the steps.
+func (e *Executor) synthesize(ctx context.Context, question string, parts, answers []string, articleSvc article.Service, promptFactory *prompts.Factory) string {
+	joined := strings.Join(answers, "\n\n---\n\n")
+	if articleSvc == nil || promptFactory == nil {
+		return joined
+	}
+	prompt, err := promptFactory.CreateSynthesizeStepsPrompt(question, parts)
+	if err != nil {
+		log.Printf("WARNING: Failed to create the synthesis prompt, joining the step answers: %v", err)
+		return joined
+	}
+	if e.StepTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.StepTimeout)
+		defer cancel()
+	}
+	answer, err := articleSvc.CallSynthesisLLM(ctx, prompt)
+	if err != nil {
+		log.Printf("WARNING: Failed to synthesize the step answers, joining them: %v", err)
+		return joined
+	}
+	return answer
+}
+
+// execute runs the strategy registered for the plan's intent.
+func (e *Executor) execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	strategy, ok := e.Strategies[plan.Intent]
 	if !ok {
 		// Fallback for any intent that isn't registered.
-		return fmt.Sprintf("I'm sorry, I don't know how to handle the intent: %s", plan.Intent), nil
+		return newResult(fmt.Sprintf("I'm sorry, I don't know how to handle the intent: %s", plan.Intent)), nil
 	}
 
+	// Synthesis calls go to the model configured for the intent, if any.
+	ctx = llm.WithIntent(ctx, string(plan.Intent))
+
 	// The call to strategy.Execute will trigger the BaseStrategy's template method.
 	return strategy.Execute(ctx, plan, articleSvc, promptFactory, vectorSvc)
 }
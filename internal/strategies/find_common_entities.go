@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/models"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/repository"
@@ -19,10 +20,11 @@ type FindCommonEntitiesStrategy struct {
 func NewFindCommonEntitiesStrategy() *FindCommonEntitiesStrategy {
 	s := &FindCommonEntitiesStrategy{}
 	s.doExecute = s.findCommonEntities
+	s.tool = newTool(planner.IntentFindCommonEntities, "List the people, companies and places that articles have in common.", entitiesArgs{})
 	return s
 }
 
-func (s *FindCommonEntitiesStrategy) findCommonEntities(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *FindCommonEntitiesStrategy) findCommonEntities(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("FIND COMMON ENTITIES STRATEGY: Performing entity extraction logic...")
 
 	// Get common entities from database using efficient PostgreSQL query
@@ -37,11 +39,11 @@ func (s *FindCommonEntitiesStrategy) findCommonEntities(ctx context.Context, pla
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("failed to find common entities: %w", err)
+		return nil, fmt.Errorf("failed to find common entities: %w", err)
 	}
 
 	if len(entityCounts) == 0 {
-		return "No entities found in the database.", nil
+		return newResult("No entities found in the database."), nil
 	}
 
 	// Return the raw entity counts as JSON-like string
@@ -50,5 +52,13 @@ func (s *FindCommonEntitiesStrategy) findCommonEntities(ctx context.Context, pla
 		result += fmt.Sprintf("- %s: %d occurrences\n", entity.Entity, entity.Count)
 	}
 
-	return result, nil
+	// Cite the articles the entities were counted across, when the query named them.
+	var sources []*models.Article
+	for _, url := range plan.Targets {
+		if art, ok := articleSvc.GetArticle(ctx, url); ok {
+			sources = append(sources, art)
+		}
+	}
+
+	return newResult(result, sources...), nil
 }
@@ -18,28 +18,30 @@ type FindTopicStrategy struct {
 func NewFindTopicStrategy() *FindTopicStrategy {
 	s := &FindTopicStrategy{}
 	s.doExecute = s.findTopicArticles
+	s.tool = newTool(planner.IntentFindTopic, "Find the articles discussing a topic, e.g. \"what articles discuss finance?\".", topicArgs{})
 	return s
 }
 
-func (s *FindTopicStrategy) findTopicArticles(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *FindTopicStrategy) findTopicArticles(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("FIND TOPIC STRATEGY: Performing vector search and synthesis...")
 
 	if len(plan.Parameters) == 0 {
-		return "Please specify a topic to search for.", nil
+		return newResult("Please specify a topic to search for."), nil
 	}
 	topic := plan.Parameters[0]
 
 	// 1. Perform Semantic Search (Vector Search)
 	// Instead of getting all articles, we ask the article service (which uses Weaviate)
-	// to find the top 3 most relevant articles for the topic.
-	relevantArticles, err := articleSvc.SearchSimilarArticles(ctx, topic, 3)
+	// to find the top 3 most relevant articles for the topic, within the
+	// plan's date range if it sets one.
+	relevantArticles, err := searchTopic(ctx, articleSvc, topic, 3, plan)
 	if err != nil {
-		return "", fmt.Errorf("vector search failed: %w", err)
+		return nil, fmt.Errorf("vector search failed: %w", err)
 	}
 
 	// 2. Handle No Results
 	if len(relevantArticles) == 0 {
-		return fmt.Sprintf("I could not find any articles discussing '%s'.", topic), nil
+		return newResult(fmt.Sprintf("I could not find any articles discussing '%s'.", topic)), nil
 	}
 
 	// 3. Craft a Synthesis Prompt for the LLM
@@ -47,10 +49,10 @@ func (s *FindTopicStrategy) findTopicArticles(ctx context.Context, plan *planner
 	// to create a final answer based on their content.
 	prompt, err := promptFactory.CreateFindTopicPrompt(topic, relevantArticles)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 4. Call the LLM for the Final Answer
 	// The LLM will generate a natural language response explaining what it found.
-	return articleSvc.CallSynthesisLLM(ctx, prompt)
+	return synthesize(ctx, articleSvc, prompt, relevantArticles...)
 }
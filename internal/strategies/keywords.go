@@ -17,17 +17,18 @@ type KeywordsStrategy struct {
 func NewKeywordsStrategy() *KeywordsStrategy {
 	s := &KeywordsStrategy{}
 	s.doExecute = s.extractKeywords
+	s.tool = newTool(planner.IntentKeywords, "Extract the keywords and main topics of an article, e.g. \"what are the main topics of...\".", articleArgs{})
 	return s
 }
 
-func (s *KeywordsStrategy) extractKeywords(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *KeywordsStrategy) extractKeywords(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("KEYWORDS STRATEGY: Performing specific keyword extraction logic...")
 	if len(plan.Targets) == 0 {
-		return "Please specify which article you want to extract keywords from.", nil
+		return newResult("Please specify which article you want to extract keywords from."), nil
 	}
 	art, ok := articleSvc.GetArticle(ctx, plan.Targets[0])
 	if !ok {
-		return "I couldn't find the requested article.", nil
+		return newResult("I couldn't find the requested article."), nil
 	}
 
 	// Use existing topics and entities from the database instead of fetching content
@@ -48,19 +49,14 @@ func (s *KeywordsStrategy) extractKeywords(ctx context.Context, plan *planner.Qu
 			}
 		}
 
-		return result, nil
+		return newResult(result, art), nil
 	}
 
 	// Fallback: if no topics/entities available, try to extract from summary
 	prompt, err := promptFactory.CreateKeywordsPrompt(art.Title, art.Summary)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	keywords, err := articleSvc.CallSynthesisLLM(ctx, prompt)
-	if err != nil {
-		return "", err
-	}
-
-	return keywords, nil
+	return synthesize(ctx, articleSvc, prompt, art)
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/models"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/vector"
@@ -19,17 +20,18 @@ type SentimentStrategy struct {
 func NewSentimentStrategy() *SentimentStrategy {
 	s := &SentimentStrategy{}
 	s.doExecute = s.analyzeSentiment
+	s.tool = newTool(planner.IntentSentiment, "Report the sentiment of one or more articles, e.g. \"what is the sentiment of the article about...\".", articleArgs{})
 	return s
 }
 
-func (s *SentimentStrategy) analyzeSentiment(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *SentimentStrategy) analyzeSentiment(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("SENTIMENT STRATEGY: Performing specific sentiment analysis logic...")
 	if len(plan.Targets) == 0 {
-		return "Please specify which article you want to analyze sentiment for.", nil
+		return newResult("Please specify which article you want to analyze sentiment for."), nil
 	}
 
 	var results []string
-	var foundArticles int
+	var sources []*models.Article
 
 	for i, target := range plan.Targets {
 		art, ok := articleSvc.GetArticle(ctx, target)
@@ -38,7 +40,7 @@ func (s *SentimentStrategy) analyzeSentiment(ctx context.Context, plan *planner.
 			continue
 		}
 
-		foundArticles++
+		sources = append(sources, art)
 		if art.Sentiment != "" {
 			sentimentDesc := s.getSentimentDescription(art.Sentiment)
 			log.Printf("DEBUG: Original sentiment: '%s', Parsed description: '%s'", art.Sentiment, sentimentDesc)
@@ -48,11 +50,11 @@ func (s *SentimentStrategy) analyzeSentiment(ctx context.Context, plan *planner.
 		}
 	}
 
-	if foundArticles == 0 {
-		return "No articles found for sentiment analysis.", nil
+	if len(sources) == 0 {
+		return newResult("No articles found for sentiment analysis."), nil
 	}
 
-	return fmt.Sprintf("Sentiment Analysis Results:\n\n%s", strings.Join(results, "\n")), nil
+	return newResult(fmt.Sprintf("Sentiment Analysis Results:\n\n%s", strings.Join(results, "\n")), sources...), nil
 }
 
 // getSentimentDescription converts numeric sentiment to descriptive text
@@ -18,13 +18,14 @@ type SummarizeStrategy struct {
 func NewSummarizeStrategy() *SummarizeStrategy {
 	s := &SummarizeStrategy{}
 	s.doExecute = s.summarizeArticle
+	s.tool = newTool(planner.IntentSummarize, "Summarize a single article, e.g. \"summarize the article about...\".", articleArgs{})
 	return s
 }
 
-func (s *SummarizeStrategy) summarizeArticle(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (s *SummarizeStrategy) summarizeArticle(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	log.Println("SUMMARIZE STRATEGY: Retrieving cached summary...")
 	if len(plan.Targets) == 0 {
-		return "Please specify which article you want to summarize.", nil
+		return newResult("Please specify which article you want to summarize."), nil
 	}
 
 	targetURL := plan.Targets[0]
@@ -32,12 +33,12 @@ func (s *SummarizeStrategy) summarizeArticle(ctx context.Context, plan *planner.
 	// Get the article from the database (summary should already be cached from initial analysis)
 	art, ok := articleSvc.GetArticle(ctx, targetURL)
 	if !ok {
-		return "", fmt.Errorf("article not found in database: %s", targetURL)
+		return nil, fmt.Errorf("article not found in database: %s", targetURL)
 	}
 
 	if art.Summary == "" {
-		return "", fmt.Errorf("summary not available for article: %s", art.Title)
+		return nil, fmt.Errorf("summary not available for article: %s", art.Title)
 	}
 
-	return art.Summary, nil
+	return newResult(art.Summary, art), nil
 }
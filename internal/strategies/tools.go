@@ -0,0 +1,108 @@
+package strategies
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+)
+
+// The argument types below describe each tool's parameters. Their schemas are
+// derived with llm.SchemaFor; calls are decoded into toolArguments, which
+// holds the fields of all of them.
+
+// articleArgs are the arguments of tools working on named articles.
+type articleArgs struct {
+	Targets []string `json:"targets" desc:"URLs of the articles, as listed in the available articles"`
+}
+
+// comparisonArgs are the arguments of tools comparing named articles.
+type comparisonArgs struct {
+	Targets []string `json:"targets" desc:"URLs of the two or more articles to compare, as listed in the available articles; empty when inputs supply them"`
+}
+
+// entitiesArgs are the arguments of the common entities tool.
+type entitiesArgs struct {
+	Targets []string `json:"targets,omitempty" desc:"URLs of the articles to compare; all articles when empty"`
+}
+
+// topicArgs are the arguments of tools searching the library by topic.
+type topicArgs struct {
+	Topic string `json:"topic" desc:"The topic to look for, e.g. \"AI regulation\""`
+	Since string `json:"since,omitempty" desc:"Only consider articles added on or after this date" jsonschema:"format=date"`
+	Until string `json:"until,omitempty" desc:"Only consider articles added on or before this date" jsonschema:"format=date"`
+}
+
+// positivityArgs are the arguments of the positivity comparison tool.
+type positivityArgs struct {
+	Topic   string   `json:"topic" desc:"The topic the articles are compared on"`
+	Targets []string `json:"targets,omitempty" desc:"URLs of the two articles to compare; found by topic when empty"`
+}
+
+// stepArgs are the arguments every tool takes, to chain the steps of a plan.
+type stepArgs struct {
+	ID     string   `json:"id,omitempty" desc:"A short name for this call, needed only when a later call takes its articles as input"`
+	Inputs []string `json:"inputs,omitempty" desc:"Ids of earlier calls whose articles are added to this call's targets, e.g. the articles a find_by_topic call finds"`
+}
+
+// toolArguments holds the arguments any tool may be called with.
+type toolArguments struct {
+	Targets []string `json:"targets"`
+	Topic   string   `json:"topic"`
+	Since   string   `json:"since"`
+	Until   string   `json:"until"`
+	ID      string   `json:"id"`
+	Inputs  []string `json:"inputs"`
+}
+
+// newTool describes the strategy for an intent. The tool is named after the
+// intent in lower case, e.g. "find_by_topic", and takes the step arguments
+// besides its own.
+func newTool(intent planner.QueryIntent, description string, args any) llm.Tool {
+	params := llm.SchemaFor(args)
+	for name, prop := range llm.SchemaFor(stepArgs{}).Properties {
+		params.Properties[name] = prop
+	}
+	return llm.Tool{
+		Name:        strings.ToLower(string(intent)),
+		Description: description,
+		Parameters:  params,
+	}
+}
+
+// searchTopic finds the articles most relevant to the topic, keeping only
+// those added within the plan's date range. A range widens the search so
+// that filtering still leaves up to limit articles.
+func searchTopic(ctx context.Context, articleSvc article.Service, topic string, limit int, plan *planner.QueryPlan) ([]*models.Article, error) {
+	if plan.Since == "" && plan.Until == "" {
+		return articleSvc.SearchSimilarArticles(ctx, topic, limit)
+	}
+	candidates, err := articleSvc.SearchSimilarArticles(ctx, topic, limit*4)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tool schema validated the dates; a plan built elsewhere with an
+	// unparsable date is left unbounded on that side.
+	since, _ := time.Parse(time.DateOnly, plan.Since)
+	until, err := time.Parse(time.DateOnly, plan.Until)
+	if err == nil {
+		until = until.AddDate(0, 0, 1) // Include the whole last day
+	}
+
+	var articles []*models.Article
+	for _, art := range candidates {
+		if art.ProcessedAt.Before(since) || (!until.IsZero() && !art.ProcessedAt.Before(until)) {
+			continue
+		}
+		articles = append(articles, art)
+		if len(articles) == limit {
+			break
+		}
+	}
+	return articles, nil
+}
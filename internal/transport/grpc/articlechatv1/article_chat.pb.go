@@ -0,0 +1,1439 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.28.3
+// source: articlechat/v1/article_chat.proto
+
+package articlechatv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ChatRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Query string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Optional; a new session is started when empty.
+	SessionId     string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatRequest) Reset() {
+	*x = ChatRequest{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatRequest) ProtoMessage() {}
+
+func (x *ChatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatRequest.ProtoReflect.Descriptor instead.
+func (*ChatRequest) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ChatRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ChatRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type QueryPlan struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Intent     string                 `protobuf:"bytes,1,opt,name=intent,proto3" json:"intent,omitempty"`
+	Targets    []string               `protobuf:"bytes,2,rep,name=targets,proto3" json:"targets,omitempty"`
+	Parameters []string               `protobuf:"bytes,3,rep,name=parameters,proto3" json:"parameters,omitempty"`
+	Question   string                 `protobuf:"bytes,4,opt,name=question,proto3" json:"question,omitempty"`
+	// Only consider articles added in this date range (YYYY-MM-DD); unset when empty.
+	Since string `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+	Until string `protobuf:"bytes,6,opt,name=until,proto3" json:"until,omitempty"`
+	// Every intent when the planner chose several. Intent and the date range
+	// are then the first step's; targets and parameters combine all steps'.
+	Steps         []*PlanStep `protobuf:"bytes,7,rep,name=steps,proto3" json:"steps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryPlan) Reset() {
+	*x = QueryPlan{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryPlan) ProtoMessage() {}
+
+func (x *QueryPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryPlan.ProtoReflect.Descriptor instead.
+func (*QueryPlan) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *QueryPlan) GetIntent() string {
+	if x != nil {
+		return x.Intent
+	}
+	return ""
+}
+
+func (x *QueryPlan) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *QueryPlan) GetParameters() []string {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *QueryPlan) GetQuestion() string {
+	if x != nil {
+		return x.Question
+	}
+	return ""
+}
+
+func (x *QueryPlan) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *QueryPlan) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+func (x *QueryPlan) GetSteps() []*PlanStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+// PlanStep is one intent of a plan with several.
+type PlanStep struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Referenced by the inputs of later steps.
+	Id         string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Intent     string   `protobuf:"bytes,2,opt,name=intent,proto3" json:"intent,omitempty"`
+	Targets    []string `protobuf:"bytes,3,rep,name=targets,proto3" json:"targets,omitempty"`
+	Parameters []string `protobuf:"bytes,4,rep,name=parameters,proto3" json:"parameters,omitempty"`
+	Since      string   `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+	Until      string   `protobuf:"bytes,6,opt,name=until,proto3" json:"until,omitempty"`
+	// IDs of earlier steps whose sources are added to the step's targets.
+	Inputs        []string `protobuf:"bytes,7,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanStep) Reset() {
+	*x = PlanStep{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanStep) ProtoMessage() {}
+
+func (x *PlanStep) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanStep.ProtoReflect.Descriptor instead.
+func (*PlanStep) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PlanStep) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PlanStep) GetIntent() string {
+	if x != nil {
+		return x.Intent
+	}
+	return ""
+}
+
+func (x *PlanStep) GetTargets() []string {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *PlanStep) GetParameters() []string {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *PlanStep) GetSince() string {
+	if x != nil {
+		return x.Since
+	}
+	return ""
+}
+
+func (x *PlanStep) GetUntil() string {
+	if x != nil {
+		return x.Until
+	}
+	return ""
+}
+
+func (x *PlanStep) GetInputs() []string {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+// StepResult is the output of one step of a plan.
+type StepResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Intent  string                 `protobuf:"bytes,2,opt,name=intent,proto3" json:"intent,omitempty"`
+	Answer  string                 `protobuf:"bytes,3,opt,name=answer,proto3" json:"answer,omitempty"`
+	Sources []*Source              `protobuf:"bytes,4,rep,name=sources,proto3" json:"sources,omitempty"`
+	// Why the step failed or was skipped; empty when it succeeded.
+	Error         string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepResult) Reset() {
+	*x = StepResult{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepResult) ProtoMessage() {}
+
+func (x *StepResult) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepResult.ProtoReflect.Descriptor instead.
+func (*StepResult) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StepResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StepResult) GetIntent() string {
+	if x != nil {
+		return x.Intent
+	}
+	return ""
+}
+
+func (x *StepResult) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *StepResult) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *StepResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Candidate is an article an ambiguous query could refer to.
+type Candidate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Title string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// How well the article matches, from 0 to 1.
+	Score         float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candidate) Reset() {
+	*x = Candidate{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candidate) ProtoMessage() {}
+
+func (x *Candidate) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
+func (*Candidate) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Candidate) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Candidate) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Candidate) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Source struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Title string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// Vector-search relevance; 1 for articles named in the query.
+	Score         float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Source) Reset() {
+	*x = Source{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Source) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Source) ProtoMessage() {}
+
+func (x *Source) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Source.ProtoReflect.Descriptor instead.
+func (*Source) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Source) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Source) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Source) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Usage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PromptTokens     int32                  `protobuf:"varint,1,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	CompletionTokens int32                  `protobuf:"varint,2,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	TotalTokens      int32                  `protobuf:"varint,3,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Usage) Reset() {
+	*x = Usage{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Usage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Usage) ProtoMessage() {}
+
+func (x *Usage) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Usage.ProtoReflect.Descriptor instead.
+func (*Usage) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Usage) GetPromptTokens() int32 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *Usage) GetCompletionTokens() int32 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *Usage) GetTotalTokens() int32 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+type ChatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Answer        string                 `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Plan          *QueryPlan             `protobuf:"bytes,3,opt,name=plan,proto3" json:"plan,omitempty"`
+	Sources       []*Source              `protobuf:"bytes,4,rep,name=sources,proto3" json:"sources,omitempty"`
+	PromptVersion string                 `protobuf:"bytes,5,opt,name=prompt_version,json=promptVersion,proto3" json:"prompt_version,omitempty"`
+	Model         string                 `protobuf:"bytes,6,opt,name=model,proto3" json:"model,omitempty"`
+	// Tokens consumed by this request; zero on a cache hit.
+	Usage *Usage `protobuf:"bytes,7,opt,name=usage,proto3" json:"usage,omitempty"`
+	// Milliseconds per stage, plus "total".
+	LatencyMs map[string]int64 `protobuf:"bytes,8,rep,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Cached    bool             `protobuf:"varint,9,opt,name=cached,proto3" json:"cached,omitempty"`
+	// Priced with the usage price table.
+	CostUsd float64 `protobuf:"fixed64,10,opt,name=cost_usd,json=costUsd,proto3" json:"cost_usd,omitempty"`
+	// A last-resort fallback provider answered.
+	Degraded bool `protobuf:"varint,11,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	// When set, the answer asks which of these articles the query meant
+	// instead of answering; the plan targets them all.
+	Clarification []*Candidate `protobuf:"bytes,12,rep,name=clarification,proto3" json:"clarification,omitempty"`
+	// Output of each step, in plan order, when the plan had several.
+	Steps         []*StepResult `protobuf:"bytes,13,rep,name=steps,proto3" json:"steps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatResponse) Reset() {
+	*x = ChatResponse{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatResponse) ProtoMessage() {}
+
+func (x *ChatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatResponse.ProtoReflect.Descriptor instead.
+func (*ChatResponse) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChatResponse) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *ChatResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ChatResponse) GetPlan() *QueryPlan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *ChatResponse) GetSources() []*Source {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *ChatResponse) GetPromptVersion() string {
+	if x != nil {
+		return x.PromptVersion
+	}
+	return ""
+}
+
+func (x *ChatResponse) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *ChatResponse) GetUsage() *Usage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *ChatResponse) GetLatencyMs() map[string]int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return nil
+}
+
+func (x *ChatResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *ChatResponse) GetCostUsd() float64 {
+	if x != nil {
+		return x.CostUsd
+	}
+	return 0
+}
+
+func (x *ChatResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *ChatResponse) GetClarification() []*Candidate {
+	if x != nil {
+		return x.Clarification
+	}
+	return nil
+}
+
+func (x *ChatResponse) GetSteps() []*StepResult {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+type ChatStreamEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ChatStreamEvent_Plan
+	//	*ChatStreamEvent_Token
+	//	*ChatStreamEvent_Done
+	Event         isChatStreamEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatStreamEvent) Reset() {
+	*x = ChatStreamEvent{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatStreamEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatStreamEvent) ProtoMessage() {}
+
+func (x *ChatStreamEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatStreamEvent.ProtoReflect.Descriptor instead.
+func (*ChatStreamEvent) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ChatStreamEvent) GetEvent() isChatStreamEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ChatStreamEvent) GetPlan() *QueryPlan {
+	if x != nil {
+		if x, ok := x.Event.(*ChatStreamEvent_Plan); ok {
+			return x.Plan
+		}
+	}
+	return nil
+}
+
+func (x *ChatStreamEvent) GetToken() string {
+	if x != nil {
+		if x, ok := x.Event.(*ChatStreamEvent_Token); ok {
+			return x.Token
+		}
+	}
+	return ""
+}
+
+func (x *ChatStreamEvent) GetDone() *ChatResponse {
+	if x != nil {
+		if x, ok := x.Event.(*ChatStreamEvent_Done); ok {
+			return x.Done
+		}
+	}
+	return nil
+}
+
+type isChatStreamEvent_Event interface {
+	isChatStreamEvent_Event()
+}
+
+type ChatStreamEvent_Plan struct {
+	Plan *QueryPlan `protobuf:"bytes,1,opt,name=plan,proto3,oneof"`
+}
+
+type ChatStreamEvent_Token struct {
+	Token string `protobuf:"bytes,2,opt,name=token,proto3,oneof"`
+}
+
+type ChatStreamEvent_Done struct {
+	Done *ChatResponse `protobuf:"bytes,3,opt,name=done,proto3,oneof"`
+}
+
+func (*ChatStreamEvent_Plan) isChatStreamEvent_Event() {}
+
+func (*ChatStreamEvent_Token) isChatStreamEvent_Event() {}
+
+func (*ChatStreamEvent_Done) isChatStreamEvent_Event() {}
+
+type Article struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Excerpt       string                 `protobuf:"bytes,3,opt,name=excerpt,proto3" json:"excerpt,omitempty"`
+	Summary       string                 `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	Sentiment     string                 `protobuf:"bytes,5,opt,name=sentiment,proto3" json:"sentiment,omitempty"`
+	Topics        []string               `protobuf:"bytes,6,rep,name=topics,proto3" json:"topics,omitempty"`
+	Entities      []string               `protobuf:"bytes,7,rep,name=entities,proto3" json:"entities,omitempty"`
+	ProcessedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=processed_at,json=processedAt,proto3" json:"processed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Article) Reset() {
+	*x = Article{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Article) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Article) ProtoMessage() {}
+
+func (x *Article) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Article.ProtoReflect.Descriptor instead.
+func (*Article) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Article) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Article) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Article) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+func (x *Article) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Article) GetSentiment() string {
+	if x != nil {
+		return x.Sentiment
+	}
+	return ""
+}
+
+func (x *Article) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *Article) GetEntities() []string {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *Article) GetProcessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedAt
+	}
+	return nil
+}
+
+type AddArticleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddArticleRequest) Reset() {
+	*x = AddArticleRequest{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddArticleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddArticleRequest) ProtoMessage() {}
+
+func (x *AddArticleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddArticleRequest.ProtoReflect.Descriptor instead.
+func (*AddArticleRequest) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AddArticleRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// ListArticlesRequest narrows down the catalog. Unset fields are ignored.
+type ListArticlesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Sentiment string                 `protobuf:"bytes,1,opt,name=sentiment,proto3" json:"sentiment,omitempty"`
+	Topic     string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Entity    string                 `protobuf:"bytes,3,opt,name=entity,proto3" json:"entity,omitempty"`
+	// Host of the article URL, subdomains included.
+	Source string `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	// Substring match on title, excerpt or summary.
+	Text            string                 `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	ProcessedAfter  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=processed_after,json=processedAfter,proto3" json:"processed_after,omitempty"`
+	ProcessedBefore *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=processed_before,json=processedBefore,proto3" json:"processed_before,omitempty"`
+	// Opaque cursor returned by a previous page.
+	Cursor        string `protobuf:"bytes,8,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32  `protobuf:"varint,9,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListArticlesRequest) Reset() {
+	*x = ListArticlesRequest{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListArticlesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArticlesRequest) ProtoMessage() {}
+
+func (x *ListArticlesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArticlesRequest.ProtoReflect.Descriptor instead.
+func (*ListArticlesRequest) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListArticlesRequest) GetSentiment() string {
+	if x != nil {
+		return x.Sentiment
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetProcessedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedAfter
+	}
+	return nil
+}
+
+func (x *ListArticlesRequest) GetProcessedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedBefore
+	}
+	return nil
+}
+
+func (x *ListArticlesRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListArticlesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListArticlesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Articles      []*Article             `protobuf:"bytes,1,rep,name=articles,proto3" json:"articles,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListArticlesResponse) Reset() {
+	*x = ListArticlesResponse{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListArticlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListArticlesResponse) ProtoMessage() {}
+
+func (x *ListArticlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListArticlesResponse.ProtoReflect.Descriptor instead.
+func (*ListArticlesResponse) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListArticlesResponse) GetArticles() []*Article {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+func (x *ListArticlesResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type FindEntitiesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Articles to count across; all articles when empty.
+	Urls          []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindEntitiesRequest) Reset() {
+	*x = FindEntitiesRequest{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindEntitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindEntitiesRequest) ProtoMessage() {}
+
+func (x *FindEntitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindEntitiesRequest.ProtoReflect.Descriptor instead.
+func (*FindEntitiesRequest) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FindEntitiesRequest) GetUrls() []string {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type EntityCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        string                 `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntityCount) Reset() {
+	*x = EntityCount{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntityCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityCount) ProtoMessage() {}
+
+func (x *EntityCount) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityCount.ProtoReflect.Descriptor instead.
+func (*EntityCount) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EntityCount) GetEntity() string {
+	if x != nil {
+		return x.Entity
+	}
+	return ""
+}
+
+func (x *EntityCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type FindEntitiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entities      []*EntityCount         `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindEntitiesResponse) Reset() {
+	*x = FindEntitiesResponse{}
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindEntitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindEntitiesResponse) ProtoMessage() {}
+
+func (x *FindEntitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_articlechat_v1_article_chat_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindEntitiesResponse.ProtoReflect.Descriptor instead.
+func (*FindEntitiesResponse) Descriptor() ([]byte, []int) {
+	return file_articlechat_v1_article_chat_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *FindEntitiesResponse) GetEntities() []*EntityCount {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *FindEntitiesResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+var File_articlechat_v1_article_chat_proto protoreflect.FileDescriptor
+
+const file_articlechat_v1_article_chat_proto_rawDesc = "" +
+	"\n" +
+	"!articlechat/v1/article_chat.proto\x12\x0earticlechat.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"B\n" +
+	"\vChatRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\"\xd5\x01\n" +
+	"\tQueryPlan\x12\x16\n" +
+	"\x06intent\x18\x01 \x01(\tR\x06intent\x12\x18\n" +
+	"\atargets\x18\x02 \x03(\tR\atargets\x12\x1e\n" +
+	"\n" +
+	"parameters\x18\x03 \x03(\tR\n" +
+	"parameters\x12\x1a\n" +
+	"\bquestion\x18\x04 \x01(\tR\bquestion\x12\x14\n" +
+	"\x05since\x18\x05 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x06 \x01(\tR\x05until\x12.\n" +
+	"\x05steps\x18\a \x03(\v2\x18.articlechat.v1.PlanStepR\x05steps\"\xb0\x01\n" +
+	"\bPlanStep\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06intent\x18\x02 \x01(\tR\x06intent\x12\x18\n" +
+	"\atargets\x18\x03 \x03(\tR\atargets\x12\x1e\n" +
+	"\n" +
+	"parameters\x18\x04 \x03(\tR\n" +
+	"parameters\x12\x14\n" +
+	"\x05since\x18\x05 \x01(\tR\x05since\x12\x14\n" +
+	"\x05until\x18\x06 \x01(\tR\x05until\x12\x16\n" +
+	"\x06inputs\x18\a \x03(\tR\x06inputs\"\x94\x01\n" +
+	"\n" +
+	"StepResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06intent\x18\x02 \x01(\tR\x06intent\x12\x16\n" +
+	"\x06answer\x18\x03 \x01(\tR\x06answer\x120\n" +
+	"\asources\x18\x04 \x03(\v2\x16.articlechat.v1.SourceR\asources\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"I\n" +
+	"\tCandidate\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\"F\n" +
+	"\x06Source\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\"|\n" +
+	"\x05Usage\x12#\n" +
+	"\rprompt_tokens\x18\x01 \x01(\x05R\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\x02 \x01(\x05R\x10completionTokens\x12!\n" +
+	"\ftotal_tokens\x18\x03 \x01(\x05R\vtotalTokens\"\xdc\x04\n" +
+	"\fChatResponse\x12\x16\n" +
+	"\x06answer\x18\x01 \x01(\tR\x06answer\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12-\n" +
+	"\x04plan\x18\x03 \x01(\v2\x19.articlechat.v1.QueryPlanR\x04plan\x120\n" +
+	"\asources\x18\x04 \x03(\v2\x16.articlechat.v1.SourceR\asources\x12%\n" +
+	"\x0eprompt_version\x18\x05 \x01(\tR\rpromptVersion\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12+\n" +
+	"\x05usage\x18\a \x01(\v2\x15.articlechat.v1.UsageR\x05usage\x12J\n" +
+	"\n" +
+	"latency_ms\x18\b \x03(\v2+.articlechat.v1.ChatResponse.LatencyMsEntryR\tlatencyMs\x12\x16\n" +
+	"\x06cached\x18\t \x01(\bR\x06cached\x12\x19\n" +
+	"\bcost_usd\x18\n" +
+	" \x01(\x01R\acostUsd\x12\x1a\n" +
+	"\bdegraded\x18\v \x01(\bR\bdegraded\x12?\n" +
+	"\rclarification\x18\f \x03(\v2\x19.articlechat.v1.CandidateR\rclarification\x120\n" +
+	"\x05steps\x18\r \x03(\v2\x1a.articlechat.v1.StepResultR\x05steps\x1a<\n" +
+	"\x0eLatencyMsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x97\x01\n" +
+	"\x0fChatStreamEvent\x12/\n" +
+	"\x04plan\x18\x01 \x01(\v2\x19.articlechat.v1.QueryPlanH\x00R\x04plan\x12\x16\n" +
+	"\x05token\x18\x02 \x01(\tH\x00R\x05token\x122\n" +
+	"\x04done\x18\x03 \x01(\v2\x1c.articlechat.v1.ChatResponseH\x00R\x04doneB\a\n" +
+	"\x05event\"\xf6\x01\n" +
+	"\aArticle\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\aexcerpt\x18\x03 \x01(\tR\aexcerpt\x12\x18\n" +
+	"\asummary\x18\x04 \x01(\tR\asummary\x12\x1c\n" +
+	"\tsentiment\x18\x05 \x01(\tR\tsentiment\x12\x16\n" +
+	"\x06topics\x18\x06 \x03(\tR\x06topics\x12\x1a\n" +
+	"\bentities\x18\a \x03(\tR\bentities\x12=\n" +
+	"\fprocessed_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vprocessedAt\"%\n" +
+	"\x11AddArticleRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\"\xc7\x02\n" +
+	"\x13ListArticlesRequest\x12\x1c\n" +
+	"\tsentiment\x18\x01 \x01(\tR\tsentiment\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x16\n" +
+	"\x06entity\x18\x03 \x01(\tR\x06entity\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\x12C\n" +
+	"\x0fprocessed_after\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x0eprocessedAfter\x12E\n" +
+	"\x10processed_before\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0fprocessedBefore\x12\x16\n" +
+	"\x06cursor\x18\b \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\t \x01(\x05R\x05limit\"l\n" +
+	"\x14ListArticlesResponse\x123\n" +
+	"\barticles\x18\x01 \x03(\v2\x17.articlechat.v1.ArticleR\barticles\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\")\n" +
+	"\x13FindEntitiesRequest\x12\x12\n" +
+	"\x04urls\x18\x01 \x03(\tR\x04urls\";\n" +
+	"\vEntityCount\x12\x16\n" +
+	"\x06entity\x18\x01 \x01(\tR\x06entity\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"e\n" +
+	"\x14FindEntitiesResponse\x127\n" +
+	"\bentities\x18\x01 \x03(\v2\x1b.articlechat.v1.EntityCountR\bentities\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count2\xa5\x03\n" +
+	"\x12ArticleChatService\x12A\n" +
+	"\x04Chat\x12\x1b.articlechat.v1.ChatRequest\x1a\x1c.articlechat.v1.ChatResponse\x12L\n" +
+	"\n" +
+	"ChatStream\x12\x1b.articlechat.v1.ChatRequest\x1a\x1f.articlechat.v1.ChatStreamEvent0\x01\x12H\n" +
+	"\n" +
+	"AddArticle\x12!.articlechat.v1.AddArticleRequest\x1a\x17.articlechat.v1.Article\x12Y\n" +
+	"\fListArticles\x12#.articlechat.v1.ListArticlesRequest\x1a$.articlechat.v1.ListArticlesResponse\x12Y\n" +
+	"\fFindEntities\x12#.articlechat.v1.FindEntitiesRequest\x1a$.articlechat.v1.FindEntitiesResponseBIZGarticle-chat-system/internal/transport/grpc/articlechatv1;articlechatv1b\x06proto3"
+
+var (
+	file_articlechat_v1_article_chat_proto_rawDescOnce sync.Once
+	file_articlechat_v1_article_chat_proto_rawDescData []byte
+)
+
+func file_articlechat_v1_article_chat_proto_rawDescGZIP() []byte {
+	file_articlechat_v1_article_chat_proto_rawDescOnce.Do(func() {
+		file_articlechat_v1_article_chat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_articlechat_v1_article_chat_proto_rawDesc), len(file_articlechat_v1_article_chat_proto_rawDesc)))
+	})
+	return file_articlechat_v1_article_chat_proto_rawDescData
+}
+
+var file_articlechat_v1_article_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_articlechat_v1_article_chat_proto_goTypes = []any{
+	(*ChatRequest)(nil),           // 0: articlechat.v1.ChatRequest
+	(*QueryPlan)(nil),             // 1: articlechat.v1.QueryPlan
+	(*PlanStep)(nil),              // 2: articlechat.v1.PlanStep
+	(*StepResult)(nil),            // 3: articlechat.v1.StepResult
+	(*Candidate)(nil),             // 4: articlechat.v1.Candidate
+	(*Source)(nil),                // 5: articlechat.v1.Source
+	(*Usage)(nil),                 // 6: articlechat.v1.Usage
+	(*ChatResponse)(nil),          // 7: articlechat.v1.ChatResponse
+	(*ChatStreamEvent)(nil),       // 8: articlechat.v1.ChatStreamEvent
+	(*Article)(nil),               // 9: articlechat.v1.Article
+	(*AddArticleRequest)(nil),     // 10: articlechat.v1.AddArticleRequest
+	(*ListArticlesRequest)(nil),   // 11: articlechat.v1.ListArticlesRequest
+	(*ListArticlesResponse)(nil),  // 12: articlechat.v1.ListArticlesResponse
+	(*FindEntitiesRequest)(nil),   // 13: articlechat.v1.FindEntitiesRequest
+	(*EntityCount)(nil),           // 14: articlechat.v1.EntityCount
+	(*FindEntitiesResponse)(nil),  // 15: articlechat.v1.FindEntitiesResponse
+	nil,                           // 16: articlechat.v1.ChatResponse.LatencyMsEntry
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
+}
+var file_articlechat_v1_article_chat_proto_depIdxs = []int32{
+	2,  // 0: articlechat.v1.QueryPlan.steps:type_name -> articlechat.v1.PlanStep
+	5,  // 1: articlechat.v1.StepResult.sources:type_name -> articlechat.v1.Source
+	1,  // 2: articlechat.v1.ChatResponse.plan:type_name -> articlechat.v1.QueryPlan
+	5,  // 3: articlechat.v1.ChatResponse.sources:type_name -> articlechat.v1.Source
+	6,  // 4: articlechat.v1.ChatResponse.usage:type_name -> articlechat.v1.Usage
+	16, // 5: articlechat.v1.ChatResponse.latency_ms:type_name -> articlechat.v1.ChatResponse.LatencyMsEntry
+	4,  // 6: articlechat.v1.ChatResponse.clarification:type_name -> articlechat.v1.Candidate
+	3,  // 7: articlechat.v1.ChatResponse.steps:type_name -> articlechat.v1.StepResult
+	1,  // 8: articlechat.v1.ChatStreamEvent.plan:type_name -> articlechat.v1.QueryPlan
+	7,  // 9: articlechat.v1.ChatStreamEvent.done:type_name -> articlechat.v1.ChatResponse
+	17, // 10: articlechat.v1.Article.processed_at:type_name -> google.protobuf.Timestamp
+	17, // 11: articlechat.v1.ListArticlesRequest.processed_after:type_name -> google.protobuf.Timestamp
+	17, // 12: articlechat.v1.ListArticlesRequest.processed_before:type_name -> google.protobuf.Timestamp
+	9,  // 13: articlechat.v1.ListArticlesResponse.articles:type_name -> articlechat.v1.Article
+	14, // 14: articlechat.v1.FindEntitiesResponse.entities:type_name -> articlechat.v1.EntityCount
+	0,  // 15: articlechat.v1.ArticleChatService.Chat:input_type -> articlechat.v1.ChatRequest
+	0,  // 16: articlechat.v1.ArticleChatService.ChatStream:input_type -> articlechat.v1.ChatRequest
+	10, // 17: articlechat.v1.ArticleChatService.AddArticle:input_type -> articlechat.v1.AddArticleRequest
+	11, // 18: articlechat.v1.ArticleChatService.ListArticles:input_type -> articlechat.v1.ListArticlesRequest
+	13, // 19: articlechat.v1.ArticleChatService.FindEntities:input_type -> articlechat.v1.FindEntitiesRequest
+	7,  // 20: articlechat.v1.ArticleChatService.Chat:output_type -> articlechat.v1.ChatResponse
+	8,  // 21: articlechat.v1.ArticleChatService.ChatStream:output_type -> articlechat.v1.ChatStreamEvent
+	9,  // 22: articlechat.v1.ArticleChatService.AddArticle:output_type -> articlechat.v1.Article
+	12, // 23: articlechat.v1.ArticleChatService.ListArticles:output_type -> articlechat.v1.ListArticlesResponse
+	15, // 24: articlechat.v1.ArticleChatService.FindEntities:output_type -> articlechat.v1.FindEntitiesResponse
+	20, // [20:25] is the sub-list for method output_type
+	15, // [15:20] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_articlechat_v1_article_chat_proto_init() }
+func file_articlechat_v1_article_chat_proto_init() {
+	if File_articlechat_v1_article_chat_proto != nil {
+		return
+	}
+	file_articlechat_v1_article_chat_proto_msgTypes[8].OneofWrappers = []any{
+		(*ChatStreamEvent_Plan)(nil),
+		(*ChatStreamEvent_Token)(nil),
+		(*ChatStreamEvent_Done)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_articlechat_v1_article_chat_proto_rawDesc), len(file_articlechat_v1_article_chat_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_articlechat_v1_article_chat_proto_goTypes,
+		DependencyIndexes: file_articlechat_v1_article_chat_proto_depIdxs,
+		MessageInfos:      file_articlechat_v1_article_chat_proto_msgTypes,
+	}.Build()
+	File_articlechat_v1_article_chat_proto = out.File
+	file_articlechat_v1_article_chat_proto_goTypes = nil
+	file_articlechat_v1_article_chat_proto_depIdxs = nil
+}
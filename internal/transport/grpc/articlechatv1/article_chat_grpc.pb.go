@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: articlechat/v1/article_chat.proto
+
+package articlechatv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ArticleChatService_Chat_FullMethodName         = "/articlechat.v1.ArticleChatService/Chat"
+	ArticleChatService_ChatStream_FullMethodName   = "/articlechat.v1.ArticleChatService/ChatStream"
+	ArticleChatService_AddArticle_FullMethodName   = "/articlechat.v1.ArticleChatService/AddArticle"
+	ArticleChatService_ListArticles_FullMethodName = "/articlechat.v1.ArticleChatService/ListArticles"
+	ArticleChatService_FindEntities_FullMethodName = "/articlechat.v1.ArticleChatService/FindEntities"
+)
+
+// ArticleChatServiceClient is the client API for ArticleChatService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ArticleChatService mirrors the HTTP API for Go backends that want typed contracts.
+type ArticleChatServiceClient interface {
+	// Chat answers a query, optionally continuing a session.
+	Chat(ctx context.Context, in *ChatRequest, opts ...grpc.CallOption) (*ChatResponse, error)
+	// ChatStream answers a query as a stream: the plan, the answer tokens as they
+	// are generated, and finally the full response.
+	ChatStream(ctx context.Context, in *ChatRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatStreamEvent], error)
+	// AddArticle fetches, analyzes and stores a new article.
+	AddArticle(ctx context.Context, in *AddArticleRequest, opts ...grpc.CallOption) (*Article, error)
+	// ListArticles returns one page of the article catalog, newest first.
+	ListArticles(ctx context.Context, in *ListArticlesRequest, opts ...grpc.CallOption) (*ListArticlesResponse, error)
+	// FindEntities counts the most common entities across articles.
+	FindEntities(ctx context.Context, in *FindEntitiesRequest, opts ...grpc.CallOption) (*FindEntitiesResponse, error)
+}
+
+type articleChatServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArticleChatServiceClient(cc grpc.ClientConnInterface) ArticleChatServiceClient {
+	return &articleChatServiceClient{cc}
+}
+
+func (c *articleChatServiceClient) Chat(ctx context.Context, in *ChatRequest, opts ...grpc.CallOption) (*ChatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChatResponse)
+	err := c.cc.Invoke(ctx, ArticleChatService_Chat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleChatServiceClient) ChatStream(ctx context.Context, in *ChatRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatStreamEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ArticleChatService_ServiceDesc.Streams[0], ArticleChatService_ChatStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChatRequest, ChatStreamEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ArticleChatService_ChatStreamClient = grpc.ServerStreamingClient[ChatStreamEvent]
+
+func (c *articleChatServiceClient) AddArticle(ctx context.Context, in *AddArticleRequest, opts ...grpc.CallOption) (*Article, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Article)
+	err := c.cc.Invoke(ctx, ArticleChatService_AddArticle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleChatServiceClient) ListArticles(ctx context.Context, in *ListArticlesRequest, opts ...grpc.CallOption) (*ListArticlesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListArticlesResponse)
+	err := c.cc.Invoke(ctx, ArticleChatService_ListArticles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *articleChatServiceClient) FindEntities(ctx context.Context, in *FindEntitiesRequest, opts ...grpc.CallOption) (*FindEntitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindEntitiesResponse)
+	err := c.cc.Invoke(ctx, ArticleChatService_FindEntities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ArticleChatServiceServer is the server API for ArticleChatService service.
+// All implementations must embed UnimplementedArticleChatServiceServer
+// for forward compatibility.
+//
+// ArticleChatService mirrors the HTTP API for Go backends that want typed contracts.
+type ArticleChatServiceServer interface {
+	// Chat answers a query, optionally continuing a session.
+	Chat(context.Context, *ChatRequest) (*ChatResponse, error)
+	// ChatStream answers a query as a stream: the plan, the answer tokens as they
+	// are generated, and finally the full response.
+	ChatStream(*ChatRequest, grpc.ServerStreamingServer[ChatStreamEvent]) error
+	// AddArticle fetches, analyzes and stores a new article.
+	AddArticle(context.Context, *AddArticleRequest) (*Article, error)
+	// ListArticles returns one page of the article catalog, newest first.
+	ListArticles(context.Context, *ListArticlesRequest) (*ListArticlesResponse, error)
+	// FindEntities counts the most common entities across articles.
+	FindEntities(context.Context, *FindEntitiesRequest) (*FindEntitiesResponse, error)
+	mustEmbedUnimplementedArticleChatServiceServer()
+}
+
+// UnimplementedArticleChatServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedArticleChatServiceServer struct{}
+
+func (UnimplementedArticleChatServiceServer) Chat(context.Context, *ChatRequest) (*ChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Chat not implemented")
+}
+func (UnimplementedArticleChatServiceServer) ChatStream(*ChatRequest, grpc.ServerStreamingServer[ChatStreamEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method ChatStream not implemented")
+}
+func (UnimplementedArticleChatServiceServer) AddArticle(context.Context, *AddArticleRequest) (*Article, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddArticle not implemented")
+}
+func (UnimplementedArticleChatServiceServer) ListArticles(context.Context, *ListArticlesRequest) (*ListArticlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListArticles not implemented")
+}
+func (UnimplementedArticleChatServiceServer) FindEntities(context.Context, *FindEntitiesRequest) (*FindEntitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindEntities not implemented")
+}
+func (UnimplementedArticleChatServiceServer) mustEmbedUnimplementedArticleChatServiceServer() {}
+func (UnimplementedArticleChatServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeArticleChatServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArticleChatServiceServer will
+// result in compilation errors.
+type UnsafeArticleChatServiceServer interface {
+	mustEmbedUnimplementedArticleChatServiceServer()
+}
+
+func RegisterArticleChatServiceServer(s grpc.ServiceRegistrar, srv ArticleChatServiceServer) {
+	// If the following call pancis, it indicates UnimplementedArticleChatServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ArticleChatService_ServiceDesc, srv)
+}
+
+func _ArticleChatService_Chat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleChatServiceServer).Chat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleChatService_Chat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleChatServiceServer).Chat(ctx, req.(*ChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleChatService_ChatStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChatRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ArticleChatServiceServer).ChatStream(m, &grpc.GenericServerStream[ChatRequest, ChatStreamEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ArticleChatService_ChatStreamServer = grpc.ServerStreamingServer[ChatStreamEvent]
+
+func _ArticleChatService_AddArticle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddArticleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleChatServiceServer).AddArticle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleChatService_AddArticle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleChatServiceServer).AddArticle(ctx, req.(*AddArticleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleChatService_ListArticles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArticlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleChatServiceServer).ListArticles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleChatService_ListArticles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleChatServiceServer).ListArticles(ctx, req.(*ListArticlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ArticleChatService_FindEntities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindEntitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArticleChatServiceServer).FindEntities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ArticleChatService_FindEntities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArticleChatServiceServer).FindEntities(ctx, req.(*FindEntitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ArticleChatService_ServiceDesc is the grpc.ServiceDesc for ArticleChatService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ArticleChatService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "articlechat.v1.ArticleChatService",
+	HandlerType: (*ArticleChatServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Chat",
+			Handler:    _ArticleChatService_Chat_Handler,
+		},
+		{
+			MethodName: "AddArticle",
+			Handler:    _ArticleChatService_AddArticle_Handler,
+		},
+		{
+			MethodName: "ListArticles",
+			Handler:    _ArticleChatService_ListArticles_Handler,
+		},
+		{
+			MethodName: "FindEntities",
+			Handler:    _ArticleChatService_FindEntities_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ChatStream",
+			Handler:       _ArticleChatService_ChatStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "articlechat/v1/article_chat.proto",
+}
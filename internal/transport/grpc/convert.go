@@ -0,0 +1,113 @@
+package server
+
+import (
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/repository"
+	pb "article-chat-system/internal/transport/grpc/articlechatv1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toQueryPlan(plan *planner.QueryPlan) *pb.QueryPlan {
+	if plan == nil {
+		return nil
+	}
+	msg := &pb.QueryPlan{
+		Intent:     string(plan.Intent),
+		Targets:    plan.Targets,
+		Parameters: plan.Parameters,
+		Question:   plan.Question,
+		Since:      plan.Since,
+		Until:      plan.Until,
+	}
+	for _, step := range plan.Steps {
+		msg.Steps = append(msg.Steps, &pb.PlanStep{
+			Id:         step.ID,
+			Intent:     string(step.Intent),
+			Targets:    step.Targets,
+			Parameters: step.Parameters,
+			Since:      step.Since,
+			Until:      step.Until,
+			Inputs:     step.Inputs,
+		})
+	}
+	return msg
+}
+
+func toSources(sources []planner.Source) []*pb.Source {
+	var msgs []*pb.Source
+	for _, src := range sources {
+		msgs = append(msgs, &pb.Source{Url: src.URL, Title: src.Title, Score: src.Score})
+	}
+	return msgs
+}
+
+func toChatResponse(sessionID string, answer *chat.Answer) *pb.ChatResponse {
+	resp := &pb.ChatResponse{
+		Answer:        answer.Answer,
+		SessionId:     sessionID,
+		Plan:          toQueryPlan(answer.Plan),
+		PromptVersion: answer.PromptVersion,
+		Model:         answer.Model,
+		Usage: &pb.Usage{
+			PromptTokens:     int32(answer.Usage.PromptTokens),
+			CompletionTokens: int32(answer.Usage.CompletionTokens),
+			TotalTokens:      int32(answer.Usage.TotalTokens),
+		},
+		LatencyMs: answer.LatencyMs,
+		Cached:    answer.Cached,
+		CostUsd:   answer.CostUSD,
+		Degraded:  answer.Degraded,
+		Sources:   toSources(answer.Sources),
+	}
+	for _, c := range answer.Clarification {
+		resp.Clarification = append(resp.Clarification, &pb.Candidate{Url: c.URL, Title: c.Title, Score: c.Score})
+	}
+	for _, step := range answer.Steps {
+		resp.Steps = append(resp.Steps, &pb.StepResult{
+			Id:      step.ID,
+			Intent:  string(step.Intent),
+			Answer:  step.Answer,
+			Sources: toSources(step.Sources),
+			Error:   step.Error,
+		})
+	}
+	return resp
+}
+
+func toArticle(art *models.Article) *pb.Article {
+	msg := &pb.Article{
+		Url:       art.URL,
+		Title:     art.Title,
+		Excerpt:   art.Excerpt,
+		Summary:   art.Summary,
+		Sentiment: art.Sentiment,
+		Topics:    art.Topics,
+		Entities:  art.Entities,
+	}
+	if !art.ProcessedAt.IsZero() {
+		msg.ProcessedAt = timestamppb.New(art.ProcessedAt)
+	}
+	return msg
+}
+
+func toArticleFilter(req *pb.ListArticlesRequest) repository.ArticleFilter {
+	filter := repository.ArticleFilter{
+		Sentiment:    req.GetSentiment(),
+		Topic:        req.GetTopic(),
+		Entity:       req.GetEntity(),
+		SourceDomain: req.GetSource(),
+		Text:         req.GetText(),
+		Cursor:       req.GetCursor(),
+		Limit:        int(req.GetLimit()),
+	}
+	if req.GetProcessedAfter() != nil {
+		filter.ProcessedAfter = req.GetProcessedAfter().AsTime()
+	}
+	if req.GetProcessedBefore() != nil {
+		filter.ProcessedBefore = req.GetProcessedBefore().AsTime()
+	}
+	return filter
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"article-chat-system/internal/usage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The interceptors below play the role of chi's Logger and Recoverer
+// middleware on the HTTP transport.
+
+func (s *Server) logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.logger.Info("gRPC request", "method", info.FullMethod, "code", status.Code(err).String(), "duration", time.Since(start))
+	return resp, err
+}
+
+func (s *Server) logStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	s.logger.Info("gRPC stream", "method", info.FullMethod, "code", status.Code(err).String(), "duration", time.Since(start))
+	return err
+}
+
+func (s *Server) recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.Error("gRPC handler panicked", "method", info.FullMethod, "panic", p, "stack", string(debug.Stack()))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (s *Server) recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.logger.Error("gRPC handler panicked", "method", info.FullMethod, "panic", p, "stack", string(debug.Stack()))
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// identifyUnary and identifyStream authenticate the x-api-key (or
+// authorization bearer) metadata and attribute LLM usage to the key and, for
+// admin keys, to the user named in x-user-id, like the HTTP transport does
+// with headers.
+func (s *Server) identifyUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.withCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) identifyStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.withCaller(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+}
+
+// callerStream overrides the context of a server stream.
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) withCaller(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+	apiKey := first("x-api-key")
+	if token, ok := strings.CutPrefix(first("authorization"), "Bearer "); ok {
+		apiKey = strings.TrimSpace(token)
+	}
+	caller, err := s.apiKeys.Authenticate(apiKey, first("x-user-id"))
+	if errors.Is(err, usage.ErrUserNotAllowed) {
+		return nil, status.Error(codes.PermissionDenied, "x-user-id requires an admin API key")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return usage.WithCaller(ctx, caller), nil
+}
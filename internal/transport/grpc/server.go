@@ -0,0 +1,220 @@
+// Package server exposes the article chat API over gRPC. It shares the same
+// services as the HTTP transport; only the wire format differs.
+package server
+
+//go:generate protoc -I ../../../proto --go_out=../../.. --go_opt=module=article-chat-system --go-grpc_out=../../.. --go-grpc_opt=module=article-chat-system articlechat/v1/article_chat.proto
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/session"
+	pb "article-chat-system/internal/transport/grpc/articlechatv1"
+	"article-chat-system/internal/usage"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the ArticleChatService gRPC API.
+type Server struct {
+	pb.UnimplementedArticleChatServiceServer
+
+	logger           *slog.Logger
+	articleSvc       article.Service
+	chatSvc          chat.Service
+	processingFacade *processing.Facade
+	sessionSvc       session.Service
+	apiKeys          usage.APIKeys // Keys callers authenticate with
+}
+
+// NewServer is the constructor for the gRPC server.
+func NewServer(
+	logger *slog.Logger,
+	articleSvc article.Service,
+	chatSvc chat.Service,
+	processingFacade *processing.Facade,
+	sessionSvc session.Service,
+) *Server {
+	return &Server{
+		logger:           logger,
+		articleSvc:       articleSvc,
+		chatSvc:          chatSvc,
+		processingFacade: processingFacade,
+		sessionSvc:       sessionSvc,
+	}
+}
+
+// SetAPIKeys sets the keys callers authenticate with. Until it is called,
+// every call is anonymous or refused.
+func (s *Server) SetAPIKeys(keys usage.APIKeys) {
+	s.apiKeys = keys
+}
+
+// NewGRPCServer returns a grpc.Server with the service registered, traced with
+// OpenTelemetry like the HTTP server, and with logging and panic recovery.
+func (s *Server) NewGRPCServer() *grpc.Server {
+	gs := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(s.logUnary, s.recoverUnary, s.identifyUnary),
+		grpc.ChainStreamInterceptor(s.logStream, s.recoverStream, s.identifyStream),
+	)
+	pb.RegisterArticleChatServiceServer(gs, s)
+	return gs
+}
+
+// Chat answers a query, optionally continuing a session.
+func (s *Server) Chat(ctx context.Context, req *pb.ChatRequest) (*pb.ChatResponse, error) {
+	if req.GetQuery() == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	sessionID, history, err := s.sessionSvc.Resume(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, s.sessionError(req.GetSessionId(), err)
+	}
+
+	answer, err := s.chatSvc.Ask(ctx, chat.Request{Query: req.GetQuery(), History: history})
+	if err != nil {
+		s.logger.Error("Failed to answer the query", "error", err, "raw_query", req.GetQuery())
+		return nil, chatError(err)
+	}
+	s.recordTurn(ctx, sessionID, req.GetQuery(), answer)
+	return toChatResponse(sessionID, answer), nil
+}
+
+// ChatStream answers a query as a stream of plan, token and done events.
+func (s *Server) ChatStream(req *pb.ChatRequest, stream grpc.ServerStreamingServer[pb.ChatStreamEvent]) error {
+	if req.GetQuery() == "" {
+		return status.Error(codes.InvalidArgument, "query is required")
+	}
+	ctx := stream.Context()
+	sessionID, history, err := s.sessionSvc.Resume(ctx, req.GetSessionId())
+	if err != nil {
+		return s.sessionError(req.GetSessionId(), err)
+	}
+
+	streamed := false
+	ctx = llm.WithStreamHandler(ctx, func(chunk string) error {
+		streamed = true
+		return stream.Send(&pb.ChatStreamEvent{Event: &pb.ChatStreamEvent_Token{Token: chunk}})
+	})
+
+	answer, err := s.chatSvc.Ask(ctx, chat.Request{
+		Query:   req.GetQuery(),
+		History: history,
+		OnPlan: func(plan *planner.QueryPlan) error {
+			return stream.Send(&pb.ChatStreamEvent{Event: &pb.ChatStreamEvent_Plan{Plan: toQueryPlan(plan)}})
+		},
+	})
+	if err != nil {
+		s.logger.Error("Failed to answer the query", "error", err, "raw_query", req.GetQuery())
+		return chatError(err)
+	}
+
+	// Cached answers and strategies that answer without an LLM call never
+	// stream, so deliver their answer as a single token.
+	if !streamed {
+		if err := stream.Send(&pb.ChatStreamEvent{Event: &pb.ChatStreamEvent_Token{Token: answer.Answer}}); err != nil {
+			return err
+		}
+	}
+
+	s.recordTurn(stream.Context(), sessionID, req.GetQuery(), answer)
+	return stream.Send(&pb.ChatStreamEvent{Event: &pb.ChatStreamEvent_Done{Done: toChatResponse(sessionID, answer)}})
+}
+
+// AddArticle fetches, analyzes and stores a new article.
+func (s *Server) AddArticle(ctx context.Context, req *pb.AddArticleRequest) (*pb.Article, error) {
+	if req.GetUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "url is required")
+	}
+	art, err := s.processingFacade.AddNewArticle(ctx, req.GetUrl())
+	if err != nil {
+		if errors.Is(err, processing.ErrArticleExists) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if errors.Is(err, usage.ErrBudgetExceeded) {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		s.logger.Error("Failed to process article", "error", err, "url", req.GetUrl())
+		return nil, status.Errorf(codes.Internal, "failed to process article: %v", err)
+	}
+	s.logger.Info("Successfully processed new article", "url", req.GetUrl(), "title", art.Title)
+	return toArticle(art), nil
+}
+
+// ListArticles returns one page of the article catalog.
+func (s *Server) ListArticles(ctx context.Context, req *pb.ListArticlesRequest) (*pb.ListArticlesResponse, error) {
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+	page, err := s.articleSvc.ListArticles(ctx, toArticleFilter(req))
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		s.logger.Error("Failed to list articles", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to list articles: %v", err)
+	}
+
+	resp := &pb.ListArticlesResponse{NextCursor: page.NextCursor}
+	for _, art := range page.Articles {
+		resp.Articles = append(resp.Articles, toArticle(art))
+	}
+	return resp, nil
+}
+
+// FindEntities counts the most common entities across articles.
+func (s *Server) FindEntities(ctx context.Context, req *pb.FindEntitiesRequest) (*pb.FindEntitiesResponse, error) {
+	entities, err := s.articleSvc.FindCommonEntities(ctx, req.GetUrls())
+	if err != nil {
+		s.logger.Error("Failed to find common entities", "error", err, "urls", req.GetUrls())
+		return nil, status.Errorf(codes.Internal, "failed to find common entities: %v", err)
+	}
+
+	resp := &pb.FindEntitiesResponse{Count: int32(len(entities))}
+	for _, entity := range entities {
+		resp.Entities = append(resp.Entities, &pb.EntityCount{Entity: entity.Entity, Count: int32(entity.Count)})
+	}
+	return resp, nil
+}
+
+// sessionError maps a session lookup failure onto a gRPC status.
+func (s *Server) sessionError(sessionID string, err error) error {
+	if errors.Is(err, session.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "session not found: %s", sessionID)
+	}
+	s.logger.Error("Failed to load session", "error", err, "session_id", sessionID)
+	return status.Errorf(codes.Internal, "failed to load session: %v", err)
+}
+
+// chatError maps a failed chat query onto a gRPC status.
+func chatError(err error) error {
+	if errors.Is(err, usage.ErrBudgetExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Errorf(codes.Internal, "failed to answer the query: %v", err)
+}
+
+// recordTurn appends the exchange to the session. Failing to persist history
+// must not fail an answer the caller already has, so errors are only logged.
+func (s *Server) recordTurn(ctx context.Context, sessionID, query string, answer *chat.Answer) {
+	turn := &models.Turn{Query: query, Answer: answer.Answer}
+	if answer.Plan != nil {
+		turn.Intent = string(answer.Plan.Intent)
+		turn.Targets = answer.Plan.Targets
+	}
+	if err := s.sessionSvc.RecordTurn(ctx, sessionID, turn); err != nil {
+		s.logger.Warn("Failed to record session turn", "error", err, "session_id", sessionID)
+	}
+}
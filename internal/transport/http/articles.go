@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/usage"
+	"article-chat-system/internal/vector"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// articleURLParam extracts the article URL from a /articles/{url} route. The
+// URL may be sent path-escaped (recommended) or verbatim.
+func articleURLParam(r *http.Request) (string, error) {
+	raw := chi.URLParam(r, "*")
+	articleURL, err := url.PathUnescape(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid article URL %q: %w", raw, err)
+	}
+	if articleURL == "" {
+		return "", fmt.Errorf("article URL is required")
+	}
+	return articleURL, nil
+}
+
+// parseArticleFilter builds a repository filter from the query string of GET /articles.
+func parseArticleFilter(q url.Values) (repository.ArticleFilter, error) {
+	filter := repository.ArticleFilter{
+		Sentiment:    q.Get("sentiment"),
+		Topic:        q.Get("topic"),
+		Entity:       q.Get("entity"),
+		SourceDomain: q.Get("source"),
+		Text:         q.Get("q"),
+		Cursor:       q.Get("cursor"),
+	}
+
+	if filter.Cursor != "" {
+		if _, _, err := repository.DecodeCursor(filter.Cursor); err != nil {
+			return filter, fmt.Errorf("invalid 'cursor' parameter")
+		}
+	}
+
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return filter, fmt.Errorf("invalid 'limit' parameter")
+		}
+		filter.Limit = n
+	}
+
+	var err error
+	if filter.ProcessedAfter, err = parseTimeParam(q, "processed_after"); err != nil {
+		return filter, err
+	}
+	if filter.ProcessedBefore, err = parseTimeParam(q, "processed_before"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseTimeParam accepts either an RFC 3339 timestamp or a plain date.
+func parseTimeParam(q url.Values, name string) (time.Time, error) {
+	raw := q.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid '%s' parameter: expected RFC 3339 timestamp or YYYY-MM-DD date", name)
+}
+
+func (h *Handler) handleListArticles(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseArticleFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.articleSvc.ListArticles(r.Context(), filter)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to list articles", "error", err, "filter", filter)
+		http.Error(w, "Failed to list articles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *Handler) handleGetArticle(w http.ResponseWriter, r *http.Request) {
+	articleURL, err := articleURLParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	art, ok := h.articleSvc.GetArticle(r.Context(), articleURL)
+	if !ok {
+		http.Error(w, "Article not found: "+articleURL, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(art)
+}
+
+func (h *Handler) handleDeleteArticle(w http.ResponseWriter, r *http.Request) {
+	articleURL, err := articleURLParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.processingFacade.DeleteArticle(r.Context(), articleURL); err != nil {
+		if errors.Is(err, processing.ErrArticleNotFound) {
+			http.Error(w, "Article not found: "+articleURL, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, vector.ErrUnavailable) {
+			http.Error(w, "Vector store unavailable, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		h.logger.Error("Failed to delete article", "error", err, "url", articleURL)
+		http.Error(w, "Failed to delete article: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Deleted article", "url", articleURL)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleArticleAction dispatches POST /articles/{url}/{action}. The article
+// URL itself contains slashes, so the action is taken from the path suffix.
+func (h *Handler) handleArticleAction(w http.ResponseWriter, r *http.Request) {
+	articleURL, err := articleURLParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if target, ok := strings.CutSuffix(articleURL, "/reanalyze"); ok && target != "" {
+		h.handleReanalyzeArticle(w, r, target)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (h *Handler) handleReanalyzeArticle(w http.ResponseWriter, r *http.Request, articleURL string) {
+	updated, err := h.processingFacade.ReanalyzeArticle(r.Context(), articleURL)
+	if err != nil {
+		if errors.Is(err, processing.ErrArticleNotFound) {
+			http.Error(w, "Article not found: "+articleURL, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, vector.ErrUnavailable) {
+			http.Error(w, "Vector store unavailable, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		if errors.Is(err, usage.ErrBudgetExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		h.logger.Error("Failed to re-analyze article", "error", err, "url", articleURL)
+		http.Error(w, "Failed to re-analyze article: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("Re-analyzed article", "url", articleURL, "title", updated.Title)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
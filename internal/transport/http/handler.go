@@ -1,77 +1,118 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strings"
 
 	"article-chat-system/internal/article"
-	"article-chat-system/internal/cache"
-
-	// "article-chat-system/internal/models"
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/health"
+	"article-chat-system/internal/jobs"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/processing"
-	"article-chat-system/internal/prompts"
 	"article-chat-system/internal/repository"
-	"article-chat-system/internal/strategies"
-	"article-chat-system/internal/vector"
+	"article-chat-system/internal/session"
+	"article-chat-system/internal/usage"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Handler now depends on the interfaces, not the concrete structs.
 type Handler struct {
-	logger           *slog.Logger         // Structured logger
-	articleSvc       article.Service      // Use interface
-	plannerSvc       planner.Service      // Use interface
-	strategyExecutor *strategies.Executor // Executor can be concrete if it has no interface, or use its interface
-	promptFactory    *prompts.Factory     // Factory can be concrete
-	processingFacade *processing.Facade   // Facade can be concrete
-	vectorSvc        vector.Service       // Vector service for semantic search
-	cacheSvc         *cache.Service       // Cache service for API-level caching
+	logger           *slog.Logger       // Structured logger
+	articleSvc       article.Service    // Use interface
+	chatSvc          chat.Service       // Plans and executes chat queries
+	processingFacade *processing.Facade // Facade can be concrete
+	sessionSvc       session.Service    // Conversation sessions for multi-turn chat
+	jobQueue         *jobs.Queue        // Durable queue for asynchronous ingestion
+	healthSvc        *health.Service    // Dependency probes for /readyz
+	usageSvc         usage.Service      // LLM usage accounting and budgets
+	apiKeys          usage.APIKeys      // Keys callers authenticate with
 }
 
 // NewHandler now accepts the interfaces as arguments.
 func NewHandler(
 	logger *slog.Logger,
 	articleSvc article.Service,
-	plannerSvc planner.Service,
-	strategyExecutor *strategies.Executor,
-	promptFactory *prompts.Factory,
+	chatSvc chat.Service,
 	processingFacade *processing.Facade,
-	vectorSvc vector.Service,
-	cacheSvc *cache.Service,
+	sessionSvc session.Service,
+	jobQueue *jobs.Queue,
+	healthSvc *health.Service,
+	usageSvc usage.Service,
 ) *Handler {
 	return &Handler{
 		logger:           logger,
 		articleSvc:       articleSvc,
-		plannerSvc:       plannerSvc,
-		strategyExecutor: strategyExecutor,
-		promptFactory:    promptFactory,
+		chatSvc:          chatSvc,
 		processingFacade: processingFacade,
-		vectorSvc:        vectorSvc,
-		cacheSvc:         cacheSvc,
+		sessionSvc:       sessionSvc,
+		jobQueue:         jobQueue,
+		healthSvc:        healthSvc,
+		usageSvc:         usageSvc,
 	}
 }
 
+// SetAPIKeys sets the keys callers authenticate with. Until it is called,
+// every request is anonymous or refused.
+func (h *Handler) SetAPIKeys(keys usage.APIKeys) {
+	h.apiKeys = keys
+}
+
 func (h *Handler) Routes() http.Handler {
 	r := chi.NewRouter()
-	r.Use(middleware.Logger, middleware.Recoverer)
+	r.Use(middleware.Logger, middleware.Recoverer, h.identifyCaller)
+	r.Get("/healthz", h.handleHealthz)
+	r.Get("/readyz", h.handleReadyz)
+	r.Handle("/metrics", promhttp.Handler())
 	r.Post("/chat", h.handleChat)
+	r.Post("/chat/stream", h.handleChatStream)
 	r.Post("/articles", h.handleAddArticle)
+	r.Post("/articles/batch", h.handleAddArticlesBatch)
+	r.Get("/articles", h.handleListArticles)
+	r.Get("/articles/*", h.handleGetArticle)
+	r.Delete("/articles/*", h.handleDeleteArticle)
+	r.Post("/articles/*", h.handleArticleAction)
 	r.Post("/entities", h.handleFindEntities)
+	r.Get("/jobs/{id}", h.handleGetJob)
+	r.Get("/sessions", h.handleListSessions)
+	r.Get("/sessions/{id}", h.handleGetSession)
+	r.Delete("/sessions/{id}", h.handleDeleteSession)
+	r.Get("/usage", h.handleUsage)
+	r.Get("/v1/models", h.handleListModels)
+	r.Post("/v1/chat/completions", h.handleChatCompletions)
 	return r
 }
 
 type ChatRequest struct {
-	Query   string `json:"query"`
-	Message string `json:"message"`
+	Query     string `json:"query"`
+	Message   string `json:"message"`
+	SessionID string `json:"session_id"` // Optional; a new session is started when empty
 }
 
+// ChatResponse is an answer with the details of how it was produced, tagged
+// with the session it belongs to. Answer keeps its original shape so existing
+// clients can ignore the other fields.
 type ChatResponse struct {
-	Answer string `json:"answer"`
+	*chat.Answer
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// StreamTokenEvent is the payload of a "token" event on /chat/stream.
+type StreamTokenEvent struct {
+	Text string `json:"text"`
+}
+
+// StreamErrorEvent is the payload of an "error" event on /chat/stream.
+type StreamErrorEvent struct {
+	Error string `json:"error"`
 }
 
 type AddArticleRequest struct {
@@ -87,58 +128,125 @@ type FindEntitiesResponse struct {
 	Count    int                      `json:"count"`
 }
 
-func (h *Handler) handleChat(w http.ResponseWriter, r *http.Request) {
+// decodeChatRequest reads a ChatRequest and normalizes its query, writing a
+// 400 response and returning false if it is missing.
+func decodeChatRequest(w http.ResponseWriter, r *http.Request) (*ChatRequest, bool) {
 	var req ChatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	// Support both "query" and "message" fields for backward compatibility
-	query := req.Query
-	if query == "" {
-		query = req.Message
+	if req.Query == "" {
+		req.Query = req.Message
 	}
-	if query == "" {
+	if req.Query == "" {
 		http.Error(w, "Either 'query' or 'message' field is required", http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+// writeSessionError maps a session lookup failure onto an HTTP status.
+func (h *Handler) writeSessionError(w http.ResponseWriter, sessionID string, err error) {
+	if errors.Is(err, session.ErrNotFound) {
+		http.Error(w, "Session not found: "+sessionID, http.StatusNotFound)
 		return
 	}
+	h.logger.Error("Failed to load session", "error", err, "session_id", sessionID)
+	http.Error(w, "Failed to load session: "+err.Error(), http.StatusInternalServerError)
+}
 
-	// --- NEW CACHING LOGIC ---
-	// 1. Generate the cache key from the raw query string immediately.
-	cacheKey := h.cacheSvc.GenerateCacheKey(query)
+// recordTurn appends the exchange to the session. Failing to persist history
+// must not fail an answer the user already has, so errors are only logged.
+func (h *Handler) recordTurn(ctx context.Context, sessionID, query, answer string, plan *planner.QueryPlan) {
+	turn := &models.Turn{Query: query, Answer: answer}
+	if plan != nil {
+		turn.Intent = string(plan.Intent)
+		turn.Targets = plan.Targets
+	}
+	if err := h.sessionSvc.RecordTurn(ctx, sessionID, turn); err != nil {
+		h.logger.Warn("Failed to record session turn", "error", err, "session_id", sessionID)
+	}
+}
 
-	// 2. Check the cache BEFORE any LLM calls.
-	if cachedAnswer, found := h.cacheSvc.Get(cacheKey); found {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(ChatResponse{Answer: "🤖 (from cache)\n\n" + cachedAnswer})
-		return // Return immediately on a cache hit.
+func (h *Handler) handleChat(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeChatRequest(w, r)
+	if !ok {
+		return
 	}
-	// --- END NEW CACHING LOGIC ---
+	query := req.Query
 
-	// --- CACHE MISS: Proceed with the normal flow ---
-	// 3. Create a plan (First LLM call).
-	plan, err := h.plannerSvc.CreatePlan(r.Context(), query)
+	sessionID, history, err := h.sessionSvc.Resume(r.Context(), req.SessionID)
 	if err != nil {
-		h.logger.Error("Failed to create a query plan", "error", err, "raw_query", query)
-		http.Error(w, "Failed to create a query plan: "+err.Error(), http.StatusInternalServerError)
+		h.writeSessionError(w, req.SessionID, err)
 		return
 	}
 
-	// 4. Execute the plan (Potential second LLM call).
-	answer, err := h.strategyExecutor.ExecutePlan(r.Context(), plan, h.articleSvc, h.promptFactory, h.vectorSvc)
+	answer, err := h.chatSvc.Ask(r.Context(), chat.Request{Query: query, History: history})
 	if err != nil {
-		h.logger.Error("Failed to execute the plan", "error", err, "plan", plan)
-		http.Error(w, "Failed to execute the plan: "+err.Error(), http.StatusInternalServerError)
+		h.logger.Error("Failed to answer the query", "error", err, "raw_query", query)
+		http.Error(w, "Failed to answer the query: "+err.Error(), chatErrorStatus(err))
 		return
 	}
+	h.recordTurn(r.Context(), sessionID, query, answer.Answer, answer.Plan)
 
-	// 5. Store the newly generated answer in the cache.
-	h.cacheSvc.Set(cacheKey, answer)
-
-	// 6. Return the response to the user.
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ChatResponse{Answer: answer})
+	json.NewEncoder(w).Encode(ChatResponse{Answer: answer, SessionID: sessionID})
+}
+
+// handleChatStream answers a chat query over Server-Sent Events. It emits a
+// "plan" event once the query is planned, "token" events as the answer is
+// generated, and a final "done" event carrying the full ChatResponse.
+func (h *Handler) handleChatStream(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeChatRequest(w, r)
+	if !ok {
+		return
+	}
+	query := req.Query
+
+	sessionID, history, err := h.sessionSvc.Resume(r.Context(), req.SessionID)
+	if err != nil {
+		h.writeSessionError(w, req.SessionID, err)
+		return
+	}
+
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		h.logger.Error("Streaming not supported", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Forward every partial token from the synthesis LLM call to the client.
+	streamed := false
+	ctx := llm.WithStreamHandler(r.Context(), func(chunk string) error {
+		streamed = true
+		return sse.Send("token", StreamTokenEvent{Text: chunk})
+	})
+
+	answer, err := h.chatSvc.Ask(ctx, chat.Request{
+		Query:   query,
+		History: history,
+		OnPlan: func(plan *planner.QueryPlan) error {
+			return sse.Send("plan", plan)
+		},
+	})
+	if err != nil {
+		h.logger.Error("Failed to answer the query", "error", err, "raw_query", query)
+		sse.Send("error", StreamErrorEvent{Error: "Failed to answer the query: " + err.Error()})
+		return
+	}
+
+	// Cached answers and strategies that answer without an LLM call (e.g.
+	// stored summaries) never stream, so deliver their answer as a single token.
+	if !streamed {
+		sse.Send("token", StreamTokenEvent{Text: answer.Answer})
+	}
+
+	h.recordTurn(r.Context(), sessionID, query, answer.Answer, answer.Plan)
+	sse.Send("done", ChatResponse{Answer: answer, SessionID: sessionID})
 }
 
 func (h *Handler) handleAddArticle(w http.ResponseWriter, r *http.Request) {
@@ -150,11 +258,15 @@ func (h *Handler) handleAddArticle(w http.ResponseWriter, r *http.Request) {
 	}
 	newArticle, err := h.processingFacade.AddNewArticle(r.Context(), req.URL)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
+		if errors.Is(err, processing.ErrArticleExists) {
 			h.logger.Warn("Article already exists", "url", req.URL)
 			http.Error(w, err.Error(), http.StatusConflict)
 			return
 		}
+		if errors.Is(err, usage.ErrBudgetExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
 		h.logger.Error("Failed to process article", "error", err, "url", req.URL)
 		http.Error(w, "Failed to process article: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"article-chat-system/internal/health"
+)
+
+// handleHealthz is the liveness probe: the process is up and serving HTTP.
+func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": string(health.StatusOK)})
+}
+
+// handleReadyz is the readiness probe. A degraded service still accepts
+// traffic; only a failed critical dependency makes it unready.
+func (h *Handler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := h.healthSvc.Readiness(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status == health.StatusUnavailable {
+		h.logger.Warn("Readiness check failed", "components", report.Components)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
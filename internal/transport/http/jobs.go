@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"article-chat-system/internal/jobs"
+	"article-chat-system/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxBatchURLs caps how many URLs a single batch request may enqueue.
+const maxBatchURLs = 500
+
+type AddArticlesBatchRequest struct {
+	URLs []string `json:"urls"`
+}
+
+type AddArticlesBatchResponse struct {
+	JobID  string           `json:"job_id"`
+	Status models.JobStatus `json:"status"`
+	Count  int              `json:"count"`
+}
+
+func (h *Handler) handleAddArticlesBatch(w http.ResponseWriter, r *http.Request) {
+	var req AddArticlesBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Invalid request body for batch add", "error", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.URLs) == 0 {
+		http.Error(w, "The 'urls' field must contain at least one URL", http.StatusBadRequest)
+		return
+	}
+	if len(req.URLs) > maxBatchURLs {
+		http.Error(w, "Too many URLs in one batch", http.StatusBadRequest)
+		return
+	}
+
+	// Each article is checked again when a worker ingests it; this only
+	// spares a caller whose budget is already spent from queueing a batch
+	// that would fail item by item.
+	if h.usageSvc != nil {
+		if err := h.usageSvc.Check(r.Context()); err != nil {
+			http.Error(w, err.Error(), chatErrorStatus(err))
+			return
+		}
+	}
+
+	job, err := h.jobQueue.Enqueue(r.Context(), req.URLs)
+	if err != nil {
+		h.logger.Error("Failed to enqueue ingestion job", "error", err, "count", len(req.URLs))
+		http.Error(w, "Failed to enqueue ingestion job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(AddArticlesBatchResponse{
+		JobID:  job.ID,
+		Status: job.Status,
+		Count:  len(job.Items),
+	})
+}
+
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	job, err := h.jobQueue.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobNotFound) {
+			http.Error(w, "Job not found: "+id, http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to load job", "error", err, "job_id", id)
+		http.Error(w, "Failed to load job: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
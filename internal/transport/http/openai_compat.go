@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/usage"
+
+	"github.com/google/uuid"
+)
+
+// completionModelBase is the model ID under which /v1/chat/completions lets
+// the planner choose the intent. "<base>/<intent>" forces an intent, and an
+// "@<prompt version>" suffix pins the prompt set, e.g. "article-chat/summarize@v1".
+const completionModelBase = "article-chat"
+
+// CompletionContent is message content, sent either as a plain string or as a
+// list of typed parts of which only text parts are kept.
+type CompletionContent string
+
+func (c *CompletionContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = CompletionContent(text)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or a list of parts: %w", err)
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	*c = CompletionContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+type CompletionMessage struct {
+	Role    string            `json:"role,omitempty"`
+	Content CompletionContent `json:"content,omitempty"`
+}
+
+type CompletionRequest struct {
+	Model         string              `json:"model"`
+	Messages      []CompletionMessage `json:"messages"`
+	Stream        bool                `json:"stream"`
+	User          string              `json:"user,omitempty"` // End user the usage is attributed to
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+}
+
+type CompletionChoice struct {
+	Index        int                `json:"index"`
+	Message      *CompletionMessage `json:"message,omitempty"`
+	Delta        *CompletionMessage `json:"delta,omitempty"`
+	FinishReason *string            `json:"finish_reason"`
+}
+
+// CompletionResponse is both a "chat.completion" and, when streaming, a
+// "chat.completion.chunk".
+type CompletionResponse struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   *llm.Usage         `json:"usage,omitempty"`
+}
+
+type ModelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type ModelList struct {
+	Object string      `json:"object"`
+	Data   []ModelInfo `json:"data"`
+}
+
+// completionError is the OpenAI error envelope.
+type completionError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code,omitempty"`
+	} `json:"error"`
+}
+
+func writeCompletionError(w http.ResponseWriter, status int, errType, code, message string) {
+	var body completionError
+	body.Error.Message = message
+	body.Error.Type = errType
+	body.Error.Code = code
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// handleListModels exposes every intent, under the prompt version in use, as
+// a selectable model.
+func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
+	version := h.chatSvc.PromptVersion()
+	ids := []string{completionModelBase + "@" + version}
+	for _, intent := range h.chatSvc.Intents() {
+		ids = append(ids, fmt.Sprintf("%s/%s@%s", completionModelBase, strings.ToLower(string(intent)), version))
+	}
+
+	list := ModelList{Object: "list", Data: make([]ModelInfo, 0, len(ids))}
+	for _, id := range ids {
+		list.Data = append(list.Data, ModelInfo{ID: id, Object: "model", OwnedBy: "article-chat-system"})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// parseCompletionModel resolves a model ID to the intent it forces, if any.
+// An empty ID selects the default model.
+func (h *Handler) parseCompletionModel(id string) (planner.QueryIntent, error) {
+	if id == "" {
+		return "", nil
+	}
+	name, version, hasVersion := strings.Cut(id, "@")
+	if hasVersion && version != h.chatSvc.PromptVersion() {
+		return "", fmt.Errorf("prompt version %q is not loaded; this server uses %q", version, h.chatSvc.PromptVersion())
+	}
+	if name == completionModelBase {
+		return "", nil
+	}
+	intentName, ok := strings.CutPrefix(name, completionModelBase+"/")
+	if !ok {
+		return "", fmt.Errorf("the model %q does not exist", id)
+	}
+	for _, intent := range h.chatSvc.Intents() {
+		if strings.EqualFold(string(intent), intentName) {
+			return intent, nil
+		}
+	}
+	return "", fmt.Errorf("the model %q does not exist", id)
+}
+
+// completionQuery takes the last message as the query and pairs the earlier
+// user and assistant messages into conversation turns. System messages are
+// ignored; the planner has its own instructions.
+func completionQuery(messages []CompletionMessage) (string, []models.Turn, error) {
+	if len(messages) == 0 || messages[len(messages)-1].Role != "user" {
+		return "", nil, fmt.Errorf("the last message must have role 'user'")
+	}
+	query := strings.TrimSpace(string(messages[len(messages)-1].Content))
+	if query == "" {
+		return "", nil, fmt.Errorf("the last user message is empty")
+	}
+
+	var history []models.Turn
+	for _, msg := range messages[:len(messages)-1] {
+		switch msg.Role {
+		case "user":
+			history = append(history, models.Turn{Query: string(msg.Content)})
+		case "assistant":
+			if len(history) == 0 || history[len(history)-1].Answer != "" {
+				history = append(history, models.Turn{})
+			}
+			history[len(history)-1].Answer = string(msg.Content)
+		}
+	}
+	return query, history, nil
+}
+
+// handleChatCompletions serves the OpenAI chat completions protocol on top of
+// the chat service, so OpenAI-compatible tools can query the corpus.
+func (h *Handler) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req CompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", "", "Invalid request body: "+err.Error())
+		return
+	}
+	intent, err := h.parseCompletionModel(req.Model)
+	if err != nil {
+		writeCompletionError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", err.Error())
+		return
+	}
+	query, history, err := completionQuery(req.Messages)
+	if err != nil {
+		writeCompletionError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+
+	model := req.Model
+	if model == "" {
+		model = completionModelBase + "@" + h.chatSvc.PromptVersion()
+	}
+	chatReq := chat.Request{Query: query, History: history, Intent: intent}
+
+	// OpenAI clients name their end user in the request body. Like
+	// X-User-ID, it is only trusted from an admin key. Other callers are
+	// charged as themselves: many clients send it unasked, so it is ignored
+	// rather than refused.
+	ctx := r.Context()
+	if caller := usage.CallerFrom(ctx); req.User != "" && caller.Admin {
+		caller.User = req.User
+		ctx = usage.WithCaller(ctx, caller)
+	}
+
+	if req.Stream {
+		includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+		h.streamChatCompletion(w, r.WithContext(ctx), model, chatReq, includeUsage)
+		return
+	}
+
+	answer, err := h.chatSvc.Ask(ctx, chatReq)
+	if err != nil {
+		h.logger.Error("Failed to answer the completion request", "error", err, "raw_query", query)
+		if status := chatErrorStatus(err); status == http.StatusTooManyRequests {
+			writeCompletionError(w, status, "insufficient_quota", "budget_exceeded", err.Error())
+			return
+		}
+		writeCompletionError(w, http.StatusInternalServerError, "server_error", "", "Failed to answer the query: "+err.Error())
+		return
+	}
+
+	stop := "stop"
+	tokens := answer.Usage
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CompletionResponse{
+		ID:      "chatcmpl-" + uuid.NewString(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []CompletionChoice{{
+			Message:      &CompletionMessage{Role: "assistant", Content: CompletionContent(answer.Answer)},
+			FinishReason: &stop,
+		}},
+		Usage: &tokens,
+	})
+}
+
+// streamChatCompletion answers with "chat.completion.chunk" events followed by
+// the "[DONE]" sentinel.
+func (h *Handler) streamChatCompletion(w http.ResponseWriter, r *http.Request, model string, chatReq chat.Request, includeUsage bool) {
+	sse, err := newSSEWriter(w)
+	if err != nil {
+		h.logger.Error("Streaming not supported", "error", err)
+		writeCompletionError(w, http.StatusInternalServerError, "server_error", "", err.Error())
+		return
+	}
+
+	id := "chatcmpl-" + uuid.NewString()
+	created := time.Now().Unix()
+	chunk := func(delta *CompletionMessage, finishReason *string) CompletionResponse {
+		return CompletionResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []CompletionChoice{{Delta: delta, FinishReason: finishReason}},
+		}
+	}
+
+	if err := sse.SendData(chunk(&CompletionMessage{Role: "assistant"}, nil)); err != nil {
+		h.logger.Warn("Client went away before the stream started", "error", err)
+		return
+	}
+
+	streamed := false
+	ctx := llm.WithStreamHandler(r.Context(), func(text string) error {
+		streamed = true
+		return sse.SendData(chunk(&CompletionMessage{Content: CompletionContent(text)}, nil))
+	})
+
+	answer, err := h.chatSvc.Ask(ctx, chatReq)
+	if err != nil {
+		h.logger.Error("Failed to answer the completion request", "error", err, "raw_query", chatReq.Query)
+		var body completionError
+		body.Error.Message = "Failed to answer the query: " + err.Error()
+		body.Error.Type = "server_error"
+		sse.SendData(body)
+		sse.SendDone()
+		return
+	}
+
+	if !streamed {
+		sse.SendData(chunk(&CompletionMessage{Content: CompletionContent(answer.Answer)}, nil))
+	}
+	stop := "stop"
+	sse.SendData(chunk(&CompletionMessage{}, &stop))
+	if includeUsage {
+		tokens := answer.Usage
+		final := chunk(nil, nil)
+		final.Choices = []CompletionChoice{}
+		final.Usage = &tokens
+		sse.SendData(final)
+	}
+	sse.SendDone()
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/session"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultSessionListLimit = 20
+	maxSessionListLimit     = 100
+)
+
+type ListSessionsResponse struct {
+	Sessions []*models.Session `json:"sessions"`
+	Count    int               `json:"count"`
+}
+
+func (h *Handler) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	limit := defaultSessionListLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid 'limit' parameter", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSessionListLimit)
+	}
+
+	sessions, err := h.sessionSvc.List(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", "error", err)
+		http.Error(w, "Failed to list sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ListSessionsResponse{
+		Sessions: sessions,
+		Count:    len(sessions),
+	})
+}
+
+func (h *Handler) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	sess, err := h.sessionSvc.Get(r.Context(), id)
+	if err != nil {
+		h.writeSessionError(w, id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+func (h *Handler) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.sessionSvc.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			http.Error(w, "Session not found: "+id, http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Failed to delete session", "error", err, "session_id", id)
+		http.Error(w, "Failed to delete session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("Deleted session", "session_id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// sseWriter serializes Server-Sent Events onto an HTTP response.
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEWriter prepares the response for event streaming. It fails if the
+// underlying writer cannot flush partial output.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("streaming is not supported by the response writer")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &sseWriter{w: w, flusher: flusher}, nil
+}
+
+// Send writes a single named event with a JSON-encoded payload and flushes it.
+func (s *sseWriter) Send(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event, err)
+	}
+	return s.write(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+}
+
+// SendData writes an unnamed event with a JSON-encoded payload, as expected by
+// OpenAI-style streaming clients.
+func (s *sseWriter) SendData(data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return s.write(fmt.Sprintf("data: %s\n\n", payload))
+}
+
+// SendDone writes the "[DONE]" sentinel that ends an OpenAI-style stream.
+func (s *sseWriter) SendDone() error {
+	return s.write("data: [DONE]\n\n")
+}
+
+func (s *sseWriter) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprint(s.w, frame); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	s.flusher.Flush()
+	return nil
+}
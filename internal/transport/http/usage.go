@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/usage"
+)
+
+// IdentifyCaller authenticates the API key sent as a bearer token or in
+// X-API-Key and attributes the request's LLM usage to it and, for admin
+// keys, to the user named in the X-User-ID header. Requests without a key
+// are anonymous; an unknown key is refused, and so is a user named by a key
+// that is not an admin key. Other HTTP transports, such as MCP's, use it to
+// check callers as the API does.
+func IdentifyCaller(keys usage.APIKeys, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r, ok := identify(w, r, keys); ok {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// identifyCaller is IdentifyCaller with the keys set by SetAPIKeys.
+func (h *Handler) identifyCaller(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r, ok := identify(w, r, h.apiKeys); ok {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// identify attaches the request's caller to its context, or answers with an
+// error and reports false.
+func identify(w http.ResponseWriter, r *http.Request, keys usage.APIKeys) (*http.Request, bool) {
+	apiKey := r.Header.Get("X-API-Key")
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		apiKey = token
+	}
+	caller, err := keys.Authenticate(strings.TrimSpace(apiKey), strings.TrimSpace(r.Header.Get("X-User-ID")))
+	if errors.Is(err, usage.ErrUserNotAllowed) {
+		http.Error(w, "X-User-ID requires an admin API key", http.StatusForbidden)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Invalid API key", http.StatusUnauthorized)
+		return nil, false
+	}
+	return r.WithContext(usage.WithCaller(r.Context(), caller)), true
+}
+
+// chatErrorStatus maps a failed chat query onto an HTTP status.
+func chatErrorStatus(err error) int {
+	if errors.Is(err, usage.ErrBudgetExceeded) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+// handleUsage reports token usage and cost per day, caller and model, to
+// authenticated callers only. Query parameters: from and to (UTC dates,
+// inclusive, default today), user and api_key_id. Admin keys may read the
+// usage of any caller; other keys only read their own.
+func (h *Handler) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if h.usageSvc == nil {
+		http.Error(w, "Usage accounting is not enabled", http.StatusNotFound)
+		return
+	}
+	caller := usage.CallerFrom(r.Context())
+	if caller.Anonymous() {
+		http.Error(w, "An API key is required to read usage", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := repository.UsageFilter{User: q.Get("user"), APIKeyID: q.Get("api_key_id")}
+	if !caller.Admin {
+		if filter.User != "" || (filter.APIKeyID != "" && filter.APIKeyID != caller.APIKeyID) {
+			http.Error(w, "Only admin API keys may read the usage of other callers", http.StatusForbidden)
+			return
+		}
+		filter.APIKeyID = caller.APIKeyID
+	}
+	if from := q.Get("from"); from != "" {
+		day, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			http.Error(w, "Invalid 'from' date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		filter.From = day
+	}
+	if to := q.Get("to"); to != "" {
+		day, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			http.Error(w, "Invalid 'to' date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		filter.To = day.Add(24 * time.Hour) // Inclusive
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		http.Error(w, "'from' must not be after 'to'", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.usageSvc.Report(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to report usage", "error", err)
+		http.Error(w, "Failed to report usage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
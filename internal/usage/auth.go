@@ -0,0 +1,58 @@
+package usage
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+var (
+	// ErrUnknownAPIKey is returned for an API key that is not configured.
+	ErrUnknownAPIKey = errors.New("unknown API key")
+	// ErrUserNotAllowed is returned when a key that is not an admin key
+	// names a user to act for.
+	ErrUserNotAllowed = errors.New("only admin API keys may act for a user")
+)
+
+// APIKeys are the keys callers authenticate with, from API_KEYS and
+// ADMIN_API_KEYS. Only their digests are kept.
+type APIKeys struct {
+	digests map[[sha256.Size]byte]bool // Whether each key is an admin key
+}
+
+// NewAPIKeys accepts the given keys and admin keys. Blank keys are ignored.
+func NewAPIKeys(keys, adminKeys []string) APIKeys {
+	k := APIKeys{digests: make(map[[sha256.Size]byte]bool)}
+	for _, key := range keys {
+		if key != "" {
+			k.digests[sha256.Sum256([]byte(key))] = false
+		}
+	}
+	for _, key := range adminKeys {
+		if key != "" {
+			k.digests[sha256.Sum256([]byte(key))] = true
+		}
+	}
+	return k
+}
+
+// Authenticate returns the caller a request is made for. A request without
+// an API key is anonymous, whatever user it names. Only an admin key may
+// name a user to act for: its holder, typically a trusted backend, vouches
+// for the user and spends that user's budget. Any other key is its own
+// caller and is refused with ErrUserNotAllowed when it names a user, so
+// that it cannot escape its budget by inventing users. An unknown key is
+// rejected with ErrUnknownAPIKey rather than taken for anonymous, so that a
+// mistyped key does not silently spend the anonymous budget.
+func (k APIKeys) Authenticate(apiKey, user string) (Caller, error) {
+	if apiKey == "" {
+		return Caller{}, nil
+	}
+	admin, ok := k.digests[sha256.Sum256([]byte(apiKey))]
+	if !ok {
+		return Caller{}, ErrUnknownAPIKey
+	}
+	if user != "" && !admin {
+		return Caller{}, ErrUserNotAllowed
+	}
+	return Caller{User: user, APIKeyID: KeyID(apiKey), Admin: admin}, nil
+}
@@ -0,0 +1,95 @@
+package usage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/repository"
+)
+
+// ErrBudgetExceeded is returned when a daily budget has been spent.
+var ErrBudgetExceeded = errors.New("daily LLM budget exceeded")
+
+// Caller identifies who a request is made for, once authenticated by
+// APIKeys. Both fields are empty for anonymous requests.
+type Caller struct {
+	User     string // User ID the key's holder acts for, if any
+	APIKeyID string // Fingerprint of the API key, see KeyID
+	Admin    bool   // The key may act for users and read everyone's usage
+}
+
+// Anonymous reports whether the caller is neither a user nor an API key.
+func (c Caller) Anonymous() bool {
+	return c.User == "" && c.APIKeyID == ""
+}
+
+// KeyID fingerprints an API key so that usage can be attributed to it
+// without storing the key.
+func KeyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "key_" + hex.EncodeToString(sum[:6])
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context whose LLM usage is attributed to the caller.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom returns the caller attached to the context, if any.
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
+}
+
+// Report is the usage of a period, with the budget of the caller it was
+// requested for.
+type Report struct {
+	From    string                 `json:"from"` // UTC dates, inclusive
+	To      string                 `json:"to"`
+	Usage   []*models.UsageSummary `json:"usage"`
+	Total   Totals                 `json:"total"`
+	Budgets []BudgetStatus         `json:"budgets,omitempty"`
+}
+
+// Totals sums the usage of a report. A request that used several models
+// counts once per model.
+type Totals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// BudgetStatus is how much of a daily budget has been spent today.
+type BudgetStatus struct {
+	Scope        string  `json:"scope"` // "caller", "anonymous" or "total"
+	DailyUSD     float64 `json:"daily_usd"`
+	SpentUSD     float64 `json:"spent_today_usd"`
+	RemainingUSD float64 `json:"remaining_usd"`
+}
+
+// Service enforces daily budgets and accounts for LLM usage.
+type Service interface {
+	// Check returns ErrBudgetExceeded when the context's caller, or all
+	// callers together, have spent their daily budget.
+	Check(ctx context.Context) error
+	// Record prices and stores the usage of one request for the context's
+	// caller, returning its cost.
+	Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error)
+	// Report summarizes the usage matching the filter, with the budgets of
+	// the context's caller.
+	Report(ctx context.Context, filter repository.UsageFilter) (*Report, error)
+}
+
+// startOfDay returns midnight UTC of t's day.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,84 @@
+package usage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"article-chat-system/internal/llm"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Price is what a model charges, in USD per million tokens. Cached prompt
+// tokens are charged at the prompt price unless their own price is set.
+type Price struct {
+	Prompt     float64  `yaml:"prompt"`
+	Completion float64  `yaml:"completion"`
+	CacheRead  *float64 `yaml:"cache_read"`
+	CacheWrite *float64 `yaml:"cache_write"`
+}
+
+// PriceTable maps model names to prices. A model uses the longest entry its
+// name starts with, so "gpt-4o-2024-08-06" is priced as "gpt-4o".
+type PriceTable map[string]Price
+
+// priceFile is the layout of the price table file.
+type priceFile struct {
+	Models PriceTable `yaml:"models"`
+}
+
+// LoadPrices reads a price table. A missing file is an empty table, which
+// prices every model at zero.
+func LoadPrices(path string) (PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return PriceTable{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+	var file priceFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse price table %s: %w", path, err)
+	}
+	if file.Models == nil {
+		file.Models = PriceTable{}
+	}
+	return file.Models, nil
+}
+
+// Lookup returns the price of a model.
+func (t PriceTable) Lookup(model string) (Price, bool) {
+	best := -1
+	var price Price
+	for prefix, p := range t {
+		if strings.HasPrefix(model, prefix) && len(prefix) > best {
+			best, price = len(prefix), p
+		}
+	}
+	return price, best >= 0
+}
+
+// Cost prices the usage of a model. Unknown models cost nothing.
+func (t PriceTable) Cost(model string, u llm.Usage) float64 {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return 0
+	}
+	cacheRead, cacheWrite := price.Prompt, price.Prompt
+	if price.CacheRead != nil {
+		cacheRead = *price.CacheRead
+	}
+	if price.CacheWrite != nil {
+		cacheWrite = *price.CacheWrite
+	}
+	// Cache reads and writes are included in the prompt tokens.
+	uncached := max(u.PromptTokens-u.CacheReadTokens-u.CacheWriteTokens, 0)
+	micro := float64(uncached)*price.Prompt +
+		float64(u.CacheReadTokens)*cacheRead +
+		float64(u.CacheWriteTokens)*cacheWrite +
+		float64(u.CompletionTokens)*price.Completion
+	return micro / 1e6
+}
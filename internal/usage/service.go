@@ -0,0 +1,174 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/metrics"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Budgets are daily spending limits in USD. Zero disables a limit.
+type Budgets struct {
+	PerCaller float64 // For each user or API key, and for all anonymous callers together
+	Total     float64 // For all callers together, anonymous ones included
+}
+
+// UsageService prices LLM usage, stores it and enforces daily budgets.
+type UsageService struct {
+	repo    repository.UsageRepository
+	prices  PriceTable
+	budgets Budgets
+	now     func() time.Time
+}
+
+// NewService is the constructor for the usage service.
+func NewService(repo repository.UsageRepository, prices PriceTable, budgets Budgets) *UsageService {
+	return &UsageService{repo: repo, prices: prices, budgets: budgets, now: time.Now}
+}
+
+// callerFilter selects the records of one caller. A user ID takes
+// precedence over the API key it was sent with, and anonymous callers share
+// one bucket.
+func callerFilter(caller Caller) repository.UsageFilter {
+	if caller.Anonymous() {
+		return repository.UsageFilter{Anonymous: true}
+	}
+	if caller.User != "" {
+		return repository.UsageFilter{User: caller.User}
+	}
+	return repository.UsageFilter{APIKeyID: caller.APIKeyID}
+}
+
+// Check rejects the request when a daily budget is spent. A request that
+// starts under budget runs to completion, so spending can overshoot a budget
+// by the cost of the requests in flight.
+func (s *UsageService) Check(ctx context.Context) error {
+	statuses, err := s.budgetStatus(ctx, CallerFrom(ctx))
+	if err != nil {
+		return err
+	}
+	for _, b := range statuses {
+		if b.RemainingUSD <= 0 {
+			metrics.BudgetRejections.WithLabelValues(b.Scope).Inc()
+			return fmt.Errorf("%w: $%.2f of the %s budget of $%.2f spent today", ErrBudgetExceeded, b.SpentUSD, b.Scope, b.DailyUSD)
+		}
+	}
+	return nil
+}
+
+// budgetStatus reports today's spending against the budgets that apply to
+// the caller. Anonymous callers are held to the per-caller budget together,
+// so that leaving out the API key does not lift it.
+func (s *UsageService) budgetStatus(ctx context.Context, caller Caller) ([]BudgetStatus, error) {
+	today := startOfDay(s.now())
+	var statuses []BudgetStatus
+	check := func(scope string, daily float64, filter repository.UsageFilter) error {
+		filter.From = today
+		spent, err := s.repo.TotalCost(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to check the %s budget: %w", scope, err)
+		}
+		statuses = append(statuses, BudgetStatus{Scope: scope, DailyUSD: daily, SpentUSD: spent, RemainingUSD: max(daily-spent, 0)})
+		return nil
+	}
+	if s.budgets.PerCaller > 0 {
+		scope := "caller"
+		if caller.Anonymous() {
+			scope = "anonymous"
+		}
+		if err := check(scope, s.budgets.PerCaller, callerFilter(caller)); err != nil {
+			return nil, err
+		}
+	}
+	if s.budgets.Total > 0 {
+		if err := check("total", s.budgets.Total, repository.UsageFilter{}); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// Record prices the usage of each model and stores it as one request.
+// Models that consumed no tokens are skipped.
+func (s *UsageService) Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error) {
+	caller := CallerFrom(ctx)
+	requestID := uuid.NewString()
+	now := s.now()
+
+	var records []*models.UsageRecord
+	var total float64
+	for model, u := range byModel {
+		if u.TotalTokens == 0 && u.PromptTokens == 0 && u.CompletionTokens == 0 {
+			continue
+		}
+		cost := s.prices.Cost(model, u)
+		total += cost
+		metrics.LLMCost.WithLabelValues(model).Add(cost)
+		records = append(records, &models.UsageRecord{
+			RequestID:        requestID,
+			CreatedAt:        now,
+			User:             caller.User,
+			APIKeyID:         caller.APIKeyID,
+			Operation:        operation,
+			Model:            model,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			TotalTokens:      u.TotalTokens,
+			CacheReadTokens:  u.CacheReadTokens,
+			CacheWriteTokens: u.CacheWriteTokens,
+			CostUSD:          cost,
+		})
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Model < records[j].Model })
+	if err := s.repo.SaveUsage(ctx, records); err != nil {
+		return total, fmt.Errorf("failed to record usage: %w", err)
+	}
+	return total, nil
+}
+
+// Report summarizes usage between two UTC dates. A zero From starts today
+// and a zero To ends today.
+func (s *UsageService) Report(ctx context.Context, filter repository.UsageFilter) (*Report, error) {
+	today := startOfDay(s.now())
+	if filter.From.IsZero() {
+		filter.From = today
+	}
+	if filter.To.IsZero() {
+		filter.To = today.Add(24 * time.Hour)
+	}
+
+	summaries, err := s.repo.SummarizeUsage(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{
+		From:  filter.From.UTC().Format(time.DateOnly),
+		To:    filter.To.Add(-time.Nanosecond).UTC().Format(time.DateOnly),
+		Usage: summaries,
+	}
+	if report.Usage == nil {
+		report.Usage = []*models.UsageSummary{}
+	}
+	for _, row := range summaries {
+		report.Total.Requests += row.Requests
+		report.Total.PromptTokens += row.PromptTokens
+		report.Total.CompletionTokens += row.CompletionTokens
+		report.Total.TotalTokens += row.TotalTokens
+		report.Total.CostUSD += row.CostUSD
+	}
+
+	if report.Budgets, err = s.budgetStatus(ctx, CallerFrom(ctx)); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,52 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrUnavailable is returned by vector stores that are not connected to
+// Weaviate, e.g. because it was unreachable at startup.
+var ErrUnavailable = errors.New("vector store unavailable")
+
+// Connector is implemented by vector stores that can attach to Weaviate
+// after they were created.
+type Connector interface {
+	// Connect verifies Weaviate is reachable, prepares the schema and marks
+	// the store ready. It is safe to call again after a failure.
+	Connect(ctx context.Context) error
+	Ready() bool
+}
+
+// Reconnect retries Connect on every store that is not ready yet, doubling the
+// wait between attempts up to maxInterval, until all are ready or the context
+// is cancelled. It blocks; run it in its own goroutine.
+func Reconnect(ctx context.Context, logger *slog.Logger, interval, maxInterval time.Duration, stores ...Connector) {
+	for {
+		pending := 0
+		for _, store := range stores {
+			if store.Ready() {
+				continue
+			}
+			if err := store.Connect(ctx); err != nil {
+				pending++
+				logger.Debug("Weaviate still unreachable", "error", err)
+			}
+		}
+		if pending == 0 {
+			logger.Info("Vector search attached to Weaviate")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
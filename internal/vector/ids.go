@@ -0,0 +1,22 @@
+package vector
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/weaviate/weaviate-go-client/v4/weaviate/fault"
+)
+
+// ObjectID returns the deterministic Weaviate object ID for an article URL.
+// Weaviate only accepts UUIDs as IDs, so a UUID v5 (SHA-1) of the URL is used;
+// the same URL always maps to the same object.
+func ObjectID(url string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(url)).String()
+}
+
+// IsNotFound reports whether a Weaviate call failed because the object does not exist.
+func IsNotFound(err error) bool {
+	var clientErr *fault.WeaviateClientError
+	return errors.As(err, &clientErr) && clientErr.StatusCode == http.StatusNotFound
+}
@@ -13,7 +13,7 @@ type Service interface {
 	// SearchBySemanticSimilarity searches for articles semantically similar to the query
 	SearchBySemanticSimilarity(ctx context.Context, query string, limit int) ([]*models.Article, error)
 
-	// IndexArticle adds an article to the vector database
+	// IndexArticle adds an article to the vector database, or updates it
 	IndexArticle(ctx context.Context, article *models.Article) error
 
 	// RemoveArticle removes an article from the vector database
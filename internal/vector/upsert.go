@@ -0,0 +1,34 @@
+package vector
+
+import (
+	"context"
+
+	"github.com/weaviate/weaviate-go-client/v4/weaviate"
+)
+
+// UpsertObject writes the properties of the object with the given ID,
+// creating it if it does not exist yet. Weaviate's creator fails on an
+// existing ID, so an article that is written again is merged instead.
+func UpsertObject(ctx context.Context, client *weaviate.Client, class, id string, properties map[string]interface{}) error {
+	exists, err := client.Data().Checker().
+		WithClassName(class).
+		WithID(id).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return client.Data().Updater().
+			WithMerge().
+			WithClassName(class).
+			WithID(id).
+			WithProperties(properties).
+			Do(ctx)
+	}
+	_, err = client.Data().Creator().
+		WithClassName(class).
+		WithID(id).
+		WithProperties(properties).
+		Do(ctx)
+	return err
+}
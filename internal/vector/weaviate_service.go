@@ -1,11 +1,13 @@
 package vector
 
 import (
+	"article-chat-system/internal/metrics"
 	"article-chat-system/internal/models"
 	"context"
 	"fmt"
 	"log"
-	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/weaviate/weaviate-go-client/v4/weaviate"
 	"github.com/weaviate/weaviate-go-client/v4/weaviate/filters"
@@ -17,10 +19,24 @@ import (
 type WeaviateService struct {
 	client *weaviate.Client
 	class  string
+	ready  atomic.Bool
 }
 
 // NewWeaviateService creates a new Weaviate service
 func NewWeaviateService(host, scheme, apiKey string) (*WeaviateService, error) {
+	service, err := OpenWeaviateService(host, scheme, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.Connect(context.Background()); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// OpenWeaviateService creates a service without contacting Weaviate. Its
+// methods return ErrUnavailable until Connect succeeds.
+func OpenWeaviateService(host, scheme, apiKey string) (*WeaviateService, error) {
 	config := weaviate.Config{
 		Host:   host,
 		Scheme: scheme,
@@ -37,23 +53,30 @@ func NewWeaviateService(host, scheme, apiKey string) (*WeaviateService, error) {
 		return nil, fmt.Errorf("failed to create Weaviate client: %w", err)
 	}
 
-	service := &WeaviateService{
+	return &WeaviateService{
 		client: client,
 		class:  "Article",
-	}
+	}, nil
+}
 
-	// Ensure the class exists
-	if err := service.ensureClassExists(); err != nil {
-		return nil, fmt.Errorf("failed to ensure class exists: %w", err)
+// Connect ensures the class exists and marks the service ready.
+func (w *WeaviateService) Connect(ctx context.Context) error {
+	if err := w.ensureClassExists(ctx); err != nil {
+		return fmt.Errorf("failed to ensure class exists: %w", err)
 	}
+	w.ready.Store(true)
+	return nil
+}
 
-	return service, nil
+// Ready reports whether the service has connected to Weaviate.
+func (w *WeaviateService) Ready() bool {
+	return w.ready.Load()
 }
 
 // ensureClassExists creates the Article class if it doesn't exist
-func (w *WeaviateService) ensureClassExists() error {
+func (w *WeaviateService) ensureClassExists(ctx context.Context) error {
 	// Check if class exists
-	exists, err := w.client.Schema().ClassExistenceChecker().WithClassName(w.class).Do(context.Background())
+	exists, err := w.client.Schema().ClassExistenceChecker().WithClassName(w.class).Do(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check class existence: %w", err)
 	}
@@ -107,7 +130,7 @@ func (w *WeaviateService) ensureClassExists() error {
 		},
 	}
 
-	err = w.client.Schema().ClassCreator().WithClass(class).Do(context.Background())
+	err = w.client.Schema().ClassCreator().WithClass(class).Do(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create class: %w", err)
 	}
@@ -118,6 +141,9 @@ func (w *WeaviateService) ensureClassExists() error {
 
 // SearchByTopics searches for articles that contain the specified topics/parameters
 func (w *WeaviateService) SearchByTopics(ctx context.Context, topics []string, limit int) ([]*models.Article, error) {
+	if !w.Ready() {
+		return nil, ErrUnavailable
+	}
 	if len(topics) == 0 {
 		return []*models.Article{}, nil
 	}
@@ -175,7 +201,9 @@ func (w *WeaviateService) SearchByTopics(ctx context.Context, topics []string, l
 		WithWhere(whereFilter).
 		WithLimit(limit)
 
+	start := time.Now()
 	result, err := builder.Do(ctx)
+	metrics.ObserveSince(metrics.VectorSearchDuration.WithLabelValues("topics", metrics.Outcome(err)), start)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search articles: %w", err)
 	}
@@ -185,6 +213,9 @@ func (w *WeaviateService) SearchByTopics(ctx context.Context, topics []string, l
 
 // SearchBySemanticSimilarity searches for articles semantically similar to the query
 func (w *WeaviateService) SearchBySemanticSimilarity(ctx context.Context, query string, limit int) ([]*models.Article, error) {
+	if !w.Ready() {
+		return nil, ErrUnavailable
+	}
 	// Use nearText search for semantic similarity
 	builder := w.client.GraphQL().Get().
 		WithClassName(w.class).
@@ -201,7 +232,9 @@ func (w *WeaviateService) SearchBySemanticSimilarity(ctx context.Context, query
 		WithNearText(w.client.GraphQL().NearTextArgBuilder().WithConcepts([]string{query})).
 		WithLimit(limit)
 
+	start := time.Now()
 	result, err := builder.Do(ctx)
+	metrics.ObserveSince(metrics.VectorSearchDuration.WithLabelValues("semantic", metrics.Outcome(err)), start)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search articles by semantic similarity: %w", err)
 	}
@@ -209,8 +242,12 @@ func (w *WeaviateService) SearchBySemanticSimilarity(ctx context.Context, query
 	return w.parseSearchResults(result)
 }
 
-// IndexArticle adds an article to the vector database
+// IndexArticle adds an article to the vector database, or updates it when
+// it is indexed already.
 func (w *WeaviateService) IndexArticle(ctx context.Context, article *models.Article) error {
+	if !w.Ready() {
+		return ErrUnavailable
+	}
 	// Convert article to Weaviate object
 	weaviateObject := map[string]interface{}{
 		"url":         article.URL,
@@ -223,17 +260,7 @@ func (w *WeaviateService) IndexArticle(ctx context.Context, article *models.Arti
 		"processedAt": article.ProcessedAt,
 	}
 
-	// Use URL as the object ID for consistency
-	objectID := strings.ReplaceAll(article.URL, "/", "_")
-	objectID = strings.ReplaceAll(objectID, ":", "_")
-	objectID = strings.ReplaceAll(objectID, ".", "_")
-
-	_, err := w.client.Data().Creator().
-		WithClassName(w.class).
-		WithID(objectID).
-		WithProperties(weaviateObject).
-		Do(ctx)
-
+	err := UpsertObject(ctx, w.client, w.class, ObjectID(article.URL), weaviateObject)
 	if err != nil {
 		return fmt.Errorf("failed to index article: %w", err)
 	}
@@ -244,14 +271,12 @@ func (w *WeaviateService) IndexArticle(ctx context.Context, article *models.Arti
 
 // RemoveArticle removes an article from the vector database
 func (w *WeaviateService) RemoveArticle(ctx context.Context, url string) error {
-	// Convert URL to object ID
-	objectID := strings.ReplaceAll(url, "/", "_")
-	objectID = strings.ReplaceAll(objectID, ":", "_")
-	objectID = strings.ReplaceAll(objectID, ".", "_")
-
+	if !w.Ready() {
+		return ErrUnavailable
+	}
 	err := w.client.Data().Deleter().
 		WithClassName(w.class).
-		WithID(objectID).
+		WithID(ObjectID(url)).
 		Do(ctx)
 
 	if err != nil {
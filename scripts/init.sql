@@ -2,9 +2,82 @@ CREATE TABLE articles (
     url TEXT PRIMARY KEY,
     title TEXT NOT NULL,
     excerpt TEXT,
+    text_content TEXT,
     summary TEXT,
     sentiment TEXT,
     topics TEXT[],
     entities TEXT[],
     processed_at TIMESTAMPTZ NOT NULL
 );
+
+CREATE INDEX articles_processed_at_idx ON articles (processed_at DESC, url DESC);
+
+CREATE TABLE chat_sessions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE chat_turns (
+    id BIGSERIAL PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES chat_sessions(id) ON DELETE CASCADE,
+    query TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    intent TEXT,
+    targets TEXT[],
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX chat_turns_session_idx ON chat_turns (session_id, created_at);
+
+CREATE TABLE ingestion_jobs (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    user_id TEXT NOT NULL DEFAULT '',
+    api_key_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE ingestion_job_items (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES ingestion_jobs(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ingestion_job_items_job_idx ON ingestion_job_items (job_id);
+CREATE INDEX ingestion_job_items_ready_idx ON ingestion_job_items (status, next_attempt_at);
+
+CREATE TABLE llm_cache (
+    key TEXT PRIMARY KEY,
+    model TEXT NOT NULL,
+    prompt_version TEXT NOT NULL,
+    response JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    accessed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX llm_cache_accessed_idx ON llm_cache (accessed_at);
+
+CREATE TABLE llm_usage (
+    id BIGSERIAL PRIMARY KEY,
+    request_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    user_id TEXT NOT NULL DEFAULT '',
+    api_key_id TEXT NOT NULL DEFAULT '',
+    operation TEXT NOT NULL,
+    model TEXT NOT NULL,
+    prompt_tokens INT NOT NULL,
+    completion_tokens INT NOT NULL,
+    total_tokens INT NOT NULL,
+    cache_read_tokens INT NOT NULL DEFAULT 0,
+    cache_write_tokens INT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX llm_usage_created_idx ON llm_usage (created_at);
+CREATE INDEX llm_usage_caller_idx ON llm_usage (user_id, api_key_id, created_at);
@@ -0,0 +1,182 @@
+package integration
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/cache"
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/resolver"
+	"article-chat-system/internal/strategies"
+)
+
+// chatCassette holds the recorded answers the chat tests replay. Re-record it
+// against a real provider with
+//
+//	LLM_CASSETTE_MODE=record LLM_PROVIDER=openai OPENAI_API_KEY=... go test ./tests/integration -run TestChat
+const chatCassette = "testdata/chat_cassette.json"
+
+const (
+	layoffsURL = "https://edition.cnn.com/2025/07/24/tech/intel-layoffs-15-percent-q2-earnings"
+	tradeURL   = "https://edition.cnn.com/2025/07/27/business/eu-trade-deal"
+)
+
+// memoryArticleRepository is an in-memory repository.ArticleRepository, so
+// the chat tests need neither Docker nor a database.
+type memoryArticleRepository struct {
+	mu       sync.Mutex
+	articles map[string]*models.Article
+}
+
+func newMemoryArticleRepository(articles ...*models.Article) *memoryArticleRepository {
+	r := &memoryArticleRepository{articles: make(map[string]*models.Article)}
+	for _, art := range articles {
+		r.articles[art.URL] = art
+	}
+	return r
+}
+
+func (r *memoryArticleRepository) Save(ctx context.Context, art *models.Article) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.articles[art.URL] = art
+	return nil
+}
+
+func (r *memoryArticleRepository) FindByURL(ctx context.Context, url string) (*models.Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.articles[url], nil
+}
+
+func (r *memoryArticleRepository) List(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page := &repository.ArticlePage{Articles: []*models.Article{}}
+	for _, art := range r.articles {
+		page.Articles = append(page.Articles, art)
+	}
+	sort.Slice(page.Articles, func(i, j int) bool { return page.Articles[i].URL < page.Articles[j].URL })
+	return page, nil
+}
+
+func (r *memoryArticleRepository) Delete(ctx context.Context, url string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.articles[url]
+	delete(r.articles, url)
+	return ok, nil
+}
+
+func (r *memoryArticleRepository) FindTopEntities(ctx context.Context, articleURLs []string, limit int) ([]repository.EntityCount, error) {
+	return nil, nil
+}
+
+// newReplayChatService wires the chat service as the server does, with LLM
+// clients that replay chatCassette unless LLM_CASSETTE_MODE says otherwise.
+func newReplayChatService(t *testing.T) *chat.ChatService {
+	t.Helper()
+	cfg := &config.Config{
+		LLMProvider:     config.GetEnv("LLM_PROVIDER", "openai"),
+		OpenAIAPIKey:    config.GetEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:     config.GetEnv("OPENAI_MODEL", "gpt-4o-mini"),
+		AnthropicAPIKey: config.GetEnv("ANTHROPIC_API_KEY", ""),
+		LLMModel:        config.GetEnv("LLM_MODEL", ""),
+		LLMMockFixture:  config.GetEnv("LLM_MOCK_FIXTURE", ""),
+		LLMCassette:     chatCassette,
+		LLMCassetteMode: config.GetEnv("LLM_CASSETTE_MODE", llm.CassetteReplay),
+		PromptVersion:   "v1",
+	}
+	router, err := llm.NewRouterFactory(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewRouterFactory() error = %v", err)
+	}
+
+	loader, _ := prompts.NewLoader(cfg.PromptVersion)
+	loader.PromptDir = filepath.Join("..", "..", loader.PromptDir)
+	promptFactory, err := prompts.NewFactory(loader)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+
+	processed := time.Date(2025, 7, 28, 9, 0, 0, 0, time.UTC)
+	repo := newMemoryArticleRepository(
+		&models.Article{
+			URL:         layoffsURL,
+			Title:       "Intel to lay off 15% of its workforce",
+			Excerpt:     "Intel will cut about 15% of its staff as it struggles to turn its business around.",
+			TextContent: "Intel said on Thursday it will lay off about 15% of its workforce, roughly 24,000 people, by the end of the year. The chipmaker reported a second-quarter loss and said it would cut costs by $10 billion in 2025. Chief executive Lip-Bu Tan said the company would also cancel planned factories in Germany and Poland and slow construction in Ohio.",
+			Summary:     "Intel lays off 15% of its staff\n- About 24,000 jobs are cut by the end of the year\n- Factories in Germany and Poland are cancelled",
+			Sentiment:   "Negative",
+			Topics:      []string{"Intel", "layoffs", "semiconductors"},
+			Entities:    []string{"Intel", "Lip-Bu Tan", "Germany", "Poland", "Ohio"},
+			ProcessedAt: processed,
+		},
+		&models.Article{
+			URL:         tradeURL,
+			Title:       "What the US-EU trade deal means",
+			Excerpt:     "The US and the EU agreed on a 15% tariff on most European goods.",
+			TextContent: "The United States and the European Union reached a trade deal on Sunday that sets a 15% tariff on most European goods, averting a threatened 30% rate. European leaders called the deal the best available outcome, while business groups warned it would still hurt exporters. The EU agreed to buy $750 billion of American energy over three years.",
+			Summary:     "The US and EU agree on a 15% tariff\n- A 30% tariff is averted\n- The EU will buy $750 billion of US energy",
+			Sentiment:   "Neutral",
+			Topics:      []string{"trade", "tariffs", "European Union"},
+			Entities:    []string{"United States", "European Union"},
+			ProcessedAt: processed,
+		},
+	)
+
+	articleSvc := article.NewService(router.Client(llm.RoleSynthesis), repo, nil)
+	executor := strategies.NewExecutor()
+	plannerSvc := planner.NewService(router.Client(llm.RolePlanner), promptFactory, articleSvc, nil, executor)
+	svc := chat.NewService(plannerSvc, executor, articleSvc, promptFactory, nil, cache.NewService())
+	svc.SetTargetResolver(resolver.NewResolver(articleSvc))
+	return svc
+}
+
+func TestChat_ReplaysRecordedAnswers(t *testing.T) {
+	svc := newReplayChatService(t)
+
+	tests := []struct {
+		name          string
+		query         string
+		intent        planner.QueryIntent
+		shouldContain string
+	}{
+		{name: "summary", query: "Summarize the article " + layoffsURL, intent: planner.IntentSummarize, shouldContain: "24,000"},
+		{name: "keywords", query: "Extract keywords from the article " + tradeURL, intent: planner.IntentKeywords, shouldContain: "tariff"},
+		{name: "tone", query: "Compare the tone of " + layoffsURL + " and " + tradeURL, intent: planner.IntentCompareTone, shouldContain: "Intel"},
+		{name: "several steps", query: "Summarize " + tradeURL + " and extract the keywords of " + layoffsURL, shouldContain: "trade deal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, err := svc.Ask(context.Background(), chat.Request{Query: tt.query})
+			if err != nil {
+				t.Fatalf("Ask(%q) error = %v", tt.query, err)
+			}
+			if tt.intent != "" && answer.Plan.Intent != tt.intent {
+				t.Errorf("Expected intent %s, got %s", tt.intent, answer.Plan.Intent)
+			}
+			if tt.intent == "" && len(answer.Plan.Steps) < 2 {
+				t.Errorf("Expected a plan with several steps, got %+v", answer.Plan)
+			}
+			if !strings.Contains(answer.Answer, tt.shouldContain) {
+				t.Errorf("Expected the answer to contain %q, got %q", tt.shouldContain, answer.Answer)
+			}
+			if len(answer.Sources) == 0 {
+				t.Error("Expected the answer to cite its articles")
+			}
+		})
+	}
+}
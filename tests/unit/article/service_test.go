@@ -43,12 +43,20 @@ func (m *mockRepository) FindByURL(ctx context.Context, url string) (*models.Art
 	return nil, nil // Not found
 }
 
-func (m *mockRepository) FindAll(ctx context.Context) ([]*models.Article, error) {
-	var articles []*models.Article
+func (m *mockRepository) Delete(ctx context.Context, url string) (bool, error) {
+	if _, exists := m.articles[url]; !exists {
+		return false, nil
+	}
+	delete(m.articles, url)
+	return true, nil
+}
+
+func (m *mockRepository) List(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	page := &repository.ArticlePage{}
 	for _, art := range m.articles {
-		articles = append(articles, art)
+		page.Articles = append(page.Articles, art)
 	}
-	return articles, nil
+	return page, nil
 }
 
 func (m *mockRepository) FindTopEntities(ctx context.Context, articleURLs []string, limit int) ([]repository.EntityCount, error) {
@@ -193,7 +201,7 @@ func TestArticleService_CallSynthesisLLM(t *testing.T) {
 			tt.setup(mockLLM)
 			service := article.NewService(mockLLM, mockRepo, nil)
 
-			result, err := service.CallSynthesisLLM(context.Background(), "test prompt")
+			result, err := service.CallSynthesisLLM(context.Background(), llm.NewRequest("", "test prompt"))
 
 			if tt.hasError {
 				if err == nil {
@@ -211,6 +219,55 @@ func TestArticleService_CallSynthesisLLM(t *testing.T) {
 	}
 }
 
+// mockStreamingLLMClient emits a fixed list of chunks through StreamContent.
+type mockStreamingLLMClient struct {
+	mockLLMClient
+	chunks []string
+}
+
+func (m *mockStreamingLLMClient) StreamContent(ctx context.Context, prompt string, onChunk llm.StreamHandler) (*llm.Response, error) {
+	text := ""
+	for _, chunk := range m.chunks {
+		if err := onChunk(chunk); err != nil {
+			return nil, err
+		}
+		text += chunk
+	}
+	return &llm.Response{Text: text}, nil
+}
+
+func TestArticleService_CallSynthesisLLM_Streaming(t *testing.T) {
+	mockLLM := &mockStreamingLLMClient{chunks: []string{"Hello", ", ", "world"}}
+	service := article.NewService(mockLLM, newMockRepository(), nil)
+
+	var received []string
+	ctx := llm.WithStreamHandler(context.Background(), func(chunk string) error {
+		received = append(received, chunk)
+		return nil
+	})
+
+	result, err := service.CallSynthesisLLM(ctx, llm.NewRequest("", "test prompt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Hello, world" {
+		t.Errorf("Expected assembled answer 'Hello, world', got %q", result)
+	}
+	if len(received) != 3 {
+		t.Errorf("Expected 3 streamed chunks, got %d", len(received))
+	}
+
+	// Without a stream handler the client must not stream.
+	received = nil
+	result, err = service.CallSynthesisLLM(context.Background(), llm.NewRequest("", "test prompt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != "Mock response" || len(received) != 0 {
+		t.Errorf("Expected non-streamed 'Mock response', got %q with %d chunks", result, len(received))
+	}
+}
+
 // Add mock error types for testing
 type mockErrNotFound struct{}
 
@@ -0,0 +1,87 @@
+package cache_test
+
+import (
+	"testing"
+
+	"article-chat-system/internal/cache"
+	"article-chat-system/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestService_InvalidateArticle(t *testing.T) {
+	svc := cache.NewService()
+
+	svc.SetForArticles("intel", "intel answer", []string{"https://example.com/intel"})
+	svc.SetForArticles("both", "comparison", []string{"https://example.com/intel", "https://example.com/eu"})
+	svc.SetForArticles("eu", "eu answer", []string{"https://example.com/eu"})
+	svc.Set("topic", "topic answer")
+
+	removed := svc.InvalidateArticle("https://example.com/intel")
+	if removed != 3 {
+		t.Errorf("Expected 3 entries removed, got %d", removed)
+	}
+
+	for _, key := range []string{"intel", "both", "topic"} {
+		if _, found := svc.Get(key); found {
+			t.Errorf("Expected %q to be invalidated", key)
+		}
+	}
+	if answer, found := svc.Get("eu"); !found || answer != "eu answer" {
+		t.Errorf("Expected unrelated entry to survive, got %q (found=%v)", answer, found)
+	}
+
+	// A second invalidation has nothing left to remove for this article.
+	if removed := svc.InvalidateArticle("https://example.com/intel"); removed != 0 {
+		t.Errorf("Expected 0 entries removed, got %d", removed)
+	}
+}
+
+func TestService_InvalidateUntargeted(t *testing.T) {
+	svc := cache.NewService()
+
+	svc.SetForArticles("intel", "intel answer", []string{"https://example.com/intel"})
+	svc.Set("topic", "topic answer")
+	// Replacing an entry with an untargeted one must unindex it from its article.
+	svc.SetForArticles("replaced", "old answer", []string{"https://example.com/intel"})
+	svc.Set("replaced", "new answer")
+
+	if removed := svc.InvalidateUntargeted(); removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+	for _, key := range []string{"topic", "replaced"} {
+		if _, found := svc.Get(key); found {
+			t.Errorf("Expected %q to be invalidated", key)
+		}
+	}
+	if answer, found := svc.Get("intel"); !found || answer != "intel answer" {
+		t.Errorf("Expected the targeted entry to survive, got %q (found=%v)", answer, found)
+	}
+
+	// A new entry under the evicted key is not dropped with the old article.
+	svc.SetForArticles("replaced", "eu answer", []string{"https://example.com/eu"})
+	if removed := svc.InvalidateArticle("https://example.com/intel"); removed != 1 {
+		t.Errorf("Expected only the intel entry to be removed, got %d", removed)
+	}
+	if _, found := svc.Get("replaced"); !found {
+		t.Error("Expected the entry for another article to survive")
+	}
+}
+
+func TestService_GetCountsLookups(t *testing.T) {
+	svc := cache.NewService()
+	hits := testutil.ToFloat64(metrics.CacheLookups.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(metrics.CacheLookups.WithLabelValues("miss"))
+
+	svc.Set("present", "answer")
+	svc.Get("present")
+	svc.Get("absent")
+	svc.Get("absent")
+
+	if got := testutil.ToFloat64(metrics.CacheLookups.WithLabelValues("hit")) - hits; got != 1 {
+		t.Errorf("Expected 1 cache hit, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.CacheLookups.WithLabelValues("miss")) - misses; got != 2 {
+		t.Errorf("Expected 2 cache misses, got %v", got)
+	}
+}
@@ -0,0 +1,315 @@
+package chat_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/article"
+	"article-chat-system/internal/cache"
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/metrics"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/resolver"
+	"article-chat-system/internal/strategies"
+	"article-chat-system/internal/vector"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type mockPlanner struct {
+	calls int
+	plan  planner.QueryPlan
+}
+
+func (m *mockPlanner) CreatePlan(ctx context.Context, query string, history []models.Turn) (*planner.QueryPlan, error) {
+	m.calls++
+	plan := m.plan
+	return &plan, nil
+}
+
+type recordingStrategy struct {
+	intents []planner.QueryIntent
+}
+
+func (s *recordingStrategy) Execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+	s.intents = append(s.intents, plan.Intent)
+	return &planner.Result{
+		Answer:  "answer for " + string(plan.Intent),
+		Sources: []planner.Source{{URL: "https://example.com/a", Title: "A", Score: 1}},
+	}, nil
+}
+
+func (s *recordingStrategy) Tool() llm.Tool {
+	return llm.Tool{Name: "recording"}
+}
+
+func newTestService(plannerSvc planner.Service, strategy planner.IntentStrategy) *chat.ChatService {
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentSummarize: strategy,
+			planner.IntentKeywords:  strategy,
+		},
+	}
+	promptFactory, _ := prompts.NewFactory(&prompts.Loader{Version: "v1"})
+	return chat.NewService(plannerSvc, executor, nil, promptFactory, nil, cache.NewService())
+}
+
+func TestChatService_AskCachesStandaloneQuestions(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/a"}}}
+	svc := newTestService(plannerSvc, &recordingStrategy{})
+
+	var planned *planner.QueryPlan
+	first, err := svc.Ask(context.Background(), chat.Request{
+		Query:  "summarize a",
+		OnPlan: func(plan *planner.QueryPlan) error { planned = plan; return nil },
+	})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if planned == nil || planned.Intent != planner.IntentSummarize {
+		t.Errorf("Expected OnPlan to receive the plan, got %+v", planned)
+	}
+	if first.Cached || first.PromptVersion != "v1" || len(first.Sources) != 1 {
+		t.Errorf("Unexpected first answer: %+v", first)
+	}
+	if _, ok := first.LatencyMs["total"]; !ok {
+		t.Errorf("Expected total latency, got %v", first.LatencyMs)
+	}
+
+	second, err := svc.Ask(context.Background(), chat.Request{Query: "summarize a"})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if !second.Cached || plannerSvc.calls != 1 {
+		t.Errorf("Expected a cache hit without planning, got cached=%v planner calls=%d", second.Cached, plannerSvc.calls)
+	}
+	if len(second.Sources) != 1 || second.Plan == nil {
+		t.Errorf("Expected cached answer to keep its plan and sources, got %+v", second)
+	}
+
+	// Follow-ups depend on the conversation and bypass the cache.
+	history := []models.Turn{{Query: "summarize a", Answer: first.Answer}}
+	third, err := svc.Ask(context.Background(), chat.Request{Query: "summarize a", History: history})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if third.Cached || plannerSvc.calls != 2 {
+		t.Errorf("Expected follow-up to be planned afresh, got cached=%v planner calls=%d", third.Cached, plannerSvc.calls)
+	}
+}
+
+func TestChatService_AskCachesUnderTargetsAndSources(t *testing.T) {
+	// The answer targets b but draws on a as well, so a change to a must drop it.
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/b"}}}
+	executor := &strategies.Executor{Strategies: map[planner.QueryIntent]planner.IntentStrategy{planner.IntentSummarize: &recordingStrategy{}}}
+	promptFactory, _ := prompts.NewFactory(&prompts.Loader{Version: "v1"})
+	cacheSvc := cache.NewService()
+	svc := chat.NewService(plannerSvc, executor, nil, promptFactory, nil, cacheSvc)
+
+	if _, err := svc.Ask(context.Background(), chat.Request{Query: "compare b"}); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if removed := cacheSvc.InvalidateArticle("https://example.com/a"); removed != 1 {
+		t.Errorf("Expected the answer to be dropped with its source, got %d entries removed", removed)
+	}
+	answer, err := svc.Ask(context.Background(), chat.Request{Query: "compare b"})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if answer.Cached || plannerSvc.calls != 2 {
+		t.Errorf("Expected the answer to be planned afresh, got cached=%v planner calls=%d", answer.Cached, plannerSvc.calls)
+	}
+}
+
+func TestChatService_AskWithForcedIntent(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize}}
+	strategy := &recordingStrategy{}
+	svc := newTestService(plannerSvc, strategy)
+
+	if _, err := svc.Ask(context.Background(), chat.Request{Query: "tell me about a"}); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	answer, err := svc.Ask(context.Background(), chat.Request{Query: "tell me about a", Intent: planner.IntentKeywords})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+
+	if answer.Cached {
+		t.Error("Expected a forced intent not to reuse the answer cached for the planner's intent")
+	}
+	want := []planner.QueryIntent{planner.IntentSummarize, planner.IntentKeywords}
+	if len(strategy.intents) != 2 || strategy.intents[0] != want[0] || strategy.intents[1] != want[1] {
+		t.Errorf("Expected executed intents %v, got %v", want, strategy.intents)
+	}
+}
+
+func TestChatService_Intents(t *testing.T) {
+	svc := newTestService(&mockPlanner{}, &recordingStrategy{})
+
+	intents := svc.Intents()
+	if len(intents) != 2 || intents[0] != planner.IntentKeywords || intents[1] != planner.IntentSummarize {
+		t.Errorf("Expected sorted registered intents, got %v", intents)
+	}
+}
+
+func TestChatService_AskRecordsLatencyByIntent(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentKeywords}}
+	svc := newTestService(plannerSvc, &recordingStrategy{})
+
+	if _, err := svc.Ask(context.Background(), chat.Request{Query: "keywords of a"}); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+
+	observer := metrics.ChatDuration.WithLabelValues(string(planner.IntentKeywords), metrics.OutcomeSuccess)
+	if got := testutil.CollectAndCount(observer.(prometheus.Collector)); got != 1 {
+		t.Errorf("Expected a latency series for the planned intent, got %d", got)
+	}
+}
+
+type meteredStrategy struct{}
+
+func (meteredStrategy) Execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+	llm.RecordUsage(ctx, "gpt-4o-mini", llm.Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120})
+	return &planner.Result{Answer: "metered"}, nil
+}
+
+func (meteredStrategy) Tool() llm.Tool {
+	return llm.Tool{Name: "metered"}
+}
+
+type mockTracker struct {
+	checkErr error
+	checks   int
+	recorded []map[string]llm.Usage
+}
+
+func (m *mockTracker) Check(ctx context.Context) error {
+	m.checks++
+	return m.checkErr
+}
+
+func (m *mockTracker) Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error) {
+	m.recorded = append(m.recorded, byModel)
+	return 0.25, nil
+}
+
+func TestChatService_AskRecordsUsage(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/a"}}}
+	svc := newTestService(plannerSvc, meteredStrategy{})
+	tracker := &mockTracker{}
+	svc.SetUsageTracker(tracker)
+
+	answer, err := svc.Ask(context.Background(), chat.Request{Query: "summarize a"})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if answer.CostUSD != 0.25 {
+		t.Errorf("Expected the tracked cost on the answer, got %g", answer.CostUSD)
+	}
+	if len(tracker.recorded) != 1 || tracker.recorded[0]["gpt-4o-mini"].TotalTokens != 120 {
+		t.Fatalf("Expected the request's usage to be recorded per model, got %v", tracker.recorded)
+	}
+
+	// Cached answers cost nothing and are neither checked nor recorded.
+	if _, err := svc.Ask(context.Background(), chat.Request{Query: "summarize a"}); err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if tracker.checks != 1 || len(tracker.recorded) != 1 {
+		t.Errorf("Expected a cache hit to skip accounting, got %d checks and %d records", tracker.checks, len(tracker.recorded))
+	}
+}
+
+func TestChatService_AskRejectedByBudget(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize}}
+	svc := newTestService(plannerSvc, meteredStrategy{})
+	budgetErr := errors.New("daily LLM budget exceeded")
+	svc.SetUsageTracker(&mockTracker{checkErr: budgetErr})
+
+	if _, err := svc.Ask(context.Background(), chat.Request{Query: "summarize a"}); !errors.Is(err, budgetErr) {
+		t.Fatalf("Expected the budget error, got %v", err)
+	}
+	if plannerSvc.calls != 0 {
+		t.Errorf("Expected a rejected request not to be planned, got %d planner calls", plannerSvc.calls)
+	}
+}
+
+// mockResolver maps targets from a table, or reports them ambiguous.
+type mockResolver struct {
+	urls      map[string]string
+	ambiguous *resolver.AmbiguousError
+}
+
+func (m *mockResolver) Resolve(ctx context.Context, plan *planner.QueryPlan, history []models.Turn) error {
+	if m.ambiguous != nil {
+		return m.ambiguous
+	}
+	for i, target := range plan.Targets {
+		if url, ok := m.urls[target]; ok {
+			plan.Targets[i] = url
+		}
+	}
+	return nil
+}
+
+func TestChatService_AskResolvesTargets(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"the Tesla article"}}}
+	svc := newTestService(plannerSvc, &recordingStrategy{})
+	svc.SetTargetResolver(&mockResolver{urls: map[string]string{"the Tesla article": "https://example.com/tesla"}})
+
+	var planned []string
+	answer, err := svc.Ask(context.Background(), chat.Request{
+		Query:  "summarize the Tesla article",
+		OnPlan: func(plan *planner.QueryPlan) error { planned = plan.Targets; return nil },
+	})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if len(planned) != 1 || planned[0] != "https://example.com/tesla" || answer.Plan.Targets[0] != "https://example.com/tesla" {
+		t.Errorf("Expected the plan to run on the resolved URL, got %v", planned)
+	}
+	if _, ok := answer.LatencyMs["resolve"]; !ok {
+		t.Errorf("Expected the resolve stage to be timed, got %v", answer.LatencyMs)
+	}
+}
+
+func TestChatService_AskClarifiesAmbiguousTargets(t *testing.T) {
+	plannerSvc := &mockPlanner{plan: planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"the Intel article"}}}
+	strategy := &recordingStrategy{}
+	svc := newTestService(plannerSvc, strategy)
+	svc.SetTargetResolver(&mockResolver{ambiguous: &resolver.AmbiguousError{
+		Target: "the Intel article",
+		Candidates: []resolver.Candidate{
+			{URL: "https://example.com/intel-layoffs", Title: "Intel layoffs", Score: 1},
+			{URL: "https://example.com/intel-spinoff", Title: "Intel spins off its network group", Score: 1},
+		},
+	}})
+
+	answer, err := svc.Ask(context.Background(), chat.Request{Query: "summarize the Intel article"})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if len(strategy.intents) != 0 {
+		t.Errorf("Expected the plan not to run, got %v", strategy.intents)
+	}
+	if len(answer.Clarification) != 2 || !strings.Contains(answer.Answer, "1. Intel layoffs (https://example.com/intel-layoffs)") {
+		t.Errorf("Expected a question listing the candidates, got %q", answer.Answer)
+	}
+	// The candidates are the turn's targets, so that "the second one" can pick one.
+	if answer.Plan == nil || len(answer.Plan.Targets) != 2 || answer.Plan.Targets[1] != "https://example.com/intel-spinoff" {
+		t.Errorf("Expected the candidates as the plan's targets, got %+v", answer.Plan)
+	}
+
+	again, err := svc.Ask(context.Background(), chat.Request{Query: "summarize the Intel article"})
+	if err != nil {
+		t.Fatalf("Ask() error = %v", err)
+	}
+	if again.Cached || plannerSvc.calls != 2 {
+		t.Errorf("Expected a clarifying question not to be cached, got cached=%v planner calls=%d", again.Cached, plannerSvc.calls)
+	}
+}
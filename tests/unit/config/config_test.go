@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"article-chat-system/internal/config"
 )
@@ -211,3 +212,100 @@ func TestGetEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		setEnv   bool
+		expected int
+	}{
+		{name: "valid integer", envValue: "8", setEnv: true, expected: 8},
+		{name: "not set", setEnv: false, expected: 4},
+		{name: "invalid integer", envValue: "eight", setEnv: true, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Unsetenv("TEST_INT_VAR")
+
+			if tt.setEnv {
+				os.Setenv("TEST_INT_VAR", tt.envValue)
+			}
+
+			if result := config.GetEnvInt("TEST_INT_VAR", 4); result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		setEnv   bool
+		expected time.Duration
+	}{
+		{name: "valid duration", envValue: "250ms", setEnv: true, expected: 250 * time.Millisecond},
+		{name: "not set", setEnv: false, expected: time.Second},
+		{name: "invalid duration", envValue: "soon", setEnv: true, expected: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Unsetenv("TEST_DURATION_VAR")
+
+			if tt.setEnv {
+				os.Setenv("TEST_DURATION_VAR", tt.envValue)
+			}
+
+			if result := config.GetEnvDuration("TEST_DURATION_VAR", time.Second); result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	defer os.Unsetenv("TEST_LIST_VAR")
+	os.Setenv("TEST_LIST_VAR", "openai:gpt-4o-mini, ,mock ")
+
+	result := config.GetEnvList("TEST_LIST_VAR", nil)
+	if len(result) != 2 || result[0] != "openai:gpt-4o-mini" || result[1] != "mock" {
+		t.Errorf("Expected [openai:gpt-4o-mini mock], got %v", result)
+	}
+}
+
+func TestGetEnvIntMap(t *testing.T) {
+	defer os.Unsetenv("TEST_INT_MAP_VAR")
+	os.Setenv("TEST_INT_MAP_VAR", "gpt-4o=128000, llama3.1 = 32768,broken,bad=many")
+
+	result := config.GetEnvIntMap("TEST_INT_MAP_VAR")
+	if len(result) != 2 || result["gpt-4o"] != 128000 || result["llama3.1"] != 32768 {
+		t.Errorf("Expected gpt-4o=128000 and llama3.1=32768, got %v", result)
+	}
+}
+
+func TestGetEnvFloat(t *testing.T) {
+	defer os.Unsetenv("TEST_FLOAT_VAR")
+
+	os.Setenv("TEST_FLOAT_VAR", "2.50")
+	if result := config.GetEnvFloat("TEST_FLOAT_VAR", 1); result != 2.5 {
+		t.Errorf("Expected 2.5, got %g", result)
+	}
+	os.Setenv("TEST_FLOAT_VAR", "cheap")
+	if result := config.GetEnvFloat("TEST_FLOAT_VAR", 1); result != 1 {
+		t.Errorf("Expected the default 1 for an invalid value, got %g", result)
+	}
+}
+
+func TestGetEnvMap(t *testing.T) {
+	defer os.Unsetenv("TEST_MAP_VAR")
+	os.Setenv("TEST_MAP_VAR", "planner=openai:gpt-4o-mini, synthesis = anthropic ,broken,empty=")
+
+	result := config.GetEnvMap("TEST_MAP_VAR")
+	if len(result) != 2 || result["planner"] != "openai:gpt-4o-mini" || result["synthesis"] != "anthropic" {
+		t.Errorf("Expected planner and synthesis entries, got %v", result)
+	}
+}
@@ -0,0 +1,297 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"article-chat-system/internal/chat"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/resolver"
+	"article-chat-system/internal/session"
+	server "article-chat-system/internal/transport/grpc"
+	pb "article-chat-system/internal/transport/grpc/articlechatv1"
+	"article-chat-system/internal/usage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// mockArticleService implements article.Service; only the listing methods are used.
+type mockArticleService struct {
+	filter repository.ArticleFilter
+}
+
+func (m *mockArticleService) GetArticle(ctx context.Context, url string) (*models.Article, bool) {
+	return nil, false
+}
+func (m *mockArticleService) StoreArticle(ctx context.Context, article *models.Article) error {
+	return nil
+}
+func (m *mockArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	return false, nil
+}
+func (m *mockArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	m.filter = filter
+	if filter.Cursor != "" {
+		if _, _, err := repository.DecodeCursor(filter.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	return &repository.ArticlePage{
+		Articles:   []*models.Article{{URL: "https://example.com/a", Title: "A", Topics: []string{"ai"}}},
+		NextCursor: "next",
+	}, nil
+}
+func (m *mockArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
+	return "", nil
+}
+func (m *mockArticleService) FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error) {
+	return []repository.EntityCount{{Entity: "Intel", Count: 3}}, nil
+}
+func (m *mockArticleService) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	return nil, nil
+}
+
+// mockChatService plans SUMMARIZE and streams the answer word by word. It
+// asks which article was meant for "summarize the Intel article".
+type mockChatService struct{}
+
+func (m *mockChatService) Ask(ctx context.Context, req chat.Request) (*chat.Answer, error) {
+	if req.Query == "summarize the Intel article" {
+		return &chat.Answer{
+			Answer: "Which article do you mean?",
+			Plan:   &planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/intel-layoffs", "https://example.com/intel-spinoff"}},
+			Clarification: []resolver.Candidate{
+				{URL: "https://example.com/intel-layoffs", Title: "Intel layoffs", Score: 1},
+				{URL: "https://example.com/intel-spinoff", Title: "Intel spins off its network group", Score: 1},
+			},
+		}, nil
+	}
+	plan := &planner.QueryPlan{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/a"}}
+	if req.OnPlan != nil {
+		if err := req.OnPlan(plan); err != nil {
+			return nil, err
+		}
+	}
+	if onChunk, ok := llm.StreamHandlerFromContext(ctx); ok {
+		for _, chunk := range []string{"a ", "summary"} {
+			if err := onChunk(chunk); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &chat.Answer{
+		Answer:    "a summary",
+		Plan:      plan,
+		Sources:   []planner.Source{{URL: "https://example.com/a", Title: "A", Score: 1}},
+		Usage:     llm.Usage{TotalTokens: 42},
+		LatencyMs: map[string]int64{"total": 5},
+		CostUSD:   0.002,
+		Degraded:  true,
+		Steps: []planner.StepResult{
+			{ID: "step1", Intent: planner.IntentSummarize, Answer: "a summary", Sources: []planner.Source{{URL: "https://example.com/a", Title: "A", Score: 1}}},
+			{ID: "step2", Intent: planner.IntentKeywords, Error: "timed out after 30s"},
+		},
+	}, nil
+}
+func (m *mockChatService) Intents() []planner.QueryIntent { return nil }
+func (m *mockChatService) PromptVersion() string          { return "v1" }
+
+// mockSessionService knows a single session and records its turns.
+type mockSessionService struct {
+	turns []*models.Turn
+}
+
+func (m *mockSessionService) Start(ctx context.Context) (*models.Session, error) {
+	return &models.Session{ID: "new-session"}, nil
+}
+func (m *mockSessionService) Get(ctx context.Context, id string) (*models.Session, error) {
+	return nil, session.ErrNotFound
+}
+func (m *mockSessionService) List(ctx context.Context, limit int) ([]*models.Session, error) {
+	return nil, nil
+}
+func (m *mockSessionService) Delete(ctx context.Context, id string) error { return nil }
+func (m *mockSessionService) History(ctx context.Context, id string) ([]models.Turn, error) {
+	return nil, session.ErrNotFound
+}
+func (m *mockSessionService) Resume(ctx context.Context, id string) (string, []models.Turn, error) {
+	if id == "" {
+		return "new-session", nil, nil
+	}
+	return "", nil, session.ErrNotFound
+}
+func (m *mockSessionService) RecordTurn(ctx context.Context, id string, turn *models.Turn) error {
+	m.turns = append(m.turns, turn)
+	return nil
+}
+
+func newTestClient(t *testing.T, articleSvc *mockArticleService, sessionSvc *mockSessionService, apiKeys ...string) pb.ArticleChatServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := server.NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), articleSvc, &mockChatService{}, nil, sessionSvc)
+	srv.SetAPIKeys(usage.NewAPIKeys(apiKeys, []string{"admin-secret"}))
+	gs := srv.NewGRPCServer()
+	go gs.Serve(listener)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial test server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewArticleChatServiceClient(conn)
+}
+
+func TestServer_Chat(t *testing.T) {
+	sessions := &mockSessionService{}
+	client := newTestClient(t, &mockArticleService{}, sessions)
+
+	resp, err := client.Chat(context.Background(), &pb.ChatRequest{Query: "summarize a"})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.GetAnswer() != "a summary" || resp.GetSessionId() != "new-session" {
+		t.Errorf("Unexpected response: %v", resp)
+	}
+	if resp.GetPlan().GetIntent() != "SUMMARIZE" || len(resp.GetSources()) != 1 || resp.GetUsage().GetTotalTokens() != 42 {
+		t.Errorf("Expected plan, sources and usage in the response, got %v", resp)
+	}
+	if resp.GetCostUsd() != 0.002 || !resp.GetDegraded() {
+		t.Errorf("Expected the cost and degraded flag in the response, got %v", resp)
+	}
+	if steps := resp.GetSteps(); len(steps) != 2 || steps[0].GetAnswer() != "a summary" || len(steps[0].GetSources()) != 1 || steps[1].GetError() == "" {
+		t.Errorf("Expected the output of every step, got %v", steps)
+	}
+	if len(sessions.turns) != 1 || sessions.turns[0].Intent != "SUMMARIZE" {
+		t.Errorf("Expected the turn to be recorded, got %+v", sessions.turns)
+	}
+
+	resp, err = client.Chat(context.Background(), &pb.ChatRequest{Query: "summarize the Intel article"})
+	if err != nil {
+		t.Fatalf("Expected an ambiguous target to be answered with a question, got %v", err)
+	}
+	if c := resp.GetClarification(); len(c) != 2 || c[1].GetTitle() != "Intel spins off its network group" || len(resp.GetPlan().GetTargets()) != 2 {
+		t.Errorf("Expected the candidates in the response, got %v", resp)
+	}
+
+	_, err = client.Chat(context.Background(), &pb.ChatRequest{Query: "summarize a", SessionId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown session, got %v", err)
+	}
+	_, err = client.Chat(context.Background(), &pb.ChatRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty query, got %v", err)
+	}
+}
+
+func TestServer_AuthenticatesAPIKeys(t *testing.T) {
+	client := newTestClient(t, &mockArticleService{}, &mockSessionService{}, "secret")
+	withKey := func(key string, md ...string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), append([]string{"x-api-key", key}, md...)...)
+	}
+
+	if _, err := client.Chat(withKey("secret"), &pb.ChatRequest{Query: "summarize a"}); err != nil {
+		t.Errorf("Expected a known key to be accepted, got %v", err)
+	}
+	if _, err := client.Chat(withKey("guess"), &pb.ChatRequest{Query: "summarize a"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for an unknown key, got %v", err)
+	}
+	if _, err := client.Chat(withKey("secret", "x-user-id", "alice"), &pb.ChatRequest{Query: "summarize a"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for a user named by a regular key, got %v", err)
+	}
+	if _, err := client.Chat(withKey("admin-secret", "x-user-id", "alice"), &pb.ChatRequest{Query: "summarize a"}); err != nil {
+		t.Errorf("Expected an admin key to act for alice, got %v", err)
+	}
+	if _, err := client.Chat(context.Background(), &pb.ChatRequest{Query: "summarize a"}); err != nil {
+		t.Errorf("Expected anonymous calls to be accepted, got %v", err)
+	}
+}
+
+func TestServer_ChatStream(t *testing.T) {
+	client := newTestClient(t, &mockArticleService{}, &mockSessionService{})
+
+	stream, err := client.ChatStream(context.Background(), &pb.ChatRequest{Query: "summarize a"})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var kinds []string
+	var tokens string
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		switch e := event.GetEvent().(type) {
+		case *pb.ChatStreamEvent_Plan:
+			kinds = append(kinds, "plan")
+		case *pb.ChatStreamEvent_Token:
+			kinds = append(kinds, "token")
+			tokens += e.Token
+		case *pb.ChatStreamEvent_Done:
+			kinds = append(kinds, "done")
+			if e.Done.GetAnswer() != "a summary" {
+				t.Errorf("Expected final answer in done event, got %q", e.Done.GetAnswer())
+			}
+		}
+	}
+
+	want := []string{"plan", "token", "token", "done"}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("Expected events %v, got %v", want, kinds)
+		}
+	}
+	if tokens != "a summary" {
+		t.Errorf("Expected streamed tokens to form the answer, got %q", tokens)
+	}
+}
+
+func TestServer_ListArticlesAndFindEntities(t *testing.T) {
+	articleSvc := &mockArticleService{}
+	client := newTestClient(t, articleSvc, &mockSessionService{})
+
+	page, err := client.ListArticles(context.Background(), &pb.ListArticlesRequest{Topic: "ai", Source: "example.com", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	if len(page.GetArticles()) != 1 || page.GetNextCursor() != "next" {
+		t.Errorf("Unexpected page: %v", page)
+	}
+	if articleSvc.filter.Topic != "ai" || articleSvc.filter.SourceDomain != "example.com" || articleSvc.filter.Limit != 10 {
+		t.Errorf("Expected request fields to map onto the filter, got %+v", articleSvc.filter)
+	}
+
+	_, err = client.ListArticles(context.Background(), &pb.ListArticlesRequest{Cursor: "tampered"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a tampered cursor, got %v", err)
+	}
+
+	entities, err := client.FindEntities(context.Background(), &pb.FindEntitiesRequest{})
+	if err != nil {
+		t.Fatalf("FindEntities() error = %v", err)
+	}
+	if entities.GetCount() != 1 || entities.GetEntities()[0].GetEntity() != "Intel" {
+		t.Errorf("Unexpected entities: %v", entities)
+	}
+}
@@ -0,0 +1,96 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/health"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func failing(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestReadiness_AllHealthy(t *testing.T) {
+	svc := health.NewService(time.Second, 0,
+		health.Check{Name: "postgres", Critical: true, Probe: ok},
+		health.Check{Name: "weaviate", Capabilities: []string{"vector_search"}, Probe: ok},
+	)
+
+	report := svc.Readiness(context.Background())
+	if report.Status != health.StatusOK {
+		t.Errorf("Expected status ok, got %s", report.Status)
+	}
+	if len(report.Degraded) != 0 {
+		t.Errorf("Expected no degraded capabilities, got %v", report.Degraded)
+	}
+	if report.Components["weaviate"].Status != health.StatusOK {
+		t.Errorf("Expected weaviate ok, got %+v", report.Components["weaviate"])
+	}
+}
+
+func TestReadiness_NonCriticalFailureDegrades(t *testing.T) {
+	svc := health.NewService(time.Second, 0,
+		health.Check{Name: "postgres", Critical: true, Probe: ok},
+		health.Check{Name: "weaviate", Capabilities: []string{"vector_search"}, Probe: failing},
+		health.Check{Name: "llm", Capabilities: []string{"chat", "ingestion"}, Probe: failing},
+	)
+
+	report := svc.Readiness(context.Background())
+	if report.Status != health.StatusDegraded {
+		t.Errorf("Expected status degraded, got %s", report.Status)
+	}
+	if want := []string{"chat", "ingestion", "vector_search"}; !reflect.DeepEqual(report.Degraded, want) {
+		t.Errorf("Expected degraded capabilities %v, got %v", want, report.Degraded)
+	}
+	if report.Components["weaviate"].Error != "connection refused" {
+		t.Errorf("Expected the probe error to be reported, got %+v", report.Components["weaviate"])
+	}
+}
+
+func TestReadiness_CriticalFailureIsUnavailable(t *testing.T) {
+	svc := health.NewService(time.Second, 0,
+		health.Check{Name: "postgres", Critical: true, Probe: failing},
+		health.Check{Name: "weaviate", Capabilities: []string{"vector_search"}, Probe: failing},
+	)
+
+	if report := svc.Readiness(context.Background()); report.Status != health.StatusUnavailable {
+		t.Errorf("Expected status unavailable, got %s", report.Status)
+	}
+}
+
+func TestReadiness_ProbeTimeout(t *testing.T) {
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	svc := health.NewService(20*time.Millisecond, 0,
+		health.Check{Name: "llm", Capabilities: []string{"chat"}, Probe: hanging},
+	)
+
+	report := svc.Readiness(context.Background())
+	if report.Components["llm"].Status != health.StatusUnavailable {
+		t.Errorf("Expected a hanging probe to be reported unavailable, got %+v", report.Components["llm"])
+	}
+}
+
+func TestReadiness_CachesReport(t *testing.T) {
+	var calls atomic.Int32
+	counting := func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}
+	svc := health.NewService(time.Second, time.Minute,
+		health.Check{Name: "postgres", Critical: true, Probe: counting},
+	)
+
+	svc.Readiness(context.Background())
+	svc.Readiness(context.Background())
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected the report to be cached, probe ran %d times", got)
+	}
+}
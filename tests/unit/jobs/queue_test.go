@@ -0,0 +1,325 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/jobs"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/usage"
+)
+
+// mockJobRepository is an in-memory implementation of repository.JobRepository.
+type mockJobRepository struct {
+	mu    sync.Mutex
+	jobs  map[string]*models.Job
+	items []*models.JobItem
+}
+
+func newMockJobRepository() *mockJobRepository {
+	return &mockJobRepository{jobs: make(map[string]*models.Job)}
+}
+
+func (m *mockJobRepository) CreateJob(ctx context.Context, urls []string, user, apiKeyID string) (*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := &models.Job{ID: fmt.Sprintf("job-%d", len(m.jobs)+1), User: user, APIKeyID: apiKeyID}
+	for _, url := range urls {
+		item := &models.JobItem{ID: int64(len(m.items) + 1), JobID: job.ID, URL: url, Status: models.JobItemPending, User: user, APIKeyID: apiKeyID}
+		m.items = append(m.items, item)
+	}
+	m.jobs[job.ID] = job
+	return job, nil
+}
+
+func (m *mockJobRepository) FindJob(ctx context.Context, id string) (*models.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	found := &models.Job{ID: job.ID}
+	for _, item := range m.items {
+		if item.JobID == id {
+			found.Items = append(found.Items, *item)
+		}
+	}
+	found.Summarize()
+	return found, nil
+}
+
+func (m *mockJobRepository) ClaimItem(ctx context.Context, lease time.Duration) (*models.JobItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, item := range m.items {
+		if item.Status == models.JobItemPending && !item.NextAttemptAt.After(time.Now()) {
+			item.Status = models.JobItemProcessing
+			item.Attempts++
+			claimed := *item
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockJobRepository) CompleteItem(ctx context.Context, id int64, status models.JobItemStatus, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.items[id-1]
+	item.Status = status
+	item.LastError = lastError
+	return nil
+}
+
+func (m *mockJobRepository) RetryItem(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.items[id-1]
+	item.Status = models.JobItemPending
+	item.LastError = lastError
+	item.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (m *mockJobRepository) ReleaseItem(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := m.items[id-1]
+	item.Status = models.JobItemPending
+	item.Attempts--
+	return nil
+}
+
+// mockIngester fails or succeeds per URL according to its script, and
+// records who each article was ingested for.
+type mockIngester struct {
+	failures map[string]int // URL -> number of times to fail before succeeding
+	callers  []usage.Caller
+	mu       sync.Mutex
+}
+
+func (m *mockIngester) AddNewArticle(ctx context.Context, url string) (*models.Article, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callers = append(m.callers, usage.CallerFrom(ctx))
+	if url == "https://example.com/existing" {
+		return nil, fmt.Errorf("%w: %s", processing.ErrArticleExists, url)
+	}
+	if url == "https://example.com/over-budget" {
+		return nil, fmt.Errorf("%w: caller budget spent", usage.ErrBudgetExceeded)
+	}
+	if m.failures[url] > 0 {
+		m.failures[url]--
+		return nil, errors.New("fetch failed")
+	}
+	return &models.Article{URL: url}, nil
+}
+
+func TestQueue_ProcessesJob(t *testing.T) {
+	repo := newMockJobRepository()
+	ingester := &mockIngester{failures: map[string]int{
+		"https://example.com/flaky":  1,  // Succeeds on the second attempt
+		"https://example.com/broken": 10, // Never succeeds
+	}}
+
+	cfg := jobs.Config{
+		Workers:      2,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   5 * time.Millisecond,
+		PollInterval: time.Millisecond,
+		Lease:        time.Minute,
+		ItemTimeout:  time.Second,
+	}
+	queue := jobs.NewQueue(repo, ingester, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	job, err := queue.Enqueue(context.Background(), []string{
+		"https://example.com/ok",
+		"https://example.com/flaky",
+		"https://example.com/broken",
+		"https://example.com/existing",
+	})
+	if err != nil {
+		t.Fatalf("Enqueue() returned an unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Start(ctx)
+
+	deadline := time.Now().Add(5 * time.Second)
+	var got *models.Job
+	for time.Now().Before(deadline) {
+		got, err = queue.Get(context.Background(), job.ID)
+		if err != nil {
+			t.Fatalf("Get() returned an unexpected error: %v", err)
+		}
+		if got.Status != models.JobQueued && got.Status != models.JobRunning {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	queue.Wait()
+
+	if got.Status != models.JobCompletedWithErrors {
+		t.Fatalf("Expected status %s, got %s", models.JobCompletedWithErrors, got.Status)
+	}
+
+	expected := map[string]struct {
+		status   models.JobItemStatus
+		attempts int
+	}{
+		"https://example.com/ok":       {models.JobItemSucceeded, 1},
+		"https://example.com/flaky":    {models.JobItemSucceeded, 2},
+		"https://example.com/broken":   {models.JobItemFailed, 3},
+		"https://example.com/existing": {models.JobItemSkipped, 1},
+	}
+	for _, item := range got.Items {
+		want := expected[item.URL]
+		if item.Status != want.status || item.Attempts != want.attempts {
+			t.Errorf("%s: expected %s after %d attempts, got %s after %d", item.URL, want.status, want.attempts, item.Status, item.Attempts)
+		}
+	}
+}
+
+// blockingIngester blocks until its context is cancelled.
+type blockingIngester struct {
+	started chan struct{}
+}
+
+func (m *blockingIngester) AddNewArticle(ctx context.Context, url string) (*models.Article, error) {
+	close(m.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestQueue_ShutdownRequeuesItem(t *testing.T) {
+	repo := newMockJobRepository()
+	ingester := &blockingIngester{started: make(chan struct{})}
+	cfg := jobs.DefaultConfig()
+	cfg.Workers = 1
+	cfg.PollInterval = time.Millisecond
+	queue := jobs.NewQueue(repo, ingester, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	job, err := queue.Enqueue(context.Background(), []string{"https://example.com/slow"})
+	if err != nil {
+		t.Fatalf("Enqueue() returned an unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Start(ctx)
+	select {
+	case <-ingester.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The worker never started the item")
+	}
+	cancel()
+	queue.Wait()
+
+	got, err := queue.Get(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Get() returned an unexpected error: %v", err)
+	}
+	if item := got.Items[0]; item.Status != models.JobItemPending || item.Attempts != 0 || item.LastError != "" {
+		t.Errorf("Expected the interrupted item to be pending with no attempt counted, got %s after %d (%q)", item.Status, item.Attempts, item.LastError)
+	}
+}
+
+func TestQueue_ChargesTheEnqueuingCaller(t *testing.T) {
+	repo := newMockJobRepository()
+	ingester := &mockIngester{}
+	cfg := jobs.DefaultConfig()
+	cfg.Workers = 1
+	cfg.PollInterval = time.Millisecond
+	queue := jobs.NewQueue(repo, ingester, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	alice := usage.Caller{User: "alice", APIKeyID: usage.KeyID("alice-key")}
+	job, err := queue.Enqueue(usage.WithCaller(context.Background(), alice), []string{"https://example.com/ok"})
+	if err != nil {
+		t.Fatalf("Enqueue() returned an unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Start(ctx)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if got, _ := queue.Get(context.Background(), job.ID); got.Status == models.JobCompleted {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	queue.Wait()
+
+	if len(ingester.callers) != 1 || ingester.callers[0] != alice {
+		t.Errorf("Expected the article to be ingested for %+v, got %+v", alice, ingester.callers)
+	}
+}
+
+func TestQueue_FailsItemsOverBudgetWithoutRetrying(t *testing.T) {
+	repo := newMockJobRepository()
+	ingester := &mockIngester{}
+	cfg := jobs.DefaultConfig()
+	cfg.Workers = 1
+	cfg.PollInterval = time.Millisecond
+	cfg.MaxAttempts = 3
+	queue := jobs.NewQueue(repo, ingester, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	job, err := queue.Enqueue(context.Background(), []string{"https://example.com/over-budget"})
+	if err != nil {
+		t.Fatalf("Enqueue() returned an unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	queue.Start(ctx)
+	var got *models.Job
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if got, _ = queue.Get(context.Background(), job.ID); got.Status == models.JobFailed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	queue.Wait()
+
+	if got.Items[0].Status != models.JobItemFailed || len(ingester.callers) != 1 {
+		t.Errorf("Expected the item to fail after one attempt, got %+v after %d attempts", got.Items[0], len(ingester.callers))
+	}
+}
+
+func TestQueue_GetUnknownJob(t *testing.T) {
+	queue := jobs.NewQueue(newMockJobRepository(), &mockIngester{}, jobs.DefaultConfig(), slog.Default())
+	if _, err := queue.Get(context.Background(), "missing"); !errors.Is(err, jobs.ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base := 10 * time.Second
+	max := time.Minute
+
+	tests := []struct {
+		attempt int
+		nominal time.Duration
+	}{
+		{attempt: 1, nominal: 10 * time.Second},
+		{attempt: 2, nominal: 20 * time.Second},
+		{attempt: 3, nominal: 40 * time.Second},
+		{attempt: 4, nominal: time.Minute}, // Capped
+		{attempt: 10, nominal: time.Minute},
+	}
+
+	for _, tt := range tests {
+		delay := jobs.Backoff(tt.attempt, base, max)
+		// Jitter may shave off up to 20% of the nominal delay, never add to it.
+		if delay > tt.nominal || delay < tt.nominal*8/10 {
+			t.Errorf("attempt %d: expected delay in [%v, %v], got %v", tt.attempt, tt.nominal*8/10, tt.nominal, delay)
+		}
+	}
+}
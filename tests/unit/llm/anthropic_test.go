@@ -0,0 +1,283 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/planner"
+)
+
+type anthropicBlock struct {
+	Type         string `json:"type"`
+	Text         string `json:"text"`
+	CacheControl *struct {
+		Type string `json:"type"`
+	} `json:"cache_control"`
+}
+
+type anthropicRequest struct {
+	Model     string           `json:"model"`
+	MaxTokens int              `json:"max_tokens"`
+	Stream    bool             `json:"stream"`
+	System    []anthropicBlock `json:"system"`
+	Messages  []struct {
+		Role    string           `json:"role"`
+		Content []anthropicBlock `json:"content"`
+	} `json:"messages"`
+	StopSequences []string `json:"stop_sequences"`
+	Tools         []struct {
+		Name        string          `json:"name"`
+		InputSchema json.RawMessage `json:"input_schema"`
+	} `json:"tools"`
+	ToolChoice *struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"tool_choice"`
+}
+
+// newAnthropicServer stands in for the Messages API and hands every request
+// it receives to the test.
+func newAnthropicServer(t *testing.T, requests chan<- anthropicRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+			return
+		}
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if requests != nil {
+			requests <- req
+		}
+		if req.Model == "overloaded" {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(529)
+			fmt.Fprint(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+			return
+		}
+
+		if !req.Stream && len(req.Tools) > 0 {
+			fmt.Fprintf(w, `{"id":"msg_1","type":"message","role":"assistant","model":%q,
+				"content":[{"type":"tool_use","id":"toolu_1","name":%q,"input":{"intent":"SUMMARIZE","targets":[],"parameters":[]}}],
+				"usage":{"input_tokens":20,"output_tokens":9}}`, req.Model, req.Tools[0].Name)
+			return
+		}
+		if !req.Stream {
+			fmt.Fprintf(w, `{"id":"msg_1","type":"message","role":"assistant","model":%q,
+				"content":[{"type":"text","text":"Cached answer."}],
+				"usage":{"input_tokens":20,"output_tokens":3,"cache_creation_input_tokens":0,"cache_read_input_tokens":1500}}`, req.Model)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			fmt.Sprintf(`{"type":"message_start","message":{"model":%q,"usage":{"input_tokens":20,"output_tokens":1,"cache_creation_input_tokens":1500,"cache_read_input_tokens":0}}}`, req.Model),
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Streamed "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"answer."}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":4}}`,
+			`{"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			var typed struct{ Type string }
+			json.Unmarshal([]byte(event), &typed)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+}
+
+func newAnthropicClient(t *testing.T, baseURL, model string) llm.Client {
+	t.Helper()
+	client, err := llm.NewClientFactory(context.Background(), &config.Config{
+		LLMProvider:     "anthropic",
+		LLMModel:        model,
+		LLMBaseURL:      baseURL,
+		AnthropicAPIKey: "test-key",
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	return client
+}
+
+func TestAnthropicClient_MarksStaticPrefixCacheable(t *testing.T) {
+	requests := make(chan anthropicRequest, 1)
+	server := newAnthropicServer(t, requests)
+	defer server.Close()
+	client := newAnthropicClient(t, server.URL, "claude-test")
+
+	prompt := "Instructions" + llm.CacheBoundary + "Article context" + llm.CacheBoundary + "User query"
+	ctx, meter := llm.WithUsageMeter(context.Background())
+	resp, err := client.GenerateContent(ctx, prompt)
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+
+	req := <-requests
+	if len(req.Messages) != 1 || len(req.Messages[0].Content) != 3 {
+		t.Fatalf("Expected one user message with three blocks, got %+v", req.Messages)
+	}
+	blocks := req.Messages[0].Content
+	for i, cacheable := range []bool{true, true, false} {
+		if got := blocks[i].CacheControl != nil && blocks[i].CacheControl.Type == "ephemeral"; got != cacheable {
+			t.Errorf("Block %d (%q): expected cacheable=%v", i, blocks[i].Text, cacheable)
+		}
+		if strings.Contains(blocks[i].Text, llm.CacheBoundary) {
+			t.Errorf("Block %d still contains the boundary marker", i)
+		}
+	}
+	if req.MaxTokens == 0 {
+		t.Error("Expected max_tokens to be set")
+	}
+
+	want := llm.Usage{PromptTokens: 1520, CompletionTokens: 3, TotalTokens: 1523, CacheReadTokens: 1500}
+	if resp.Text != "Cached answer." || resp.Usage != want || meter.Usage() != want {
+		t.Errorf("Expected usage %+v, got %+v (meter %+v)", want, resp.Usage, meter.Usage())
+	}
+}
+
+func TestAnthropicClient_Generate_SystemPromptAndJSONPrefill(t *testing.T) {
+	requests := make(chan anthropicRequest, 1)
+	server := newAnthropicServer(t, requests)
+	defer server.Close()
+	client := newAnthropicClient(t, server.URL, "claude-test")
+
+	req := llm.NewRequest("Plan the query."+llm.CacheBoundary, "Article context"+llm.CacheBoundary+"User query")
+	req.ResponseFormat = llm.FormatJSON
+	req.MaxTokens = 512
+	req.Stop = []string{"}"}
+	resp, err := llm.Generate(context.Background(), client, req)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	got := <-requests
+	if len(got.System) != 1 || got.System[0].Text != "Plan the query." || got.System[0].CacheControl == nil {
+		t.Errorf("Expected a cacheable system block, got %+v", got.System)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "user" || len(got.Messages[0].Content) != 2 {
+		t.Fatalf("Expected the user turn and a prefill, got %+v", got.Messages)
+	}
+	if prefill := got.Messages[1]; prefill.Role != "assistant" || prefill.Content[0].Text != "{" {
+		t.Errorf("Expected the answer to be prefilled with '{', got %+v", prefill)
+	}
+	if got.MaxTokens != 512 || len(got.StopSequences) != 1 {
+		t.Errorf("Expected max_tokens 512 and one stop sequence, got %d and %q", got.MaxTokens, got.StopSequences)
+	}
+	if resp.Text != "{Cached answer." {
+		t.Errorf("Expected the prefill to lead the answer, got %q", resp.Text)
+	}
+}
+
+func TestAnthropicClient_StructuredOutputUsesForcedTool(t *testing.T) {
+	requests := make(chan anthropicRequest, 1)
+	server := newAnthropicServer(t, requests)
+	defer server.Close()
+	client := newAnthropicClient(t, server.URL, "claude-test")
+
+	var plan planner.QueryPlan
+	if _, err := llm.GenerateObject(context.Background(), client, llm.NewRequest("Plan it.", "summarize"), &plan, 0); err != nil {
+		t.Fatalf("GenerateObject() error = %v", err)
+	}
+	if plan.Intent != planner.IntentSummarize {
+		t.Errorf("Expected the tool input to be decoded as the plan, got %+v", plan)
+	}
+
+	got := <-requests
+	if len(got.Tools) != 1 || got.Tools[0].Name != "query_plan" || !strings.Contains(string(got.Tools[0].InputSchema), `"enum"`) {
+		t.Errorf("Expected a query_plan tool with the plan schema, got %+v", got.Tools)
+	}
+	if got.ToolChoice == nil || got.ToolChoice.Type != "tool" || got.ToolChoice.Name != "query_plan" {
+		t.Errorf("Expected the tool to be forced, got %+v", got.ToolChoice)
+	}
+	if last := got.Messages[len(got.Messages)-1]; last.Role != "user" {
+		t.Errorf("Expected no prefill alongside a forced tool, got a %s turn", last.Role)
+	}
+}
+
+func TestAnthropicClient_Generate_ToolCalls(t *testing.T) {
+	requests := make(chan anthropicRequest, 1)
+	server := newAnthropicServer(t, requests)
+	defer server.Close()
+	client := newAnthropicClient(t, server.URL, "claude-test")
+
+	req := llm.NewRequest("Call a tool.", "summarize a")
+	req.Tools = []llm.Tool{{Name: "summarize", Description: "Summarize an article", Parameters: llm.SchemaFor(struct {
+		Targets []string `json:"targets"`
+	}{})}}
+	resp, err := llm.Generate(context.Background(), client, req)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	got := <-requests
+	if len(got.Tools) != 1 || got.Tools[0].Name != "summarize" || got.ToolChoice != nil {
+		t.Errorf("Expected the tool to be offered without forcing it, got %+v / %+v", got.Tools, got.ToolChoice)
+	}
+	if resp.Text != "" || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "toolu_1" || resp.ToolCalls[0].Name != "summarize" {
+		t.Errorf("Expected the tool_use block as a tool call, got %q / %+v", resp.Text, resp.ToolCalls)
+	}
+}
+
+func TestAnthropicClient_StreamContent(t *testing.T) {
+	server := newAnthropicServer(t, nil)
+	defer server.Close()
+	client := newAnthropicClient(t, server.URL, "claude-test")
+
+	var chunks []string
+	resp, err := client.(llm.StreamingClient).StreamContent(context.Background(), "Summarize"+llm.CacheBoundary, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamContent() error = %v", err)
+	}
+	if strings.Join(chunks, "|") != "Streamed |answer." || resp.Text != "Streamed answer." {
+		t.Errorf("Unexpected stream: chunks %q, text %q", chunks, resp.Text)
+	}
+	want := llm.Usage{PromptTokens: 1520, CompletionTokens: 4, TotalTokens: 1524, CacheWriteTokens: 1500}
+	if resp.Usage != want {
+		t.Errorf("Expected usage %+v, got %+v", want, resp.Usage)
+	}
+}
+
+func TestAnthropicClient_OverloadIsTemporary(t *testing.T) {
+	server := newAnthropicServer(t, nil)
+	defer server.Close()
+	client := newAnthropicClient(t, server.URL, "overloaded")
+
+	_, err := client.GenerateContent(context.Background(), "prompt")
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error") {
+		t.Fatalf("Expected the overload error, got %v", err)
+	}
+	if !llm.IsTemporary(err) {
+		t.Error("Expected an overloaded provider to be retryable")
+	}
+}
+
+func TestAnthropicClient_RequiresAPIKey(t *testing.T) {
+	_, err := llm.NewClientFactory(context.Background(), &config.Config{LLMProvider: "anthropic"})
+	if err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY") {
+		t.Errorf("Expected a missing API key error, got %v", err)
+	}
+}
+
+func TestStripCacheBoundaries(t *testing.T) {
+	if got := llm.StripCacheBoundaries("static" + llm.CacheBoundary + "dynamic"); got != "staticdynamic" {
+		t.Errorf("Expected markers to be removed, got %q", got)
+	}
+}
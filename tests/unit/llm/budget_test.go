@@ -0,0 +1,128 @@
+package llm_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/llm"
+)
+
+func TestContextLimit(t *testing.T) {
+	overrides := map[string]int{"llama3.1": 32768}
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{model: "gpt-3.5-turbo", want: 16385},
+		{model: "gpt-4o-2024-08-06", want: 128000},
+		{model: "gpt-4", want: 8192},
+		{model: "claude-3-5-sonnet-latest", want: 200000},
+		{model: "llama3.1:8b", want: 32768},
+		{model: "llama3", want: 8192},
+		{model: "some-local-model", want: 8192},
+	}
+	for _, tt := range tests {
+		if got := llm.ContextLimit(tt.model, overrides); got != tt.want {
+			t.Errorf("ContextLimit(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTokenizer(t *testing.T) {
+	var tok llm.EstimateTokenizer
+	if n := tok.CountTokens("The cat sat on the mat."); n != 7 {
+		t.Errorf("Expected 6 words and a period to be 7 tokens, got %d", n)
+	}
+	if n := tok.CountTokens("internationalization"); n != 5 {
+		t.Errorf("Expected a 20-letter word to be 5 tokens, got %d", n)
+	}
+	if n := tok.CountTokens("東京都"); n != 3 {
+		t.Errorf("Expected one token per CJK character, got %d", n)
+	}
+}
+
+func TestTokenizerFor(t *testing.T) {
+	// Exact counts from the o200k and cl100k vocabularies.
+	if n := llm.TokenizerFor("gpt-4o-mini").CountTokens("The cat sat on the mat."); n != 7 {
+		t.Errorf("Expected gpt-4o-mini to count 7 tokens, got %d", n)
+	}
+	if n := llm.TokenizerFor("gpt-4").CountTokens("internationalization"); n != 2 {
+		t.Errorf("Expected gpt-4 to count 2 tokens, got %d", n)
+	}
+	if _, ok := llm.TokenizerFor("gpt-4o").(llm.TiktokenTokenizer); !ok {
+		t.Error("Expected OpenAI models to use tiktoken")
+	}
+	for _, model := range []string{"claude-3-5-sonnet-latest", "llama3.1:8b"} {
+		if _, ok := llm.TokenizerFor(model).(llm.EstimateTokenizer); !ok {
+			t.Errorf("Expected %s to fall back to the estimate", model)
+		}
+	}
+}
+
+func TestBudget_Fits(t *testing.T) {
+	budget := &llm.Budget{ContextTokens: 100, OutputTokens: 50, Tokenizer: llm.EstimateTokenizer{}}
+	short := llm.NewRequest("Summarize.", "A short article.")
+	// Two messages of 4 tokens overhead, "Summarize." is 3 tokens and "A short
+	// article." 5.
+	if !budget.Fits(short) || budget.Remaining(short) != 100-50-16 {
+		t.Errorf("Expected the short request to fit with 34 tokens left, got %d", budget.Remaining(short))
+	}
+	long := llm.NewRequest("Summarize.", strings.Repeat("word ", 60))
+	if budget.Fits(long) {
+		t.Errorf("Expected 60 words and a 50 token answer not to fit 100 tokens")
+	}
+	long.MaxTokens = 10
+	if !budget.Fits(long) {
+		t.Errorf("Expected the request's own MaxTokens to replace the reserved output")
+	}
+}
+
+func TestBudget_ChunkOverlapsParagraphs(t *testing.T) {
+	budget := llm.NewBudget("gpt-4o", nil)
+	var paras []string
+	for i := 0; i < 12; i++ {
+		paras = append(paras, fmt.Sprintf("Paragraph %d %s", i, strings.Repeat("text ", 8)))
+	}
+	chunks := budget.Chunk(strings.Join(paras, "\n"), 60, 15)
+
+	if len(chunks) < 3 {
+		t.Fatalf("Expected the 12 paragraphs to need several chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := budget.CountTokens(chunk); n > 60 {
+			t.Errorf("Chunk %d has %d tokens, above the limit of 60", i, n)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := strings.Split(chunks[i-1], "\n\n")
+		if first := strings.Split(chunk, "\n\n")[0]; first != prev[len(prev)-1] {
+			t.Errorf("Expected chunk %d to start with the last paragraph of the previous one, got %q", i, first)
+		}
+	}
+	if !strings.HasPrefix(chunks[len(chunks)-1], "Paragraph") || !strings.Contains(chunks[len(chunks)-1], "Paragraph 11") {
+		t.Errorf("Expected the last chunk to end with the last paragraph, got %q", chunks[len(chunks)-1])
+	}
+}
+
+func TestBudget_ChunkSplitsLongParagraphs(t *testing.T) {
+	budget := llm.NewBudget("gpt-4o", nil)
+	text := strings.Repeat("One short sentence here. ", 40)
+	chunks := budget.Chunk(text, 50, 0)
+
+	if len(chunks) < 4 {
+		t.Fatalf("Expected the 200 token paragraph to be split, got %d chunks", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := budget.CountTokens(chunk); n > 50 {
+			t.Errorf("Chunk %d has %d tokens, above the limit of 50", i, n)
+		}
+		if !strings.HasSuffix(chunk, ".") {
+			t.Errorf("Expected chunk %d to end between sentences, got %q", i, chunk)
+		}
+	}
+	if got := strings.Join(chunks, " "); got != strings.TrimSpace(text) {
+		t.Errorf("Expected the chunks to hold the whole text without overlap")
+	}
+}
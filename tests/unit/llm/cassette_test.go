@@ -0,0 +1,114 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+)
+
+func TestRecorder_RecordsAndReplays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "planner.json")
+	recorder, err := llm.WithRecorder(&answerScript{answers: []string{"Recorded answer."}}, path, "gpt-test", "v1")
+	if err != nil {
+		t.Fatalf("WithRecorder() error = %v", err)
+	}
+
+	req := llm.NewRequest("Be brief.", "Summarize the article.")
+	if _, err := llm.Generate(context.Background(), recorder, req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the cassette to be written: %v", err)
+	}
+	if !strings.Contains(string(data), llm.PromptHash(req)) || !strings.Contains(string(data), "Summarize the article.") {
+		t.Errorf("Expected the cassette to hold the prompt hash and request, got %s", data)
+	}
+
+	replay, err := llm.NewReplayClient(path, "gpt-test", "v1")
+	if err != nil {
+		t.Fatalf("NewReplayClient() error = %v", err)
+	}
+	var chunks []string
+	resp, err := llm.Stream(context.Background(), replay, llm.NewRequest("Be brief.", "Summarize the article."), func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if resp.Text != "Recorded answer." || strings.Join(chunks, "") != "Recorded answer." || len(chunks) != 2 {
+		t.Errorf("Expected the recorded answer word by word, got %q in %q", resp.Text, chunks)
+	}
+}
+
+func TestReplayClient_FailsOnUnknownPrompts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, _ := llm.WithRecorder(&answerScript{answers: []string{"ok"}}, path, "gpt-test", "v1")
+	if _, err := recorder.GenerateContent(context.Background(), "known prompt"); err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		prompt        string
+		model         string
+		promptVersion string
+	}{
+		{name: "different prompt", prompt: "unknown prompt", model: "gpt-test", promptVersion: "v1"},
+		{name: "different model", prompt: "known prompt", model: "gpt-other", promptVersion: "v1"},
+		{name: "different prompt version", prompt: "known prompt", model: "gpt-test", promptVersion: "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay, err := llm.NewReplayClient(path, tt.model, tt.promptVersion)
+			if err != nil {
+				t.Fatalf("NewReplayClient() error = %v", err)
+			}
+			_, err = replay.GenerateContent(context.Background(), tt.prompt)
+			var miss *llm.CassetteMissError
+			if !errors.As(err, &miss) || miss.Prompt != tt.prompt {
+				t.Errorf("Expected a CassetteMissError naming the prompt, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewClientFactory_Cassettes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	cfg := &config.Config{LLMProvider: "mock", LLMCassetteMode: llm.CassetteReplay, LLMCassette: path, PromptVersion: "v1"}
+	if _, err := llm.NewClientFactory(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "missing or empty") {
+		t.Errorf("Expected replay to refuse a missing cassette, got %v", err)
+	}
+
+	cfg.LLMCassetteMode = llm.CassetteRecord
+	recorder, err := llm.NewClientFactory(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	recorded, err := recorder.GenerateContent(context.Background(), "give me a summary")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+
+	cfg.LLMCassetteMode = llm.CassetteReplay
+	replay, err := llm.NewClientFactory(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	replayed, err := replay.GenerateContent(context.Background(), "give me a summary")
+	if err != nil || replayed.Text != recorded.Text {
+		t.Errorf("Expected the recorded answer %q, got %+v, %v", recorded.Text, replayed, err)
+	}
+
+	cfg.LLMCassetteMode = "rewind"
+	if _, err := llm.NewClientFactory(context.Background(), cfg); err == nil {
+		t.Error("Expected an unknown cassette mode to be rejected")
+	}
+}
@@ -50,7 +50,7 @@ func TestNewClientFactory(t *testing.T) {
 				OpenAIAPIKey: "test-api-key",
 			},
 			expectError: true,
-			errorMsg:    "unknown or unsupported LLM provider: unknown. Supported providers: openai, mock",
+			errorMsg:    "unknown or unsupported LLM provider: unknown. Supported providers: openai, openai-compatible, ollama, anthropic, mock",
 		},
 		{
 			name: "empty provider",
@@ -59,7 +59,7 @@ func TestNewClientFactory(t *testing.T) {
 				OpenAIAPIKey: "test-api-key",
 			},
 			expectError: true,
-			errorMsg:    "unknown or unsupported LLM provider: . Supported providers: openai, mock",
+			errorMsg:    "unknown or unsupported LLM provider: . Supported providers: openai, openai-compatible, ollama, anthropic, mock",
 		},
 	}
 
@@ -134,3 +134,23 @@ func TestNewClientFactory_OpenAIProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestPrimaryModel_KeepsModelCase(t *testing.T) {
+	tests := []struct {
+		provider string
+		model    string
+		want     string
+	}{
+		{provider: "OpenAI-Compatible:Qwen/Qwen2.5-72B-Instruct", want: "Qwen/Qwen2.5-72B-Instruct"},
+		{provider: "Ollama", want: "llama3.1"},
+		{provider: "OLLAMA", model: "Llama3.1:8B", want: "Llama3.1:8B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &config.Config{LLMProvider: tt.provider, LLMModel: tt.model}
+			if got := llm.PrimaryModel(cfg); got != tt.want {
+				t.Errorf("PrimaryModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+)
+
+// newMockFromFixture writes the fixture to a file and builds the mock
+// provider from it.
+func newMockFromFixture(t *testing.T, name, fixture string) (llm.Client, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	return llm.NewClientFactory(context.Background(), &config.Config{LLMProvider: "mock", LLMMockFixture: path})
+}
+
+func TestMockClient_BuiltInRules(t *testing.T) {
+	client, err := llm.NewClientFactory(context.Background(), &config.Config{LLMProvider: "mock"})
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+
+	plan := llm.NewRequest("Call tools.", "## Context: Available Articles\n- Intel (https://example.com/intel)\n\nsummarize it")
+	plan.Template = "planner"
+	plan.Tools = []llm.Tool{{Name: "summarize"}}
+	resp, err := llm.Generate(context.Background(), client, plan)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "summarize" || string(resp.ToolCalls[0].Arguments) != `{"targets": ["https://example.com/intel"]}` {
+		t.Errorf("Expected a summarize call on the article, got %+v", resp.ToolCalls)
+	}
+
+	chain := llm.NewRequest("Call tools.", "## User Query:\n\"Compare the articles about AI chips\"")
+	chain.Template = "planner"
+	resp, err = llm.Generate(context.Background(), client, chain)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 2 || string(resp.ToolCalls[0].Arguments) != `{"id": "found", "topic": "AI chips"}` || resp.ToolCalls[1].Name != "compare_multiple" {
+		t.Errorf("Expected a find_by_topic call feeding a comparison, got %+v", resp.ToolCalls)
+	}
+
+	resp, err = client.GenerateContent(context.Background(), "Please give me a summary")
+	if err != nil || !strings.HasPrefix(resp.Text, "This is a mock summary") {
+		t.Errorf("Expected the canned summary, got %+v, %v", resp, err)
+	}
+}
+
+func TestMockClient_FixtureRules(t *testing.T) {
+	client, err := newMockFromFixture(t, "fixture.yaml", `
+rules:
+  - name: outage
+    match: {url: "https://example.com/down"}
+    respond: {error: service unavailable, status: 503}
+    latency: 20ms
+  - name: echo-topic
+    match: {template: find_topic, regex: 'about (\w+)'}
+    respond: {template: "Articles about {{index .Matches 1}}: {{.URL}}"}
+  - name: fallback
+    respond: {text: static answer}
+`)
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+
+	req := llm.NewRequest("", "Find articles about AI, e.g. https://example.com/ai")
+	req.Template = "find_topic"
+	resp, err := llm.Generate(context.Background(), client, req)
+	if err != nil || resp.Text != "Articles about AI: https://example.com/ai" {
+		t.Errorf("Expected the templated answer, got %+v, %v", resp, err)
+	}
+
+	// The template condition fails without the template name.
+	resp, err = client.GenerateContent(context.Background(), "Find articles about AI")
+	if err != nil || resp.Text != "static answer" {
+		t.Errorf("Expected the fallback rule, got %+v, %v", resp, err)
+	}
+
+	start := time.Now()
+	_, err = client.GenerateContent(context.Background(), "Summarize https://example.com/down")
+	var perr *llm.ProviderError
+	if !errors.As(err, &perr) || perr.StatusCode != 503 || !strings.Contains(err.Error(), "service unavailable") {
+		t.Errorf("Expected a 503 provider error, got %v", err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Expected the rule's latency before the answer")
+	}
+}
+
+func TestMockClient_JSONFixture(t *testing.T) {
+	client, err := newMockFromFixture(t, "fixture.json", `{"rules": [{"name": "only", "match": {"regex": "ping"}, "respond": {"text": "pong"}}]}`)
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	if resp, err := client.GenerateContent(context.Background(), "ping"); err != nil || resp.Text != "pong" {
+		t.Errorf("Expected pong, got %+v, %v", resp, err)
+	}
+	if _, err := client.GenerateContent(context.Background(), "hello"); err == nil || !strings.Contains(err.Error(), "no mock rule matches") {
+		t.Errorf("Expected an error when no rule matches, got %v", err)
+	}
+}
+
+func TestMockClient_InvalidFixture(t *testing.T) {
+	_, err := newMockFromFixture(t, "fixture.yaml", `
+rules:
+  - name: ambiguous
+    respond: {text: a, error: b}
+`)
+	if err == nil || !strings.Contains(err.Error(), "rule 1 (ambiguous)") {
+		t.Errorf("Expected the invalid rule to be reported, got %v", err)
+	}
+}
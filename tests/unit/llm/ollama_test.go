@@ -0,0 +1,210 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+)
+
+// ollamaChatRequest is the part of an /api/chat request the tests inspect.
+type ollamaChatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+	Stream  bool           `json:"stream"`
+	Format  string         `json:"format"`
+	Options map[string]any `json:"options"`
+}
+
+// newOllamaServer stands in for an Ollama server answering every request with
+// the given words. The last request received is stored in last, if set.
+func newOllamaServer(t *testing.T, words []string, last ...*ollamaChatRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			fmt.Fprint(w, `{"models":[]}`)
+			return
+		}
+		if r.URL.Path != "/api/chat" || r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"bad request"}`, http.StatusBadRequest)
+			return
+		}
+		for _, dst := range last {
+			*dst = req
+		}
+		if req.Model != "llama3.1:8b" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":"model %q not found"}`, req.Model)
+			return
+		}
+
+		message := func(text string) map[string]any {
+			return map[string]any{"role": "assistant", "content": text}
+		}
+		enc := json.NewEncoder(w)
+		if !req.Stream {
+			enc.Encode(map[string]any{"model": req.Model, "message": message(strings.Join(words, "")), "done": true, "prompt_eval_count": 12, "eval_count": len(words)})
+			return
+		}
+		for _, word := range words {
+			enc.Encode(map[string]any{"model": req.Model, "message": message(word), "done": false})
+			w.(http.Flusher).Flush()
+		}
+		enc.Encode(map[string]any{"model": req.Model, "message": message(""), "done": true, "prompt_eval_count": 12, "eval_count": len(words)})
+	}))
+}
+
+func newOllamaClient(t *testing.T, baseURL, model string) llm.Client {
+	t.Helper()
+	client, err := llm.NewClientFactory(context.Background(), &config.Config{
+		LLMProvider: "ollama",
+		LLMModel:    model,
+		LLMBaseURL:  baseURL,
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	return client
+}
+
+func TestOllamaClient_GenerateContent(t *testing.T) {
+	server := newOllamaServer(t, []string{"Local ", "answer."})
+	defer server.Close()
+	client := newOllamaClient(t, server.URL, "llama3.1:8b")
+
+	ctx, meter := llm.WithUsageMeter(context.Background())
+	resp, err := client.GenerateContent(ctx, "Summarize the article")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if resp.Text != "Local answer." || resp.Model != "llama3.1:8b" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	want := llm.Usage{PromptTokens: 12, CompletionTokens: 2, TotalTokens: 14}
+	if resp.Usage != want || meter.Usage() != want {
+		t.Errorf("Expected usage %+v, got %+v (meter %+v)", want, resp.Usage, meter.Usage())
+	}
+}
+
+func TestOllamaClient_Generate_SendsMessagesAndOptions(t *testing.T) {
+	var got ollamaChatRequest
+	server := newOllamaServer(t, []string{`{"intent":"SUMMARIZE"}`}, &got)
+	defer server.Close()
+	client := newOllamaClient(t, server.URL, "llama3.1:8b")
+
+	req := llm.NewRequest("Plan the query."+llm.CacheBoundary, "summarize it")
+	req.ResponseFormat = llm.FormatJSON
+	req.MaxTokens = 256
+	req.Stop = []string{"\n\n"}
+	if _, err := llm.Generate(context.Background(), client, req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[0].Content != "Plan the query." ||
+		got.Messages[1].Role != "user" || got.Messages[1].Content != "summarize it" {
+		t.Errorf("Unexpected messages: %+v", got.Messages)
+	}
+	if got.Format != "json" {
+		t.Errorf("Expected JSON format, got %q", got.Format)
+	}
+	// num_ctx is the window the budget assumes for llama3.
+	if got.Options["num_predict"] != float64(256) || got.Options["temperature"] != float64(0) || got.Options["num_ctx"] != float64(8192) {
+		t.Errorf("Unexpected options: %v", got.Options)
+	}
+	if stop, _ := got.Options["stop"].([]any); len(stop) != 1 || stop[0] != "\n\n" {
+		t.Errorf("Expected the stop sequence to be sent, got %v", got.Options["stop"])
+	}
+}
+
+func TestOllamaClient_UsesItsOwnBaseURL(t *testing.T) {
+	server := newOllamaServer(t, []string{"Local ", "answer."})
+	defer server.Close()
+
+	// LLM_BASE_URL belongs to the Anthropic primary, not to a role served
+	// by Ollama.
+	router, err := llm.NewRouterFactory(context.Background(), &config.Config{
+		LLMProvider:     "anthropic",
+		LLMBaseURL:      "http://127.0.0.1:1",
+		AnthropicAPIKey: "test-key",
+		OllamaBaseURL:   server.URL,
+		LLMRoleModels:   map[string]string{"planner": "ollama:llama3.1:8b"},
+	})
+	if err != nil {
+		t.Fatalf("NewRouterFactory() error = %v", err)
+	}
+	resp, err := router.Client(llm.RolePlanner).GenerateContent(context.Background(), "Plan the query")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if resp.Text != "Local answer." {
+		t.Errorf("Expected the answer of the Ollama server, got %q", resp.Text)
+	}
+}
+
+func TestOllamaClient_StreamContent(t *testing.T) {
+	words := []string{"One ", "token ", "at ", "a ", "time."}
+	server := newOllamaServer(t, words)
+	defer server.Close()
+	client := newOllamaClient(t, server.URL, "llama3.1:8b")
+
+	streamer, ok := client.(llm.StreamingClient)
+	if !ok {
+		t.Fatal("Expected the ollama client to support streaming")
+	}
+	var chunks []string
+	resp, err := streamer.StreamContent(context.Background(), "Summarize the article", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamContent() error = %v", err)
+	}
+	if strings.Join(chunks, "|") != strings.Join(words, "|") {
+		t.Errorf("Expected chunks %q, got %q", words, chunks)
+	}
+	if resp.Text != "One token at a time." || resp.Usage.CompletionTokens != len(words) {
+		t.Errorf("Unexpected assembled response: %+v", resp)
+	}
+}
+
+func TestOllamaClient_ReportsServerErrors(t *testing.T) {
+	server := newOllamaServer(t, nil)
+	defer server.Close()
+	client := newOllamaClient(t, server.URL, "missing-model")
+
+	_, err := client.GenerateContent(context.Background(), "prompt")
+	if err == nil || !strings.Contains(err.Error(), `model "missing-model" not found`) {
+		t.Fatalf("Expected the server's error message, got %v", err)
+	}
+	if llm.IsTemporary(err) {
+		t.Error("Expected an unknown model not to be retried")
+	}
+}
+
+func TestOllamaClient_Ping(t *testing.T) {
+	server := newOllamaServer(t, nil)
+	client := newOllamaClient(t, server.URL, "llama3.1:8b")
+	checker := client.(llm.HealthChecker)
+
+	if err := checker.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	server.Close()
+	if err := checker.Ping(context.Background()); err == nil {
+		t.Error("Expected Ping to fail once the server is gone")
+	}
+}
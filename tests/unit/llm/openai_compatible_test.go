@@ -0,0 +1,271 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+)
+
+// compatibleRequest is the part of a chat completion request the tests inspect.
+type compatibleRequest struct {
+	Model    string `json:"model"`
+	Stream   bool   `json:"stream"`
+	Messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+	MaxTokens      int      `json:"max_tokens"`
+	Stop           []string `json:"stop"`
+	ResponseFormat *struct {
+		Type string `json:"type"`
+	} `json:"response_format"`
+	Tools []struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name       string          `json:"name"`
+			Parameters json.RawMessage `json:"parameters"`
+		} `json:"function"`
+	} `json:"tools"`
+}
+
+// newCompatibleServer stands in for a llama.cpp or vLLM server exposing the
+// OpenAI API under /v1. The last request received is stored in last, if set.
+func newCompatibleServer(t *testing.T, words []string, last ...*compatibleRequest) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"object":"list","data":[{"id":"qwen2.5-7b","object":"model"}]}`)
+	})
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var req compatibleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		for _, dst := range last {
+			*dst = req
+		}
+		usage := map[string]int{"prompt_tokens": 9, "completion_tokens": len(words), "total_tokens": 9 + len(words)}
+
+		if !req.Stream && len(req.Tools) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			call := map[string]any{"id": "call_1", "type": "function", "function": map[string]string{
+				"name": req.Tools[0].Function.Name, "arguments": `{"targets":["https://example.com/a"]}`,
+			}}
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": "cmpl-1", "object": "chat.completion", "model": req.Model,
+				"choices": []map[string]any{{"index": 0, "message": map[string]any{"role": "assistant", "content": "", "tool_calls": []any{call}}, "finish_reason": "tool_calls"}},
+				"usage":   usage,
+			})
+			return
+		}
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": "cmpl-1", "object": "chat.completion", "model": req.Model,
+				"choices": []map[string]any{{"index": 0, "message": map[string]string{"role": "assistant", "content": strings.Join(words, "")}, "finish_reason": "stop"}},
+				"usage":   usage,
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		send := func(v any) {
+			data, _ := json.Marshal(v)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			w.(http.Flusher).Flush()
+		}
+		for _, word := range words {
+			send(map[string]any{"id": "cmpl-1", "object": "chat.completion.chunk", "model": req.Model,
+				"choices": []map[string]any{{"index": 0, "delta": map[string]string{"content": word}}}})
+		}
+		send(map[string]any{"id": "cmpl-1", "object": "chat.completion.chunk", "model": req.Model, "choices": []any{}, "usage": usage})
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	return httptest.NewServer(mux)
+}
+
+func newCompatibleClient(t *testing.T, baseURL string) llm.Client {
+	t.Helper()
+	client, err := llm.NewClientFactory(context.Background(), &config.Config{
+		LLMProvider: "openai-compatible",
+		LLMModel:    "qwen2.5-7b",
+		LLMBaseURL:  baseURL + "/v1",
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	return client
+}
+
+func TestOpenAICompatibleClient_GenerateContent(t *testing.T) {
+	server := newCompatibleServer(t, []string{"Self-hosted ", "answer."})
+	defer server.Close()
+	client := newCompatibleClient(t, server.URL)
+
+	resp, err := client.GenerateContent(context.Background(), "Summarize the article")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if resp.Text != "Self-hosted answer." || resp.Model != "qwen2.5-7b" || resp.Usage.TotalTokens != 11 {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestOpenAICompatibleClient_Generate_SendsRolesAndOptions(t *testing.T) {
+	var got compatibleRequest
+	server := newCompatibleServer(t, []string{"{}"}, &got)
+	defer server.Close()
+	client := newCompatibleClient(t, server.URL)
+
+	req := llm.NewRequest("You are a planner.", "Article text"+llm.CacheBoundary)
+	req.Messages = append(req.Messages, llm.Message{Role: llm.RoleAssistant, Content: "Earlier answer"}, llm.Message{Role: llm.RoleUser, Content: "And now?"})
+	req.ResponseFormat = llm.FormatJSON
+	req.MaxTokens = 128
+	req.Stop = []string{"END"}
+	if _, err := llm.Generate(context.Background(), client, req); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var roles []string
+	for _, msg := range got.Messages {
+		roles = append(roles, msg.Role)
+	}
+	if strings.Join(roles, ",") != "system,user,assistant,user" {
+		t.Errorf("Expected system, user, assistant, user messages, got %v", roles)
+	}
+	if got.Messages[1].Content != "Article text" {
+		t.Errorf("Expected the cache boundary to be stripped, got %q", got.Messages[1].Content)
+	}
+	if got.ResponseFormat == nil || got.ResponseFormat.Type != "json_object" {
+		t.Errorf("Expected the json_object response format, got %+v", got.ResponseFormat)
+	}
+	if got.MaxTokens != 128 || len(got.Stop) != 1 || got.Stop[0] != "END" {
+		t.Errorf("Expected max_tokens 128 and stop [END], got %d and %q", got.MaxTokens, got.Stop)
+	}
+}
+
+func TestOpenAICompatibleClient_GenerateObject_FallsBackToJSONObject(t *testing.T) {
+	type headline struct {
+		Headline string `json:"headline"`
+	}
+	tests := []struct {
+		model  string
+		format string
+	}{
+		{model: "qwen2.5-7b", format: "json_schema"},
+		{model: "gpt-4o-mini", format: "json_schema"},
+		{model: "gpt-3.5-turbo", format: "json_object"},
+		{model: "openai/gpt-4-turbo", format: "json_object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			var got compatibleRequest
+			server := newCompatibleServer(t, []string{`{"headline": `, `"Intel layoffs"}`}, &got)
+			defer server.Close()
+			client, err := llm.NewClientFactory(context.Background(), &config.Config{
+				LLMProvider: "openai-compatible",
+				LLMModel:    tt.model,
+				LLMBaseURL:  server.URL + "/v1",
+			})
+			if err != nil {
+				t.Fatalf("NewClientFactory() error = %v", err)
+			}
+
+			var out headline
+			if _, err := llm.GenerateObject(context.Background(), client, llm.NewRequest("Summarize.", "Article text"), &out, 0); err != nil {
+				t.Fatalf("GenerateObject() error = %v", err)
+			}
+			if out.Headline != "Intel layoffs" {
+				t.Errorf("Unexpected object: %+v", out)
+			}
+			if got.ResponseFormat == nil || got.ResponseFormat.Type != tt.format {
+				t.Fatalf("Expected the %s response format, got %+v", tt.format, got.ResponseFormat)
+			}
+			if withSchema := strings.Contains(got.Messages[0].Content, `"headline"`); withSchema != (tt.format == "json_object") {
+				t.Errorf("Expected the schema in the system message only without json_schema, got %q", got.Messages[0].Content)
+			}
+		})
+	}
+
+	// Without json_schema, answers are still checked against the schema.
+	server := newCompatibleServer(t, []string{`{"headline": 3}`})
+	defer server.Close()
+	client, err := llm.NewClientFactory(context.Background(), &config.Config{
+		LLMProvider: "openai-compatible",
+		LLMModel:    "gpt-3.5-turbo",
+		LLMBaseURL:  server.URL + "/v1",
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+	var out headline
+	var outErr *llm.OutputError
+	if _, err := llm.GenerateObject(context.Background(), client, llm.NewRequest("Summarize.", "Article text"), &out, 0); !errors.As(err, &outErr) {
+		t.Errorf("Expected an OutputError for an answer that breaks the schema, got %v", err)
+	}
+}
+
+func TestOpenAICompatibleClient_Generate_ToolCalls(t *testing.T) {
+	var got compatibleRequest
+	server := newCompatibleServer(t, nil, &got)
+	defer server.Close()
+	client := newCompatibleClient(t, server.URL)
+
+	req := llm.NewRequest("Call a tool.", "summarize a")
+	req.Tools = []llm.Tool{{Name: "summarize", Description: "Summarize an article", Parameters: llm.SchemaFor(struct {
+		Targets []string `json:"targets"`
+	}{})}}
+	resp, err := llm.Generate(context.Background(), client, req)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(got.Tools) != 1 || got.Tools[0].Type != "function" || got.Tools[0].Function.Name != "summarize" || !strings.Contains(string(got.Tools[0].Function.Parameters), `"targets"`) {
+		t.Errorf("Expected the tool to be sent as a function, got %+v", got.Tools)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("Expected one tool call, got %+v", resp.ToolCalls)
+	}
+	call := resp.ToolCalls[0]
+	if call.ID != "call_1" || call.Name != "summarize" || string(call.Arguments) != `{"targets":["https://example.com/a"]}` {
+		t.Errorf("Unexpected tool call: %+v", call)
+	}
+}
+
+func TestOpenAICompatibleClient_StreamContent(t *testing.T) {
+	words := []string{"Streamed ", "from ", "vLLM."}
+	server := newCompatibleServer(t, words)
+	defer server.Close()
+	client := newCompatibleClient(t, server.URL)
+
+	var chunks []string
+	resp, err := client.(llm.StreamingClient).StreamContent(context.Background(), "prompt", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamContent() error = %v", err)
+	}
+	if len(chunks) != len(words) || resp.Text != "Streamed from vLLM." {
+		t.Errorf("Expected chunks %q, got %q (text %q)", words, chunks, resp.Text)
+	}
+	if resp.Usage.CompletionTokens != len(words) {
+		t.Errorf("Expected usage from the final chunk, got %+v", resp.Usage)
+	}
+}
+
+func TestOpenAICompatibleClient_RequiresBaseURL(t *testing.T) {
+	_, err := llm.NewClientFactory(context.Background(), &config.Config{LLMProvider: "openai-compatible"})
+	if err == nil || !strings.Contains(err.Error(), "LLM_BASE_URL") {
+		t.Errorf("Expected a missing base URL error, got %v", err)
+	}
+}
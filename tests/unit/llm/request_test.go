@@ -0,0 +1,54 @@
+package llm_test
+
+import (
+	"context"
+	"testing"
+
+	"article-chat-system/internal/llm"
+)
+
+// promptOnlyClient accepts only a flat prompt and records it.
+type promptOnlyClient struct {
+	prompt string
+}
+
+func (c *promptOnlyClient) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	c.prompt = prompt
+	return &llm.Response{Text: "ok"}, nil
+}
+
+func TestNewRequest(t *testing.T) {
+	req := llm.NewRequest("Be brief.", "Summarize this.")
+	if len(req.Messages) != 2 || req.Messages[0].Role != llm.RoleSystem || req.Messages[1].Role != llm.RoleUser {
+		t.Fatalf("Expected a system and a user message, got %+v", req.Messages)
+	}
+	if got := req.Prompt(); got != "Be brief.\n\nSummarize this." {
+		t.Errorf("Unexpected flattened prompt %q", got)
+	}
+
+	if req := llm.NewRequest("", "Summarize this."); len(req.Messages) != 1 || req.Prompt() != "Summarize this." {
+		t.Errorf("Expected an empty system part to be left out, got %+v", req.Messages)
+	}
+}
+
+func TestGenerate_FlattensForPromptOnlyClients(t *testing.T) {
+	client := &promptOnlyClient{}
+	if _, err := llm.Generate(context.Background(), client, llm.NewRequest("Be brief.", "Summarize this.")); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if client.prompt != "Be brief.\n\nSummarize this." {
+		t.Errorf("Expected the flattened prompt, got %q", client.prompt)
+	}
+
+	var chunks []string
+	resp, err := llm.Stream(context.Background(), client, llm.NewRequest("", "Summarize this."), func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(chunks) != 1 || chunks[0] != resp.Text {
+		t.Errorf("Expected the whole answer as one chunk, got %q", chunks)
+	}
+}
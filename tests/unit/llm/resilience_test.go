@@ -0,0 +1,238 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/llm"
+)
+
+// scriptedClient returns the scripted errors in order, then succeeds.
+type scriptedClient struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+	text  string
+	model string // Reported model; "scripted" when empty
+}
+
+func (c *scriptedClient) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return nil, err
+	}
+	model := c.model
+	if model == "" {
+		model = "scripted"
+	}
+	return &llm.Response{Text: c.text, Model: model}, nil
+}
+
+func (c *scriptedClient) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func httpError(status int) error {
+	return &llm.ProviderError{Provider: "test", StatusCode: status, Err: errors.New(http.StatusText(status))}
+}
+
+var fastRetries = llm.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestWithRetry_RetriesTemporaryErrors(t *testing.T) {
+	inner := &scriptedClient{errs: []error{httpError(429), httpError(503)}, text: "ok"}
+	client := llm.WithRetry(inner, fastRetries)
+
+	resp, err := client.GenerateContent(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if resp.Text != "ok" || inner.callCount() != 3 {
+		t.Errorf("Expected success on the third attempt, got %q after %d calls", resp.Text, inner.callCount())
+	}
+}
+
+func TestWithRetry_DoesNotRetryBadRequests(t *testing.T) {
+	inner := &scriptedClient{errs: []error{httpError(400)}}
+	client := llm.WithRetry(inner, fastRetries)
+
+	if _, err := client.GenerateContent(context.Background(), "prompt"); err == nil {
+		t.Fatal("Expected the bad request error to be returned")
+	}
+	if inner.callCount() != 1 {
+		t.Errorf("Expected a single attempt, got %d", inner.callCount())
+	}
+}
+
+func TestWithRetry_HonorsRetryAfter(t *testing.T) {
+	limited := &llm.ProviderError{Provider: "test", StatusCode: 429, RetryAfter: 50 * time.Millisecond, Err: errors.New("slow down")}
+	inner := &scriptedClient{errs: []error{limited}, text: "ok"}
+	client := llm.WithRetry(inner, llm.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 100 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := client.GenerateContent(context.Background(), "prompt"); err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected to wait for Retry-After, retried after %s", elapsed)
+	}
+
+	// A Retry-After beyond MaxDelay fails fast instead of waiting.
+	inner = &scriptedClient{errs: []error{limited}, text: "ok"}
+	client = llm.WithRetry(inner, fastRetries)
+	start = time.Now()
+	if _, err := client.GenerateContent(context.Background(), "prompt"); !errors.Is(err, limited) {
+		t.Fatalf("Expected the rate limit error, got %v", err)
+	}
+	if elapsed := time.Since(start); inner.callCount() != 1 || elapsed >= 50*time.Millisecond {
+		t.Errorf("Expected no retry, got %d calls after %s", inner.callCount(), elapsed)
+	}
+}
+
+func TestWithCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	inner := &scriptedClient{errs: []error{httpError(500), httpError(500)}, text: "ok"}
+	client := llm.WithCircuitBreaker(inner, "test", llm.BreakerConfig{FailureThreshold: 2, Cooldown: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	client.GenerateContent(ctx, "prompt")
+	client.GenerateContent(ctx, "prompt")
+	if _, err := client.GenerateContent(ctx, "prompt"); !errors.Is(err, llm.ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after two failures, got %v", err)
+	}
+	if inner.callCount() != 2 {
+		t.Errorf("Expected the open circuit not to call the provider, got %d calls", inner.callCount())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, err := client.GenerateContent(ctx, "prompt"); err != nil {
+		t.Fatalf("Expected the trial call after the cooldown to succeed, got %v", err)
+	}
+	if _, err := client.GenerateContent(ctx, "prompt"); err != nil {
+		t.Errorf("Expected the circuit to close after a successful trial, got %v", err)
+	}
+}
+
+func TestWithFallback_MarksDegradedAnswers(t *testing.T) {
+	primary := &scriptedClient{errs: []error{httpError(503)}}
+	lastResort := &scriptedClient{text: "canned"}
+	client := llm.WithFallback(
+		llm.Fallback{Name: "primary", Client: primary},
+		llm.Fallback{Name: "mock", Client: lastResort, Degraded: true},
+	)
+
+	ctx, meter := llm.WithUsageMeter(context.Background())
+	resp, err := client.GenerateContent(ctx, "prompt")
+	if err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if resp.Text != "canned" || !resp.Degraded || !meter.Degraded() {
+		t.Errorf("Expected a degraded answer from the last resort, got %+v (meter degraded=%v)", resp, meter.Degraded())
+	}
+}
+
+func TestWithFallback_ReportsAllFailures(t *testing.T) {
+	client := llm.WithFallback(
+		llm.Fallback{Name: "primary", Client: &scriptedClient{errs: []error{httpError(500)}}},
+		llm.Fallback{Name: "secondary", Client: &scriptedClient{errs: []error{httpError(502)}}},
+	)
+
+	_, err := client.GenerateContent(context.Background(), "prompt")
+	if err == nil || !strings.Contains(err.Error(), "primary") || !strings.Contains(err.Error(), "secondary") {
+		t.Errorf("Expected both provider errors, got %v", err)
+	}
+}
+
+// brokenStream emits one chunk and then fails.
+type brokenStream struct{ scriptedClient }
+
+func (c *brokenStream) StreamContent(ctx context.Context, prompt string, onChunk llm.StreamHandler) (*llm.Response, error) {
+	onChunk("partial")
+	return nil, httpError(502)
+}
+
+func TestWithFallback_KeepsPartialStreams(t *testing.T) {
+	secondary := &scriptedClient{text: "other"}
+	client := llm.WithFallback(
+		llm.Fallback{Name: "primary", Client: llm.WithRetry(&brokenStream{}, fastRetries)},
+		llm.Fallback{Name: "secondary", Client: secondary},
+	)
+
+	var chunks []string
+	_, err := client.(llm.StreamingClient).StreamContent(context.Background(), "prompt", func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected the failed stream to be reported")
+	}
+	if len(chunks) != 1 || secondary.callCount() != 0 {
+		t.Errorf("Expected no retry or fallback after a chunk was sent, got chunks %v and %d fallback calls", chunks, secondary.callCount())
+	}
+}
+
+// slowClient tracks how many calls run at once.
+type slowClient struct {
+	inFlight, peak atomic.Int32
+}
+
+func (c *slowClient) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return &llm.Response{Text: "ok"}, nil
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	inner := &slowClient{}
+	client := llm.WithConcurrencyLimit(inner, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GenerateContent(context.Background(), "prompt")
+		}()
+	}
+	wg.Wait()
+
+	if peak := inner.peak.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 calls in flight, saw %d", peak)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 7, 27, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-1", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{"later", 0},
+	}
+	for _, tt := range tests {
+		if got := llm.ParseRetryAfter(tt.value, now); got != tt.expected {
+			t.Errorf("ParseRetryAfter(%q) = %s, want %s", tt.value, got, tt.expected)
+		}
+	}
+}
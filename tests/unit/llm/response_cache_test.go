@@ -0,0 +1,203 @@
+package llm_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/llm"
+)
+
+// memoryStore is an in-memory llm.ResponseStore.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*llm.CacheEntry
+	prunes  int
+	cutoff  time.Time // Of the last prune
+	max     int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string]*llm.CacheEntry)}
+}
+
+func (s *memoryStore) GetResponse(ctx context.Context, key string) (*llm.CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[key], nil
+}
+
+func (s *memoryStore) PutResponse(ctx context.Context, entry *llm.CacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.Key] = entry
+	return nil
+}
+
+func (s *memoryStore) PruneResponses(ctx context.Context, cutoff time.Time, maxEntries int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prunes++
+	s.cutoff, s.max = cutoff, maxEntries
+	return 0, nil
+}
+
+func newCachedClient(script *answerScript, store llm.ResponseStore, ttl time.Duration) llm.Client {
+	return llm.WithResponseCache(script, store, llm.ResponseCacheConfig{Model: "gpt-4o", PromptVersion: "v1", TTL: ttl})
+}
+
+func TestResponseCache_ServesRepeatedRequests(t *testing.T) {
+	script := &answerScript{answers: []string{"first", "second"}}
+	store := newMemoryStore()
+	client := newCachedClient(script, store, time.Hour)
+	ctx := context.Background()
+
+	first, err := llm.Generate(ctx, client, llm.NewRequest("Summarize.", "article"))
+	if err != nil || first.Text != "first" || first.Cached {
+		t.Fatalf("Expected a fresh first answer, got %+v, %v", first, err)
+	}
+	again, err := llm.Generate(ctx, client, llm.NewRequest("Summarize.", "article"))
+	if err != nil || again.Text != "first" || !again.Cached {
+		t.Errorf("Expected the cached answer, got %+v, %v", again, err)
+	}
+	other, _ := llm.Generate(ctx, client, llm.NewRequest("Summarize.", "another article"))
+	if other.Text != "second" || other.Cached {
+		t.Errorf("Expected a different prompt to reach the provider, got %+v", other)
+	}
+	if len(script.requests) != 2 || len(store.entries) != 2 || store.prunes != 0 {
+		t.Errorf("Expected 2 provider calls, 2 entries and no prune, got %d, %d, %d",
+			len(script.requests), len(store.entries), store.prunes)
+	}
+
+	var streamed string
+	resp, err := llm.Stream(ctx, client, llm.NewRequest("Summarize.", "article"), func(chunk string) error {
+		streamed += chunk
+		return nil
+	})
+	if err != nil || streamed != "first" || !resp.Cached || len(script.requests) != 2 {
+		t.Errorf("Expected the cached answer to be streamed, got %q, %v", streamed, err)
+	}
+}
+
+func TestResponseCache_KeysOnModelAndOptions(t *testing.T) {
+	script := &answerScript{answers: []string{"a", "b", "c"}}
+	store := newMemoryStore()
+	ctx := context.Background()
+
+	llm.Generate(ctx, newCachedClient(script, store, 0), llm.NewRequest("", "q"))
+	other := llm.WithResponseCache(script, store, llm.ResponseCacheConfig{Model: "gpt-4o-mini", PromptVersion: "v1"})
+	if resp, _ := llm.Generate(ctx, other, llm.NewRequest("", "q")); resp.Cached {
+		t.Errorf("Expected another model not to share cached answers")
+	}
+	req := llm.NewRequest("", "q")
+	req.Temperature = 0.9
+	if resp, _ := llm.Generate(ctx, newCachedClient(script, store, 0), req); resp.Cached {
+		t.Errorf("Expected another temperature not to share cached answers")
+	}
+}
+
+func TestResponseCache_BypassAndExpiry(t *testing.T) {
+	script := &answerScript{answers: []string{"old", "fresh", "newer"}}
+	store := newMemoryStore()
+	client := newCachedClient(script, store, time.Hour)
+	ctx := context.Background()
+
+	llm.Generate(ctx, client, llm.NewRequest("", "q"))
+	req := llm.NewRequest("", "q")
+	req.NoCache = true
+	if resp, _ := llm.Generate(ctx, client, req); resp.Text != "fresh" || resp.Cached {
+		t.Errorf("Expected NoCache to reach the provider, got %+v", resp)
+	}
+	if resp, _ := llm.Generate(ctx, client, llm.NewRequest("", "q")); resp.Text != "fresh" {
+		t.Errorf("Expected the fresh answer to replace the cached one, got %q", resp.Text)
+	}
+	if resp, _ := llm.Generate(llm.WithoutCache(ctx), client, llm.NewRequest("", "q")); resp.Text != "newer" {
+		t.Errorf("Expected WithoutCache to reach the provider, got %q", resp.Text)
+	}
+
+	for _, entry := range store.entries {
+		entry.CreatedAt = time.Now().Add(-2 * time.Hour)
+	}
+	if resp, _ := llm.Generate(ctx, client, llm.NewRequest("", "q")); resp.Cached {
+		t.Errorf("Expected an expired entry to be a miss")
+	}
+}
+
+func TestResponseCache_SkipsDegradedAnswers(t *testing.T) {
+	primary := &scriptedClient{errs: []error{httpError(503)}}
+	fallback := &scriptedClient{text: "mock answer"}
+	chain := llm.WithFallback(
+		llm.Fallback{Name: "openai", Client: primary},
+		llm.Fallback{Name: "mock", Client: fallback, Degraded: true},
+	)
+	store := newMemoryStore()
+	client := llm.WithResponseCache(chain, store, llm.ResponseCacheConfig{Model: "gpt-4o"})
+
+	resp, err := llm.Generate(context.Background(), client, llm.NewRequest("", "q"))
+	if err != nil || !resp.Degraded {
+		t.Fatalf("Expected a degraded answer, got %+v, %v", resp, err)
+	}
+	if len(store.entries) != 0 {
+		t.Errorf("Expected degraded answers not to be cached")
+	}
+}
+
+func TestResponseCache_SkipsFallbackModels(t *testing.T) {
+	primary := &scriptedClient{errs: []error{httpError(503)}, text: "primary answer", model: "gpt-4o-2024-08-06"}
+	fallback := &scriptedClient{text: "fallback answer", model: "gpt-4o-mini"}
+	chain := llm.WithFallback(
+		llm.Fallback{Name: "openai:gpt-4o", Client: primary},
+		llm.Fallback{Name: "openai:gpt-4o-mini", Client: fallback},
+	)
+	store := newMemoryStore()
+	client := llm.WithResponseCache(chain, store, llm.ResponseCacheConfig{Model: "gpt-4o"})
+
+	resp, err := llm.Generate(context.Background(), client, llm.NewRequest("", "q"))
+	if err != nil || resp.Text != "fallback answer" {
+		t.Fatalf("Expected the fallback to answer, got %+v, %v", resp, err)
+	}
+	if len(store.entries) != 0 {
+		t.Fatalf("Expected the fallback's answer not to be cached under the primary model")
+	}
+
+	// The primary reports the snapshot its alias points to.
+	resp, err = llm.Generate(context.Background(), client, llm.NewRequest("", "q"))
+	if err != nil || resp.Text != "primary answer" || len(store.entries) != 1 {
+		t.Errorf("Expected the primary's answer to be cached, got %+v, %v with %d entries", resp, err, len(store.entries))
+	}
+}
+
+func TestPruneResponseCache_PrunesInTheBackground(t *testing.T) {
+	store := newMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		llm.PruneResponseCache(ctx, store, time.Hour, 500, 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		store.mu.Lock()
+		prunes, cutoff, max := store.prunes, store.cutoff, store.max
+		store.mu.Unlock()
+		if prunes >= 2 {
+			if age := time.Since(cutoff); age < time.Hour || age > time.Hour+time.Second || max != 500 {
+				t.Errorf("Expected the TTL and the entry limit to be applied, got cutoff %v ago and %d entries", age, max)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a prune at start and one per interval, got %d", prunes)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected pruning to stop with its context")
+	}
+}
@@ -0,0 +1,108 @@
+package llm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+)
+
+func TestRouter_RoutesRolesAndIntents(t *testing.T) {
+	primary := &answerScript{answers: []string{"primary"}}
+	planner := &answerScript{answers: []string{"planner"}}
+	strong := &answerScript{answers: []string{"strong"}}
+
+	router := llm.NewRouter(primary, "gpt-4o")
+	router.SetRole(llm.RolePlanner, planner, "gpt-4o-mini")
+	router.SetIntent("COMPARE_TONE", strong, "claude-3-5-sonnet-latest")
+
+	if router.Model(llm.RolePlanner) != "gpt-4o-mini" || router.Model(llm.RoleAnalysis) != "gpt-4o" {
+		t.Errorf("Unexpected role models: planner %s, analysis %s", router.Model(llm.RolePlanner), router.Model(llm.RoleAnalysis))
+	}
+	if router.IntentModel("COMPARE_TONE") != "claude-3-5-sonnet-latest" || router.IntentModel("SUMMARIZE") != "gpt-4o" {
+		t.Errorf("Unexpected intent models")
+	}
+
+	tests := []struct {
+		name   string
+		role   llm.ModelRole
+		intent string
+		want   string
+	}{
+		{name: "role with its own model", role: llm.RolePlanner, want: "planner"},
+		{name: "role without a model uses the primary", role: llm.RoleAnalysis, want: "primary"},
+		{name: "synthesis for an intent with its own model", role: llm.RoleSynthesis, intent: "COMPARE_TONE", want: "strong"},
+		{name: "synthesis for any other intent", role: llm.RoleSynthesis, intent: "SUMMARIZE", want: "primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := llm.WithIntent(context.Background(), tt.intent)
+			resp, err := llm.Generate(ctx, router.Client(tt.role), llm.NewRequest("", "question"))
+			if err != nil || resp.Text != tt.want {
+				t.Errorf("Expected %q, got %+v, %v", tt.want, resp, err)
+			}
+		})
+	}
+}
+
+func TestRouter_WrapSharedClientsOnce(t *testing.T) {
+	shared := &answerScript{answers: []string{"ok"}}
+	router := llm.NewRouter(shared, "gpt-4o")
+	router.SetRole(llm.RoleSynthesis, shared, "gpt-4o")
+	router.SetRole(llm.RolePlanner, &answerScript{answers: []string{"ok"}}, "gpt-4o-mini")
+
+	var models []string
+	router.Wrap(func(client llm.Client, model string) llm.Client {
+		models = append(models, model)
+		return client
+	})
+	if len(models) != 2 {
+		t.Errorf("Expected the two distinct clients to be wrapped once each, got %v", models)
+	}
+}
+
+func TestNewRouterFactory(t *testing.T) {
+	cfg := &config.Config{
+		LLMProvider:     "mock",
+		OpenAIModel:     "gpt-3.5-turbo",
+		LLMRoleModels:   map[string]string{"planner": "mock:small", "Synthesis": "mock:large"},
+		LLMIntentModels: map[string]string{"compare_tone": "mock:large"}, // Case-insensitive
+	}
+	router, err := llm.NewRouterFactory(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewRouterFactory() error = %v", err)
+	}
+	if router.Model(llm.RolePlanner) != "small" || router.Model(llm.RoleSynthesis) != "large" || router.Model(llm.RoleAnalysis) != "gpt-3.5-turbo" {
+		t.Errorf("Unexpected role models: planner %s, synthesis %s, analysis %s",
+			router.Model(llm.RolePlanner), router.Model(llm.RoleSynthesis), router.Model(llm.RoleAnalysis))
+	}
+	if router.IntentModel("COMPARE_TONE") != "large" {
+		t.Errorf("Expected COMPARE_TONE to use the large model, got %s", router.IntentModel("COMPARE_TONE"))
+	}
+
+	cfg.LLMRoleModels = map[string]string{"Analysis": "Ollama:Llama3-Custom"}
+	router, err = llm.NewRouterFactory(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewRouterFactory() error = %v", err)
+	}
+	if router.Model(llm.RoleAnalysis) != "Llama3-Custom" {
+		t.Errorf("Expected the model name to keep its case, got %s", router.Model(llm.RoleAnalysis))
+	}
+
+	cfg.LLMRoleModels = map[string]string{"rerank": "mock:reranker", "judge": "mock:grader"}
+	if router, err = llm.NewRouterFactory(context.Background(), cfg); err != nil || router.Model(llm.RoleRerank) != "reranker" || router.Model(llm.RoleJudge) != "grader" {
+		t.Errorf("Expected the rerank and judge roles to be accepted, got %v", err)
+	}
+
+	cfg.LLMRoleModels = map[string]string{"critic": "mock"}
+	if _, err := llm.NewRouterFactory(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "unknown LLM role") {
+		t.Errorf("Expected an unknown role to be rejected, got %v", err)
+	}
+
+	cfg.LLMRoleModels = map[string]string{"planner": "nonexistent"}
+	if _, err := llm.NewRouterFactory(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "LLM_ROLE_MODELS planner") {
+		t.Errorf("Expected an unknown provider to name the role, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+)
+
+// answerScript answers each call with the next canned text and records a copy
+// of the requests it received.
+type answerScript struct {
+	answers  []string
+	requests []*llm.Request
+}
+
+func (c *answerScript) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	return c.Generate(ctx, llm.NewRequest("", prompt))
+}
+
+func (c *answerScript) Generate(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	copied := *req
+	copied.Messages = append([]llm.Message(nil), req.Messages...)
+	c.requests = append(c.requests, &copied)
+	answer := c.answers[0]
+	if len(c.answers) > 1 {
+		c.answers = c.answers[1:]
+	}
+	return &llm.Response{Text: answer}, nil
+}
+
+func (c *answerScript) Stream(ctx context.Context, req *llm.Request, onChunk llm.StreamHandler) (*llm.Response, error) {
+	return c.Generate(ctx, req)
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare object", text: `{"intent":"SUMMARIZE"}`, want: `{"intent":"SUMMARIZE"}`},
+		{name: "code fence", text: "```json\n{\"intent\": \"SUMMARIZE\"}\n```", want: `{"intent": "SUMMARIZE"}`},
+		{name: "surrounding prose", text: `Here is the plan: {"a": {"b": "}"}} Hope this helps!`, want: `{"a": {"b": "}"}}`},
+		{name: "invalid object before valid one", text: `{oops} then {"ok": true}`, want: `{"ok": true}`},
+		{name: "no object", text: "I cannot help with that.", wantErr: true},
+		{name: "unterminated", text: `{"intent": "SUMMARIZE"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := llm.ExtractJSON(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaFor_QueryPlan(t *testing.T) {
+	schema := llm.SchemaFor(planner.QueryPlan{})
+
+	if schema.Type != "object" || strings.Join(schema.Required, ",") != "intent,targets,parameters" {
+		t.Errorf("Expected an object requiring intent, targets and parameters, got %+v", schema)
+	}
+	intent := schema.Properties["intent"]
+	if intent == nil || intent.Type != "string" || len(intent.Enum) == 0 {
+		t.Fatalf("Expected intent to be a string enum, got %+v", intent)
+	}
+	if schema.Properties["targets"].Items.Type != "string" {
+		t.Errorf("Expected targets to be an array of strings")
+	}
+
+	err := schema.Validate(map[string]any{"intent": "DANCE", "targets": "not a list"})
+	for _, want := range []string{`intent: "DANCE" is not one of`, "targets: expected an array", `missing required field "parameters"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the validation error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestSchemaFor_EntityExtractionBounds(t *testing.T) {
+	schema := llm.SchemaFor(models.EntityExtraction{})
+	score := schema.Properties["sentiment"].Properties["score"]
+	if score.Minimum == nil || *score.Minimum != -1 || score.Maximum == nil || *score.Maximum != 1 {
+		t.Errorf("Expected the sentiment score to be bounded to [-1, 1], got %+v", score)
+	}
+
+	err := schema.Validate(map[string]any{
+		"summary":   "s",
+		"entities":  []any{map[string]any{"name": "Intel", "category": "company", "confidence": 1.5}},
+		"keywords":  []any{},
+		"topics":    []any{},
+		"sentiment": map[string]any{"score": 0.2, "label": "positive", "confidence": 0.9},
+		"tone":      map[string]any{"style": "formal", "mood": "neutral", "confidence": 0.8},
+	})
+	if err == nil || !strings.Contains(err.Error(), "entities[0].category") || !strings.Contains(err.Error(), "entities[0].confidence: 1.5 is above") {
+		t.Errorf("Expected category and confidence violations, got %v", err)
+	}
+}
+
+func TestGenerateObject_RepairsInvalidAnswer(t *testing.T) {
+	client := &answerScript{answers: []string{
+		`{"intent": "DANCE", "targets": [], "parameters": []}`,
+		"```json\n{\"intent\": \"SUMMARIZE\", \"targets\": [\"https://example.com/a\"], \"parameters\": []}\n```",
+	}}
+
+	req := llm.NewRequest("Plan it.", "summarize a")
+	var plan planner.QueryPlan
+	_, err := llm.GenerateObject(context.Background(), client, req, &plan, 2)
+	if err != nil {
+		t.Fatalf("GenerateObject() error = %v", err)
+	}
+	if plan.Intent != planner.IntentSummarize || len(plan.Targets) != 1 {
+		t.Errorf("Unexpected plan: %+v", plan)
+	}
+
+	if len(client.requests) != 2 {
+		t.Fatalf("Expected one repair call, got %d calls", len(client.requests))
+	}
+	first, repair := client.requests[0], client.requests[1]
+	if first.ResponseFormat != llm.FormatJSON || first.Output == nil || first.Output.Name != "query_plan" {
+		t.Errorf("Expected JSON mode with the query_plan schema, got %q / %+v", first.ResponseFormat, first.Output)
+	}
+	if len(req.Messages) != 2 || req.Output != nil {
+		t.Errorf("Expected the caller's request to be left untouched, got %+v", req)
+	}
+	msgs := repair.Messages
+	if len(msgs) != 4 || msgs[2].Role != llm.RoleAssistant || msgs[3].Role != llm.RoleUser || !strings.Contains(msgs[3].Content, `"DANCE" is not one of`) {
+		t.Errorf("Expected the invalid answer and the validation error to be sent back, got %+v", msgs)
+	}
+}
+
+func TestGenerateObject_GivesUpAfterRepairs(t *testing.T) {
+	client := &answerScript{answers: []string{"Sorry, I can't do that."}}
+
+	var plan planner.QueryPlan
+	_, err := llm.GenerateObject(context.Background(), client, llm.NewRequest("", "plan"), &plan, 1)
+
+	var outErr *llm.OutputError
+	if !errors.As(err, &outErr) {
+		t.Fatalf("Expected an OutputError, got %v", err)
+	}
+	if outErr.Attempts != 2 || outErr.Raw != "Sorry, I can't do that." {
+		t.Errorf("Unexpected error details: %+v", outErr)
+	}
+}
@@ -0,0 +1,135 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/llm"
+)
+
+// toolScript answers each call with the next canned tool calls and records a
+// copy of the requests it received.
+type toolScript struct {
+	answers  [][]llm.ToolCall
+	requests []*llm.Request
+}
+
+func (c *toolScript) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	return c.Generate(ctx, llm.NewRequest("", prompt))
+}
+
+func (c *toolScript) Generate(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	copied := *req
+	copied.Messages = append([]llm.Message(nil), req.Messages...)
+	c.requests = append(c.requests, &copied)
+	calls := c.answers[0]
+	if len(c.answers) > 1 {
+		c.answers = c.answers[1:]
+	}
+	return &llm.Response{ToolCalls: calls}, nil
+}
+
+func (c *toolScript) Stream(ctx context.Context, req *llm.Request, onChunk llm.StreamHandler) (*llm.Response, error) {
+	return c.Generate(ctx, req)
+}
+
+// findTopicTool takes a topic and an optional date range.
+var findTopicTool = llm.Tool{
+	Name:        "find_by_topic",
+	Description: "Find articles about a topic",
+	Parameters: llm.SchemaFor(struct {
+		Topic string `json:"topic"`
+		Since string `json:"since,omitempty" jsonschema:"format=date"`
+	}{}),
+}
+
+func toolCall(name, args string) llm.ToolCall {
+	return llm.ToolCall{Name: name, Arguments: json.RawMessage(args)}
+}
+
+func TestCallTools_RepairsInvalidCalls(t *testing.T) {
+	client := &toolScript{answers: [][]llm.ToolCall{
+		{toolCall("find_by_topic", `{"since": "last week"}`), toolCall("dance", `{}`)},
+		{toolCall("find_by_topic", `{"topic": "AI", "since": "2025-07-01"}`)},
+	}}
+
+	req := llm.NewRequest("Call tools.", "articles about AI since July")
+	req.Tools = []llm.Tool{findTopicTool}
+	resp, err := llm.CallTools(context.Background(), client, req, 2)
+	if err != nil {
+		t.Fatalf("CallTools() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "find_by_topic" {
+		t.Errorf("Expected the repaired call, got %+v", resp.ToolCalls)
+	}
+
+	if len(client.requests) != 2 {
+		t.Fatalf("Expected one repair call, got %d calls", len(client.requests))
+	}
+	msgs := client.requests[1].Messages
+	if len(msgs) != 4 || msgs[2].Role != llm.RoleAssistant || !strings.Contains(msgs[2].Content, "Called dance with {}") {
+		t.Fatalf("Expected the invalid calls to be shown back, got %+v", msgs)
+	}
+	for _, want := range []string{`missing required field "topic"`, `"last week" is not a YYYY-MM-DD date`, `no tool named "dance"`} {
+		if !strings.Contains(msgs[3].Content, want) {
+			t.Errorf("Expected the repair instruction to mention %q, got %q", want, msgs[3].Content)
+		}
+	}
+	if len(req.Messages) != 2 {
+		t.Errorf("Expected the caller's request to be left untouched, got %+v", req.Messages)
+	}
+}
+
+func TestCallTools_NoCallIsValid(t *testing.T) {
+	client := &toolScript{answers: [][]llm.ToolCall{nil}}
+	req := llm.NewRequest("Call tools.", "hello")
+	req.Tools = []llm.Tool{findTopicTool}
+
+	resp, err := llm.CallTools(context.Background(), client, req, 2)
+	if err != nil || len(resp.ToolCalls) != 0 || len(client.requests) != 1 {
+		t.Errorf("Expected an answer without calls and no repair, got %+v, %v", resp, err)
+	}
+}
+
+func TestCallTools_GivesUpAfterRepairs(t *testing.T) {
+	client := &toolScript{answers: [][]llm.ToolCall{{toolCall("dance", `{}`)}}}
+	req := llm.NewRequest("Call tools.", "dance")
+	req.Tools = []llm.Tool{findTopicTool}
+
+	_, err := llm.CallTools(context.Background(), client, req, 1)
+	var outErr *llm.OutputError
+	if !errors.As(err, &outErr) || outErr.Attempts != 2 {
+		t.Errorf("Expected an OutputError after two attempts, got %v", err)
+	}
+}
+
+// textToolClient only takes a prompt and answers with canned text.
+type textToolClient struct {
+	prompt string
+	answer string
+}
+
+func (c *textToolClient) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	c.prompt = prompt
+	return &llm.Response{Text: c.answer}, nil
+}
+
+func TestCallTools_DescribesToolsToPromptOnlyClients(t *testing.T) {
+	client := &textToolClient{answer: "```json\n{\"tool_calls\": [{\"name\": \"find_by_topic\", \"arguments\": {\"topic\": \"AI\"}}]}\n```"}
+	req := llm.NewRequest("Call tools.", "articles about AI")
+	req.Tools = []llm.Tool{findTopicTool}
+
+	resp, err := llm.CallTools(context.Background(), client, req, 0)
+	if err != nil {
+		t.Fatalf("CallTools() error = %v", err)
+	}
+	if !strings.Contains(client.prompt, "- find_by_topic: Find articles about a topic") || !strings.Contains(client.prompt, `"tool_calls"`) {
+		t.Errorf("Expected the tools to be described in the prompt, got %q", client.prompt)
+	}
+	if len(resp.ToolCalls) != 1 || string(resp.ToolCalls[0].Arguments) != `{"topic": "AI"}` {
+		t.Errorf("Expected the call to be read from the answer, got %+v", resp.ToolCalls)
+	}
+}
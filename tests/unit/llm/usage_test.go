@@ -0,0 +1,67 @@
+package llm_test
+
+import (
+	"context"
+	"testing"
+
+	"article-chat-system/internal/config"
+	"article-chat-system/internal/llm"
+)
+
+func TestUsageMeter_RecordsEveryCall(t *testing.T) {
+	client, err := llm.NewClientFactory(context.Background(), &config.Config{LLMProvider: "mock"})
+	if err != nil {
+		t.Fatalf("NewClientFactory() error = %v", err)
+	}
+
+	ctx, meter := llm.WithUsageMeter(context.Background())
+	var want llm.Usage
+	for _, prompt := range []string{"summarize this article", "compare these articles"} {
+		resp, err := client.GenerateContent(ctx, prompt)
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		if resp.Usage.TotalTokens == 0 {
+			t.Errorf("Expected usage on the response for %q", prompt)
+		}
+		want.Add(resp.Usage)
+	}
+
+	if meter.Calls() != 2 {
+		t.Errorf("Expected 2 recorded calls, got %d", meter.Calls())
+	}
+	if got := meter.Usage(); got != want {
+		t.Errorf("Expected accumulated usage %+v, got %+v", want, got)
+	}
+	if meter.Model() != "mock" {
+		t.Errorf("Expected model 'mock', got %q", meter.Model())
+	}
+
+	// Calls made without a meter are not recorded anywhere.
+	if _, err := client.GenerateContent(context.Background(), "summarize"); err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+	if meter.Calls() != 2 {
+		t.Errorf("Expected unmetered call to be ignored, got %d calls", meter.Calls())
+	}
+}
+
+func TestUsageMeter_ReportsSynthesisModel(t *testing.T) {
+	ctx, meter := llm.WithUsageMeter(context.Background())
+	usage := llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+
+	llm.RecordUsage(ctx, "gpt-4o-mini", usage)
+	if meter.Model() != "gpt-4o-mini" {
+		t.Errorf("Expected the latest model without a synthesis call, got %q", meter.Model())
+	}
+
+	// A planner call made after the synthesis does not change the answer's model.
+	llm.RecordUsage(llm.WithSynthesis(ctx), "gpt-4o", usage)
+	llm.RecordUsage(ctx, "gpt-4o-mini", usage)
+	if meter.Model() != "gpt-4o" {
+		t.Errorf("Expected the synthesis model, got %q", meter.Model())
+	}
+	if byModel := meter.ByModel(); len(byModel) != 2 || byModel["gpt-4o-mini"].TotalTokens != 30 {
+		t.Errorf("Expected usage for both models, got %+v", byModel)
+	}
+}
@@ -0,0 +1,233 @@
+package mcp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/mcp"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/usage"
+)
+
+// mockArticleService implements article.Service over a fixed set of articles.
+type mockArticleService struct {
+	articles map[string]*models.Article
+}
+
+func newMockArticleService() *mockArticleService {
+	return &mockArticleService{articles: map[string]*models.Article{
+		"https://example.com/intel": {URL: "https://example.com/intel", Title: "Intel layoffs", Summary: "Intel cuts jobs.", Relevance: 0.9},
+	}}
+}
+
+func (m *mockArticleService) GetArticle(ctx context.Context, url string) (*models.Article, bool) {
+	art, ok := m.articles[url]
+	return art, ok
+}
+func (m *mockArticleService) StoreArticle(ctx context.Context, article *models.Article) error {
+	return nil
+}
+func (m *mockArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	return false, nil
+}
+func (m *mockArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	page := &repository.ArticlePage{}
+	for _, art := range m.articles {
+		page.Articles = append(page.Articles, art)
+	}
+	return page, nil
+}
+func (m *mockArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
+	llm.RecordUsage(ctx, "gpt-4o-mini", llm.Usage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120})
+	return "generated summary", nil
+}
+func (m *mockArticleService) FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error) {
+	return []repository.EntityCount{{Entity: "Intel", Count: 2}}, nil
+}
+func (m *mockArticleService) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	return []*models.Article{m.articles["https://example.com/intel"]}, nil
+}
+
+// usageLog implements mcp.UsageTracker, keeping what it records.
+type usageLog struct {
+	operations []string
+	callers    []usage.Caller
+	tokens     int
+	budgetErr  error // Returned by Check
+}
+
+func (u *usageLog) Check(ctx context.Context) error {
+	return u.budgetErr
+}
+
+func (u *usageLog) Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error) {
+	u.operations = append(u.operations, operation)
+	u.callers = append(u.callers, usage.CallerFrom(ctx))
+	for _, m := range byModel {
+		u.tokens += m.TotalTokens
+	}
+	return 0, nil
+}
+
+func newTestServer() *mcp.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return mcp.NewServer(logger, newMockArticleService(), nil, nil)
+}
+
+// call sends one request and decodes the response envelope.
+func call(t *testing.T, s *mcp.Server, payload string) map[string]interface{} {
+	t.Helper()
+	raw := s.Handle(context.Background(), []byte(payload))
+	if raw == nil {
+		t.Fatalf("Expected a response to %s", payload)
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("Invalid response %s: %v", raw, err)
+	}
+	return resp
+}
+
+func TestServer_Initialize(t *testing.T) {
+	s := newTestServer()
+
+	resp := call(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1"}}}`)
+	result := resp["result"].(map[string]interface{})
+	if result["protocolVersion"] != "2025-03-26" {
+		t.Errorf("Expected the client's protocol version to be accepted, got %v", result["protocolVersion"])
+	}
+	capabilities := result["capabilities"].(map[string]interface{})
+	if _, ok := capabilities["tools"]; !ok {
+		t.Errorf("Expected tools capability, got %v", capabilities)
+	}
+
+	if raw := s.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); raw != nil {
+		t.Errorf("Expected no response to a notification, got %s", raw)
+	}
+}
+
+func TestServer_ToolsListAndCall(t *testing.T) {
+	s := newTestServer()
+
+	resp := call(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	var names []string
+	for _, tool := range resp["result"].(map[string]interface{})["tools"].([]interface{}) {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	want := "search_articles,get_article,summarize_article,find_common_entities,add_article"
+	if strings.Join(names, ",") != want {
+		t.Errorf("Expected tools %s, got %v", want, names)
+	}
+
+	tests := []struct {
+		name     string
+		payload  string
+		wantText string
+		wantErr  bool
+	}{
+		{"search", `{"name":"search_articles","arguments":{"query":"layoffs"}}`, "Intel layoffs", false},
+		{"summarize", `{"name":"summarize_article","arguments":{"url":"https://example.com/intel"}}`, "Intel cuts jobs.", false},
+		{"entities", `{"name":"find_common_entities","arguments":{}}`, `"entity": "Intel"`, false},
+		{"missing article", `{"name":"get_article","arguments":{"url":"https://example.com/nope"}}`, "article not found", true},
+		{"missing argument", `{"name":"get_article","arguments":{}}`, "'url' is required", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := call(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":`+tt.payload+`}`)
+			result := resp["result"].(map[string]interface{})
+			text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+			if !strings.Contains(text, tt.wantText) {
+				t.Errorf("Expected text containing %q, got %q", tt.wantText, text)
+			}
+			if isError, _ := result["isError"].(bool); isError != tt.wantErr {
+				t.Errorf("Expected isError=%v, got %v", tt.wantErr, isError)
+			}
+		})
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"nope"}}`)
+	if resp["error"].(map[string]interface{})["code"].(float64) != -32602 {
+		t.Errorf("Expected invalid params for an unknown tool, got %v", resp)
+	}
+}
+
+func TestServer_SummarizeChargesTheCaller(t *testing.T) {
+	loader, _ := prompts.NewLoader("v1")
+	loader.PromptDir = filepath.Join("..", "..", "..", loader.PromptDir)
+	promptFactory, err := prompts.NewFactory(loader)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	articleSvc := newMockArticleService()
+	articleSvc.articles["https://example.com/raw"] = &models.Article{URL: "https://example.com/raw", TextContent: "Intel will cut 15% of its staff."}
+	s := mcp.NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), articleSvc, nil, promptFactory)
+	log := &usageLog{}
+	s.SetUsageTracker(log)
+
+	alice := usage.Caller{APIKeyID: usage.KeyID("alice-key")}
+	ctx := usage.WithCaller(context.Background(), alice)
+	summarize := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"summarize_article","arguments":{"url":"https://example.com/raw"}}}`
+	if raw := s.Handle(ctx, []byte(summarize)); !strings.Contains(string(raw), "generated summary") {
+		t.Fatalf("Expected a generated summary, got %s", raw)
+	}
+	if len(log.operations) != 1 || log.operations[0] != "summary" || log.tokens != 120 || log.callers[0] != alice {
+		t.Errorf("Expected the summary's 120 tokens to be charged to %+v, got %v, %d tokens, %+v", alice, log.operations, log.tokens, log.callers)
+	}
+
+	log.budgetErr = fmt.Errorf("%w: caller budget spent", usage.ErrBudgetExceeded)
+	raw := s.Handle(ctx, []byte(summarize))
+	if !strings.Contains(string(raw), usage.ErrBudgetExceeded.Error()) || len(log.operations) != 1 {
+		t.Errorf("Expected the summary to be refused over budget, got %s", raw)
+	}
+}
+
+func TestServer_Resources(t *testing.T) {
+	s := newTestServer()
+
+	resp := call(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`)
+	resources := resp["result"].(map[string]interface{})["resources"].([]interface{})
+	if len(resources) != 1 || resources[0].(map[string]interface{})["uri"] != "article:https://example.com/intel" {
+		t.Fatalf("Unexpected resources: %v", resources)
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"article:https://example.com/intel"}}`)
+	contents := resp["result"].(map[string]interface{})["contents"].([]interface{})
+	if !strings.Contains(contents[0].(map[string]interface{})["text"].(string), "Intel layoffs") {
+		t.Errorf("Expected the article JSON, got %v", contents)
+	}
+
+	resp = call(t, s, `{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"article:https://example.com/nope"}}`)
+	if resp["error"] == nil {
+		t.Errorf("Expected an error for an unknown resource, got %v", resp)
+	}
+}
+
+func TestServer_ServeStdio(t *testing.T) {
+	s := newTestServer()
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"method":"bogus"}` + "\n")
+	var out bytes.Buffer
+
+	if err := s.ServeStdio(context.Background(), in, &out); err != nil {
+		t.Fatalf("ServeStdio() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 responses, got %d: %q", len(lines), out.String())
+	}
+	if !strings.Contains(lines[1], `"code":-32601`) {
+		t.Errorf("Expected method not found for an unknown method, got %s", lines[1])
+	}
+}
@@ -0,0 +1,125 @@
+package planner_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
+)
+
+// toolCaller answers every call with the same tool calls.
+type toolCaller struct {
+	calls []llm.ToolCall
+}
+
+func (c *toolCaller) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	return c.Generate(ctx, llm.NewRequest("", prompt))
+}
+
+func (c *toolCaller) Generate(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	return &llm.Response{ToolCalls: c.calls}, nil
+}
+
+// stepArguments are the arguments every tool of stepToolbox takes.
+type stepArguments struct {
+	ID     string   `json:"id,omitempty"`
+	Topic  string   `json:"topic,omitempty"`
+	Inputs []string `json:"inputs,omitempty"`
+}
+
+// stepToolbox offers a tool per intent and turns its calls into steps.
+type stepToolbox struct{}
+
+var toolIntents = map[string]planner.QueryIntent{
+	"find_by_topic":    planner.IntentFindTopic,
+	"compare_multiple": planner.IntentCompareMultiple,
+}
+
+func (stepToolbox) Tools() []llm.Tool {
+	var tools []llm.Tool
+	for name := range toolIntents {
+		tools = append(tools, llm.Tool{Name: name, Description: name, Parameters: llm.SchemaFor(stepArguments{})})
+	}
+	return tools
+}
+
+func (stepToolbox) Step(call llm.ToolCall) (planner.PlanStep, error) {
+	var args stepArguments
+	if err := json.Unmarshal(call.Arguments, &args); err != nil {
+		return planner.PlanStep{}, err
+	}
+	step := planner.PlanStep{ID: args.ID, Intent: toolIntents[call.Name], Targets: []string{}, Parameters: []string{}, Inputs: args.Inputs}
+	if args.Topic != "" {
+		step.Parameters = append(step.Parameters, args.Topic)
+	}
+	return step, nil
+}
+
+// emptyArticleService finds no articles.
+type emptyArticleService struct{}
+
+func (emptyArticleService) GetArticle(ctx context.Context, url string) (*models.Article, bool) {
+	return nil, false
+}
+func (emptyArticleService) StoreArticle(ctx context.Context, article *models.Article) error {
+	return nil
+}
+func (emptyArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	return false, nil
+}
+func (emptyArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	return &repository.ArticlePage{}, nil
+}
+func (emptyArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
+	return "", nil
+}
+func (emptyArticleService) FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error) {
+	return nil, nil
+}
+func (emptyArticleService) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	return nil, nil
+}
+
+func newPromptFactory(t *testing.T) *prompts.Factory {
+	t.Helper()
+	loader, _ := prompts.NewLoader("v1")
+	loader.PromptDir = filepath.Join("..", "..", "..", loader.PromptDir)
+	factory, err := prompts.NewFactory(loader)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	return factory
+}
+
+func TestService_CreatePlanDropsUnknownInputs(t *testing.T) {
+	client := &toolCaller{calls: []llm.ToolCall{
+		{Name: "find_by_topic", Arguments: json.RawMessage(`{"id": "found", "topic": "AI"}`)},
+		{Name: "compare_multiple", Arguments: json.RawMessage(`{"inputs": ["found", "missing"]}`)},
+		{Name: "compare_multiple", Arguments: json.RawMessage(`{"inputs": ["later"]}`)},
+		{Name: "find_by_topic", Arguments: json.RawMessage(`{"id": "later", "topic": "chips"}`)},
+	}}
+	svc := planner.NewService(client, newPromptFactory(t), emptyArticleService{}, nil, stepToolbox{})
+
+	plan, err := svc.CreatePlan(context.Background(), "compare the articles about AI", nil)
+	if err != nil {
+		t.Fatalf("CreatePlan() error = %v", err)
+	}
+	if len(plan.Steps) != 4 {
+		t.Fatalf("Expected 4 steps, got %+v", plan.Steps)
+	}
+	if inputs := plan.Steps[1].Inputs; len(inputs) != 1 || inputs[0] != "found" {
+		t.Errorf("Expected only the earlier step to stay an input, got %v", inputs)
+	}
+	if inputs := plan.Steps[2].Inputs; len(inputs) != 0 {
+		t.Errorf("Expected an input from a later step to be dropped, got %v", inputs)
+	}
+	if _, err := planner.Dependencies(plan.Steps); err != nil {
+		t.Errorf("Expected the plan to form a graph, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package processing_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/prompts"
+)
+
+// analysisClient answers initial analysis prompts with the first paragraph
+// number it sees and reduce prompts with a combined headline. It is called
+// from several goroutines.
+type analysisClient struct {
+	mu        sync.Mutex
+	templates []string
+	fail      string // Chunks containing this text fail
+}
+
+func (c *analysisClient) GenerateContent(ctx context.Context, prompt string) (*llm.Response, error) {
+	return c.Generate(ctx, llm.NewRequest("", prompt))
+}
+
+func (c *analysisClient) Generate(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	c.mu.Lock()
+	c.templates = append(c.templates, req.Template)
+	c.mu.Unlock()
+	llm.RecordUsage(ctx, "analysis-model", llm.Usage{PromptTokens: 8, CompletionTokens: 2, TotalTokens: 10})
+
+	prompt := req.Prompt()
+	switch req.Template {
+	case "reduce_analysis":
+		parts := strings.Count(prompt, "Headline:")
+		return &llm.Response{Text: fmt.Sprintf(`{"headline": "Combined %d parts", "key_points": ["k"], "sentiment": "Negative", "entities": ["Intel"]}`, parts)}, nil
+	case "initial_analysis":
+		if c.fail != "" && strings.Contains(prompt, c.fail) {
+			return nil, fmt.Errorf("provider unavailable")
+		}
+		var first int
+		fmt.Sscanf(prompt[strings.Index(prompt, "Paragraph"):], "Paragraph %d", &first)
+		return &llm.Response{Text: fmt.Sprintf(`{"headline": "Part from paragraph %d", "key_points": ["p"], "sentiment": "Neutral", "entities": ["Intel"]}`, first)}, nil
+	}
+	return nil, fmt.Errorf("unexpected template %q", req.Template)
+}
+
+func (c *analysisClient) Stream(ctx context.Context, req *llm.Request, onChunk llm.StreamHandler) (*llm.Response, error) {
+	return c.Generate(ctx, req)
+}
+
+func (c *analysisClient) count(template string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.templates {
+		if t == template {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestAnalyzer(t *testing.T, client llm.Client, contextTokens int) *processing.Analyzer {
+	t.Helper()
+	loader, _ := prompts.NewLoader("v1")
+	loader.PromptDir = filepath.Join("..", "..", "..", loader.PromptDir)
+	promptFactory, err := prompts.NewFactory(loader)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	analyzer := processing.NewAnalyzer(client, promptFactory)
+	analyzer.SetBudget(&llm.Budget{ContextTokens: contextTokens, OutputTokens: 200, Tokenizer: llm.EstimateTokenizer{}})
+	return analyzer
+}
+
+func longArticle(paragraphs int) *models.Article {
+	var text []string
+	for i := 0; i < paragraphs; i++ {
+		text = append(text, fmt.Sprintf("Paragraph %d %s", i, strings.Repeat("about the chip maker and its layoffs. ", 10)))
+	}
+	return &models.Article{Title: "Intel layoffs", TextContent: strings.Join(text, "\n")}
+}
+
+func TestAnalyzer_ShortArticleIsAnalyzedWhole(t *testing.T) {
+	client := &analysisClient{}
+	analyzer := newTestAnalyzer(t, client, 16000)
+
+	art := longArticle(5)
+	if err := analyzer.InitialAnalysis(context.Background(), art); err != nil {
+		t.Fatalf("InitialAnalysis() error = %v", err)
+	}
+	if client.count("initial_analysis") != 1 || client.count("reduce_analysis") != 0 {
+		t.Errorf("Expected a single analysis call, got %v", client.templates)
+	}
+	if !strings.HasPrefix(art.Summary, "Part from paragraph 0") || art.Sentiment != "Neutral" {
+		t.Errorf("Unexpected analysis: %q / %q", art.Summary, art.Sentiment)
+	}
+}
+
+func TestAnalyzer_LongArticleIsAnalyzedInChunks(t *testing.T) {
+	client := &analysisClient{}
+	analyzer := newTestAnalyzer(t, client, 1200)
+
+	art := longArticle(40)
+	if err := analyzer.InitialAnalysis(context.Background(), art); err != nil {
+		t.Fatalf("InitialAnalysis() error = %v", err)
+	}
+	chunks := client.count("initial_analysis")
+	if chunks < 4 {
+		t.Fatalf("Expected the article to be split into several chunks, got %d", chunks)
+	}
+	if client.count("reduce_analysis") == 0 {
+		t.Fatalf("Expected the chunk analyses to be combined")
+	}
+	if !strings.HasPrefix(art.Summary, "Combined ") || art.Sentiment != "Negative" || len(art.Entities) != 1 {
+		t.Errorf("Expected the combined analysis on the article, got %q / %q / %v", art.Summary, art.Sentiment, art.Entities)
+	}
+}
+
+func TestAnalyzer_SkipsFailedChunks(t *testing.T) {
+	client := &analysisClient{fail: "Paragraph 0 "}
+	analyzer := newTestAnalyzer(t, client, 1200)
+
+	art := longArticle(40)
+	if err := analyzer.InitialAnalysis(context.Background(), art); err != nil {
+		t.Fatalf("Expected the other chunks to carry the analysis, got %v", err)
+	}
+	if !strings.HasPrefix(art.Summary, "Combined ") {
+		t.Errorf("Expected a combined summary, got %q", art.Summary)
+	}
+
+	client = &analysisClient{fail: "Paragraph"}
+	if err := newTestAnalyzer(t, client, 1200).InitialAnalysis(context.Background(), longArticle(40)); err == nil {
+		t.Errorf("Expected an error when every chunk fails")
+	}
+}
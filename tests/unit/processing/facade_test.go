@@ -0,0 +1,272 @@
+package processing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/processing"
+	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/usage"
+	"article-chat-system/internal/vector"
+)
+
+// storeArticleService implements article.Service over a single stored
+// article.
+type storeArticleService struct {
+	stored   *models.Article
+	storeErr error
+	stores   int
+	deletes  int
+}
+
+func (m *storeArticleService) GetArticle(ctx context.Context, url string) (*models.Article, bool) {
+	if m.stored == nil || m.stored.URL != url {
+		return nil, false
+	}
+	return m.stored, true
+}
+func (m *storeArticleService) StoreArticle(ctx context.Context, article *models.Article) error {
+	m.stores++
+	if m.storeErr != nil {
+		return m.storeErr
+	}
+	m.stored = article
+	return nil
+}
+func (m *storeArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	m.deletes++
+	if m.stored == nil || m.stored.URL != url {
+		return false, nil
+	}
+	m.stored = nil
+	return true, nil
+}
+func (m *storeArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	return &repository.ArticlePage{}, nil
+}
+func (m *storeArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
+	return "", nil
+}
+func (m *storeArticleService) FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error) {
+	return nil, nil
+}
+func (m *storeArticleService) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	return nil, nil
+}
+
+// recordingVectorService implements vector.Service, recording the articles
+// it indexes.
+type recordingVectorService struct {
+	indexed   []*models.Article
+	indexErr  error
+	removeErr error
+}
+
+func (m *recordingVectorService) SearchByTopics(ctx context.Context, topics []string, limit int) ([]*models.Article, error) {
+	return nil, nil
+}
+func (m *recordingVectorService) SearchBySemanticSimilarity(ctx context.Context, query string, limit int) ([]*models.Article, error) {
+	return nil, nil
+}
+func (m *recordingVectorService) IndexArticle(ctx context.Context, article *models.Article) error {
+	if m.indexErr != nil {
+		return m.indexErr
+	}
+	m.indexed = append(m.indexed, article)
+	return nil
+}
+func (m *recordingVectorService) RemoveArticle(ctx context.Context, url string) error {
+	return m.removeErr
+}
+
+// usageLog implements processing.UsageRecorder, keeping what it records.
+type usageLog struct {
+	operations []string
+	callers    []usage.Caller
+	tokens     int
+	budgetErr  error // Returned by Check
+}
+
+func (u *usageLog) Check(ctx context.Context) error {
+	return u.budgetErr
+}
+
+func (u *usageLog) Record(ctx context.Context, operation string, byModel map[string]llm.Usage) (float64, error) {
+	u.operations = append(u.operations, operation)
+	u.callers = append(u.callers, usage.CallerFrom(ctx))
+	for _, m := range byModel {
+		u.tokens += m.TotalTokens
+	}
+	return 0, nil
+}
+
+// countingAnswerCache implements processing.AnswerCache, counting the
+// invalidations it is asked for.
+type countingAnswerCache struct {
+	articles   []string
+	untargeted int
+}
+
+func (c *countingAnswerCache) InvalidateArticle(url string) int {
+	c.articles = append(c.articles, url)
+	return 0
+}
+
+func (c *countingAnswerCache) InvalidateUntargeted() int {
+	c.untargeted++
+	return 0
+}
+
+// newArticleServer serves an article page to fetch.
+func newArticleServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var body []string
+	for i := 0; i < 5; i++ {
+		body = append(body, fmt.Sprintf("<p>Paragraph %d %s</p>", i, strings.Repeat("about the chip maker and its layoffs. ", 10)))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><head><title>Intel layoffs</title></head><body><article>%s</article></body></html>", strings.Join(body, ""))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestFacade(t *testing.T, articleSvc *storeArticleService, vectorSvc *recordingVectorService) *processing.Facade {
+	t.Helper()
+	loader, _ := prompts.NewLoader("v1")
+	loader.PromptDir = filepath.Join("..", "..", "..", loader.PromptDir)
+	promptFactory, err := prompts.NewFactory(loader)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	return processing.NewFacade(&analysisClient{}, articleSvc, promptFactory, vectorSvc, nil)
+}
+
+func TestFacade_ReanalyzeWritesVectorsBeforePostgres(t *testing.T) {
+	server := newArticleServer(t)
+	previous := &models.Article{URL: server.URL, Title: "Intel layoffs", Summary: "Old analysis"}
+
+	t.Run("updated", func(t *testing.T) {
+		articleSvc := &storeArticleService{stored: previous}
+		vectorSvc := &recordingVectorService{}
+		updated, err := newTestFacade(t, articleSvc, vectorSvc).ReanalyzeArticle(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("ReanalyzeArticle() error = %v", err)
+		}
+		if len(vectorSvc.indexed) != 1 || vectorSvc.indexed[0] != updated || articleSvc.stored != updated {
+			t.Errorf("Expected the new analysis in both stores, got vectors %v and row %+v", vectorSvc.indexed, articleSvc.stored)
+		}
+	})
+
+	t.Run("vector store unavailable", func(t *testing.T) {
+		articleSvc := &storeArticleService{stored: previous}
+		vectorSvc := &recordingVectorService{indexErr: vector.ErrUnavailable}
+		_, err := newTestFacade(t, articleSvc, vectorSvc).ReanalyzeArticle(context.Background(), server.URL)
+		if !errors.Is(err, vector.ErrUnavailable) {
+			t.Fatalf("Expected ErrUnavailable, got %v", err)
+		}
+		if articleSvc.stores != 0 || articleSvc.stored != previous {
+			t.Errorf("Expected PostgreSQL to keep the previous analysis, got %d writes", articleSvc.stores)
+		}
+	})
+
+	t.Run("postgres fails", func(t *testing.T) {
+		articleSvc := &storeArticleService{stored: previous, storeErr: errors.New("connection reset")}
+		vectorSvc := &recordingVectorService{}
+		if _, err := newTestFacade(t, articleSvc, vectorSvc).ReanalyzeArticle(context.Background(), server.URL); err == nil {
+			t.Fatal("Expected the failed write to fail the re-analysis")
+		}
+		if len(vectorSvc.indexed) != 2 || vectorSvc.indexed[1] != previous {
+			t.Errorf("Expected the previous vectors to be restored, got %v", vectorSvc.indexed)
+		}
+	})
+}
+
+func TestFacade_DeleteKeepsArticleWhileVectorStoreUnavailable(t *testing.T) {
+	stored := &models.Article{URL: "https://example.com/a", Title: "A"}
+	articleSvc := &storeArticleService{stored: stored}
+	vectorSvc := &recordingVectorService{removeErr: vector.ErrUnavailable}
+	facade := newTestFacade(t, articleSvc, vectorSvc)
+
+	if err := facade.DeleteArticle(context.Background(), stored.URL); !errors.Is(err, vector.ErrUnavailable) {
+		t.Fatalf("Expected ErrUnavailable, got %v", err)
+	}
+	if articleSvc.deletes != 0 || articleSvc.stored != stored {
+		t.Errorf("Expected the PostgreSQL row to be kept, got %d deletes", articleSvc.deletes)
+	}
+
+	vectorSvc.removeErr = nil
+	if err := facade.DeleteArticle(context.Background(), stored.URL); err != nil {
+		t.Fatalf("DeleteArticle() error = %v", err)
+	}
+	if articleSvc.stored != nil {
+		t.Error("Expected the retried delete to remove the row")
+	}
+}
+
+func TestFacade_RecordsIngestionUsage(t *testing.T) {
+	server := newArticleServer(t)
+	articleSvc := &storeArticleService{}
+	facade := newTestFacade(t, articleSvc, &recordingVectorService{})
+	log := &usageLog{}
+	facade.SetUsageRecorder(log)
+	alice := usage.Caller{User: "alice", APIKeyID: usage.KeyID("alice-key")}
+	ctx := usage.WithCaller(context.Background(), alice)
+
+	if _, err := facade.AddNewArticle(ctx, server.URL); err != nil {
+		t.Fatalf("AddNewArticle() error = %v", err)
+	}
+	if _, err := facade.ReanalyzeArticle(ctx, server.URL); err != nil {
+		t.Fatalf("ReanalyzeArticle() error = %v", err)
+	}
+	if len(log.operations) != 2 || log.operations[0] != "ingestion" || log.operations[1] != "ingestion" || log.tokens == 0 {
+		t.Errorf("Expected both analyses to be recorded as ingestion, got %v with %d tokens", log.operations, log.tokens)
+	}
+	if log.callers[0] != alice {
+		t.Errorf("Expected the usage to be charged to alice, got %+v", log.callers[0])
+	}
+}
+
+func TestFacade_RefusesIngestionOverBudget(t *testing.T) {
+	server := newArticleServer(t)
+	articleSvc := &storeArticleService{}
+	facade := newTestFacade(t, articleSvc, &recordingVectorService{})
+	log := &usageLog{budgetErr: fmt.Errorf("%w: caller budget spent", usage.ErrBudgetExceeded)}
+	facade.SetUsageRecorder(log)
+
+	if _, err := facade.AddNewArticle(context.Background(), server.URL); !errors.Is(err, usage.ErrBudgetExceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+	if articleSvc.stores != 0 || len(log.operations) != 0 {
+		t.Errorf("Expected nothing to be analyzed or stored, got %d writes and usage %v", articleSvc.stores, log.operations)
+	}
+
+	articleSvc.stored = &models.Article{URL: server.URL, Title: "Intel layoffs"}
+	if _, err := facade.ReanalyzeArticle(context.Background(), server.URL); !errors.Is(err, usage.ErrBudgetExceeded) {
+		t.Errorf("Expected the re-analysis to be refused too, got %v", err)
+	}
+}
+
+func TestFacade_AddDropsUntargetedAnswers(t *testing.T) {
+	server := newArticleServer(t)
+	facade := newTestFacade(t, &storeArticleService{}, &recordingVectorService{})
+	answers := &countingAnswerCache{}
+	facade.SetAnswerCache(answers)
+
+	if _, err := facade.AddNewArticle(context.Background(), server.URL); err != nil {
+		t.Fatalf("AddNewArticle() error = %v", err)
+	}
+	if answers.untargeted != 1 || len(answers.articles) != 0 {
+		t.Errorf("Expected only the untargeted answers to be dropped, got %d untargeted and articles %v", answers.untargeted, answers.articles)
+	}
+}
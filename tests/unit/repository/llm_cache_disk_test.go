@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/repository"
+)
+
+func diskEntry(key string, created time.Time) *llm.CacheEntry {
+	return &llm.CacheEntry{Key: key, Model: "gpt-4o", PromptVersion: "v1", Response: json.RawMessage(`{"text":"answer"}`), CreatedAt: created}
+}
+
+func TestDiskLLMCacheRepository_PutAndGet(t *testing.T) {
+	repo, err := repository.NewDiskLLMCacheRepository(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskLLMCacheRepository() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if entry, err := repo.GetResponse(ctx, "ab12"); entry != nil || err != nil {
+		t.Errorf("Expected a miss without error, got %+v, %v", entry, err)
+	}
+	if err := repo.PutResponse(ctx, diskEntry("ab12", time.Now())); err != nil {
+		t.Fatalf("PutResponse() error = %v", err)
+	}
+	entry, err := repo.GetResponse(ctx, "ab12")
+	if err != nil || entry == nil || string(entry.Response) != `{"text":"answer"}` || entry.Model != "gpt-4o" {
+		t.Errorf("Expected the stored entry back, got %+v, %v", entry, err)
+	}
+}
+
+func TestDiskLLMCacheRepository_Prune(t *testing.T) {
+	dir := t.TempDir()
+	repo, _ := repository.NewDiskLLMCacheRepository(dir)
+	ctx := context.Background()
+
+	now := time.Now()
+	repo.PutResponse(ctx, diskEntry("aa01", now.Add(-48*time.Hour)))
+	for i, key := range []string{"bb01", "bb02", "bb03"} {
+		repo.PutResponse(ctx, diskEntry(key, now))
+		// Make bb01 the least recently used.
+		used := now.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(filepath.Join(dir, key[:2], key+".json"), used, used)
+	}
+
+	deleted, err := repo.PruneResponses(ctx, now.Add(-24*time.Hour), 2)
+	if err != nil || deleted != 2 {
+		t.Fatalf("Expected the expired and the least recently used entry to be deleted, got %d, %v", deleted, err)
+	}
+	for key, want := range map[string]bool{"aa01": false, "bb01": false, "bb02": true, "bb03": true} {
+		entry, _ := repo.GetResponse(ctx, key)
+		if (entry != nil) != want {
+			t.Errorf("Entry %s kept = %v, want %v", key, entry != nil, want)
+		}
+	}
+}
+
+func TestDiskLLMCacheRepository_PruneKeepsUnreadableEntries(t *testing.T) {
+	dir := t.TempDir()
+	repo, _ := repository.NewDiskLLMCacheRepository(dir)
+	ctx := context.Background()
+
+	os.MkdirAll(filepath.Join(dir, "cc"), 0o755)
+	unreadable := filepath.Join(dir, "cc", "cc01.json")
+	os.WriteFile(unreadable, []byte("{not json"), 0o644)
+
+	deleted, err := repo.PruneResponses(ctx, time.Now().Add(-24*time.Hour), 0)
+	if err != nil || deleted != 0 {
+		t.Fatalf("Expected nothing to be deleted, got %d, %v", deleted, err)
+	}
+	if _, err := os.Stat(unreadable); err != nil {
+		t.Errorf("Expected the unreadable entry not to be taken for expired: %v", err)
+	}
+}
+
+func TestOpenLLMCache_UnknownBackend(t *testing.T) {
+	if _, err := repository.OpenLLMCache("redis", t.TempDir(), nil); err == nil || !strings.Contains(err.Error(), "postgres, disk") {
+		t.Errorf("Expected an error listing the supported backends, got %v", err)
+	}
+}
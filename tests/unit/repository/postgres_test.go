@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -56,7 +57,7 @@ func TestPostgresRepository_FindByURL(t *testing.T) {
 	}
 }
 
-func TestPostgresRepository_FindAll(t *testing.T) {
+func TestPostgresRepository_List(t *testing.T) {
 	// Simplified test that validates the method signature
 	// This test doesn't actually query a database
 	// In a real test environment, you would use a test database
@@ -95,3 +96,28 @@ func TestNewPostgresRepositoryWithDB(t *testing.T) {
 		t.Error("Expected repository to be non-nil")
 	}
 }
+
+func TestCursor_RoundTrip(t *testing.T) {
+	processedAt := time.Date(2025, 7, 27, 10, 30, 0, 123456000, time.UTC)
+	url := "https://edition.cnn.com/2025/07/27/business/eu-trade-deal"
+
+	cursor := repository.EncodeCursor(processedAt, url)
+	gotTime, gotURL, err := repository.DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor() returned an unexpected error: %v", err)
+	}
+	if !gotTime.Equal(processedAt) {
+		t.Errorf("Expected processed_at %v, got %v", processedAt, gotTime)
+	}
+	if gotURL != url {
+		t.Errorf("Expected URL %s, got %s", url, gotURL)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, _, err := repository.DecodeCursor(cursor); !errors.Is(err, repository.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for cursor %q, got %v", cursor, err)
+		}
+	}
+}
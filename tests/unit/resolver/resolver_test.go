@@ -0,0 +1,212 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/planner"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/resolver"
+)
+
+// mockArticleService implements article.Service over a fixed catalog, listed
+// two articles per page, with canned vector-search hits.
+type mockArticleService struct {
+	articles []*models.Article
+	hits     []*models.Article
+	searches []string
+	lists    int // Pages listed
+}
+
+func newMockArticleService() *mockArticleService {
+	return &mockArticleService{articles: []*models.Article{
+		{URL: "https://techcrunch.com/2025/07/26/tesla-vet-says-that-reviewing-real-products-not-mockups-is-the-key-to-staying-innovative/", Title: "Tesla vet says that reviewing real products, not mockups, is the key to staying innovative"},
+		{URL: "https://techcrunch.com/2025/07/25/intel-is-spinning-off-its-network-and-edge-group/", Title: "Intel is spinning off its network and edge group"},
+		{URL: "https://edition.cnn.com/2025/07/24/tech/intel-layoffs-15-percent-q2-earnings", Title: "Intel to lay off 15% of its workforce"},
+		{URL: "https://edition.cnn.com/2025/07/27/business/eu-trade-deal", Title: "What the US-EU trade deal means"},
+	}}
+}
+
+func (m *mockArticleService) GetArticle(ctx context.Context, url string) (*models.Article, bool) {
+	for _, art := range m.articles {
+		if art.URL == url {
+			return art, true
+		}
+	}
+	return nil, false
+}
+func (m *mockArticleService) StoreArticle(ctx context.Context, article *models.Article) error {
+	return nil
+}
+func (m *mockArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	return false, nil
+}
+func (m *mockArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	m.lists++
+	start, _ := strconv.Atoi(filter.Cursor)
+	page := &repository.ArticlePage{Articles: m.articles[start:min(start+2, len(m.articles))]}
+	if start+2 < len(m.articles) {
+		page.NextCursor = strconv.Itoa(start + 2)
+	}
+	return page, nil
+}
+func (m *mockArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
+	return "", nil
+}
+func (m *mockArticleService) FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error) {
+	return nil, nil
+}
+func (m *mockArticleService) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	m.searches = append(m.searches, queryText)
+	return m.hits, nil
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	svc := newMockArticleService()
+	tesla, spinoff, layoffs, trade := svc.articles[0].URL, svc.articles[1].URL, svc.articles[2].URL, svc.articles[3].URL
+	history := []models.Turn{{Query: "compare the Intel articles", Targets: []string{spinoff, layoffs}}}
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "exact URL", target: tesla, want: tesla},
+		{name: "URL in another form", target: "http://www.edition.cnn.com/2025/07/27/business/eu-trade-deal/?ref=home", want: trade},
+		{name: "truncated URL", target: "techcrunch.com/2025/07/26/tesla-vet", want: tesla},
+		{name: "title words", target: "the Tesla article", want: tesla},
+		{name: "title words with a typo", target: "Intel layofs", want: layoffs},
+		{name: "slug words", target: "the edge group spinoff story", want: spinoff},
+		{name: "ordinal", target: "the second one", want: layoffs},
+		{name: "last", target: "the last article", want: layoffs},
+		{name: "nothing matches", target: "the article about penguins", want: "the article about penguins"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &planner.QueryPlan{Targets: []string{tt.target}}
+			if err := resolver.NewResolver(svc).Resolve(context.Background(), plan, history); err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if len(plan.Targets) != 1 || plan.Targets[0] != tt.want {
+				t.Errorf("Resolve(%q) = %v, want %s", tt.target, plan.Targets, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_ResolveStepsAndDuplicates(t *testing.T) {
+	svc := newMockArticleService()
+	tesla := svc.articles[0].URL
+	plan := &planner.QueryPlan{
+		Targets: []string{"the Tesla article", tesla, " "},
+		Steps: []planner.PlanStep{
+			{Intent: planner.IntentSummarize, Targets: []string{"the Tesla article"}},
+			{Intent: planner.IntentFindTopic, Targets: []string{}},
+		},
+	}
+	if err := resolver.NewResolver(svc).Resolve(context.Background(), plan, nil); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(plan.Targets) != 1 || plan.Targets[0] != tesla {
+		t.Errorf("Expected duplicates and blanks to be dropped, got %v", plan.Targets)
+	}
+	if len(plan.Steps[0].Targets) != 1 || plan.Steps[0].Targets[0] != tesla || len(plan.Steps[1].Targets) != 0 {
+		t.Errorf("Expected the steps' targets to be resolved, got %+v", plan.Steps)
+	}
+}
+
+func TestResolver_ResolveAmbiguousTargets(t *testing.T) {
+	svc := newMockArticleService()
+	plan := &planner.QueryPlan{Targets: []string{"the Intel article"}}
+
+	err := resolver.NewResolver(svc).Resolve(context.Background(), plan, nil)
+	var ambiguous *resolver.AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Expected an *AmbiguousError, got %v", err)
+	}
+	if ambiguous.Target != "the Intel article" || len(ambiguous.Candidates) != 2 {
+		t.Errorf("Expected both Intel articles as candidates, got %+v", ambiguous)
+	}
+	if ambiguous.Question() == "" {
+		t.Error("Expected a clarifying question")
+	}
+
+	// The fragment covers 46% of the trade deal's URL and 34% of the
+	// layoffs', too far apart to tie by default.
+	trade, layoffs := svc.articles[3].URL, svc.articles[2].URL
+	plan = &planner.QueryPlan{Targets: []string{"edition.cnn.com/2025/07"}}
+	if err := resolver.NewResolver(svc).Resolve(context.Background(), plan, nil); err != nil || plan.Targets[0] != trade {
+		t.Errorf("Expected a URL fragment to match the URL it covers best, got %v, %v", plan.Targets, err)
+	}
+
+	r := resolver.NewResolver(svc)
+	r.TieMargin = 0.2
+	plan = &planner.QueryPlan{Targets: []string{"edition.cnn.com/2025/07"}}
+	if err := r.Resolve(context.Background(), plan, nil); !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 ||
+		ambiguous.Candidates[0].URL != trade || ambiguous.Candidates[1].URL != layoffs {
+		t.Errorf("Expected the URL matches within the tie margin to be ambiguous, best first, got %v", err)
+	}
+}
+
+func TestResolver_IndexesTheCatalog(t *testing.T) {
+	svc := newMockArticleService()
+	r := resolver.NewResolver(svc)
+	resolve := func(target string) string {
+		t.Helper()
+		plan := &planner.QueryPlan{Targets: []string{target}}
+		if err := r.Resolve(context.Background(), plan, nil); err != nil {
+			t.Fatalf("Resolve(%q) error = %v", target, err)
+		}
+		return plan.Targets[0]
+	}
+
+	resolve("the Tesla article")
+	resolve("the trade deal")
+	if svc.lists != 2 {
+		t.Errorf("Expected the two pages of the catalog to be listed once, got %d lists", svc.lists)
+	}
+
+	added := &models.Article{URL: "https://example.com/penguins", Title: "Penguins return to the bay"}
+	svc.articles = append(svc.articles, added)
+	if got := resolve("the penguins article"); got != "the penguins article" {
+		t.Errorf("Expected the index to be used until invalidated, got %s", got)
+	}
+	r.Invalidate()
+	if got := resolve("the penguins article"); got != added.URL {
+		t.Errorf("Expected the added article after Invalidate, got %s", got)
+	}
+}
+
+func TestResolver_ResolveDescriptions(t *testing.T) {
+	svc := newMockArticleService()
+	trade, tesla := svc.articles[3], svc.articles[0]
+	r := resolver.NewResolver(svc)
+
+	svc.hits = []*models.Article{{URL: trade.URL, Title: trade.Title, Relevance: 0.9}, {URL: tesla.URL, Title: tesla.Title, Relevance: 0.7}}
+	plan := &planner.QueryPlan{Targets: []string{"the piece on tariffs between Washington and Brussels"}}
+	if err := r.Resolve(context.Background(), plan, nil); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(svc.searches) != 1 || plan.Targets[0] != trade.URL {
+		t.Errorf("Expected vector search to find the trade article, got %v", plan.Targets)
+	}
+
+	// Hits within the tie margin need the user to pick one.
+	svc.hits[1].Relevance = 0.89
+	plan = &planner.QueryPlan{Targets: []string{"the piece on tariffs between Washington and Brussels"}}
+	var ambiguous *resolver.AmbiguousError
+	if err := r.Resolve(context.Background(), plan, nil); !errors.As(err, &ambiguous) || ambiguous.Candidates[0].URL != trade.URL {
+		t.Errorf("Expected tied hits to be ambiguous, got %v", err)
+	}
+
+	// Hits below the minimum relevance match nothing.
+	svc.hits = []*models.Article{{URL: trade.URL, Title: trade.Title, Relevance: 0.5}}
+	plan = &planner.QueryPlan{Targets: []string{"something about the weather"}}
+	if err := r.Resolve(context.Background(), plan, nil); err != nil || plan.Targets[0] != "something about the weather" {
+		t.Errorf("Expected an irrelevant hit to be ignored, got %v, %v", plan.Targets, err)
+	}
+}
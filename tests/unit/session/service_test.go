@@ -0,0 +1,104 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/session"
+)
+
+// mockSessionRepository is an in-memory implementation of repository.SessionRepository.
+type mockSessionRepository struct {
+	sessions map[string]*models.Session
+	nextID   int
+}
+
+func newMockSessionRepository() *mockSessionRepository {
+	return &mockSessionRepository{sessions: make(map[string]*models.Session)}
+}
+
+func (m *mockSessionRepository) CreateSession(ctx context.Context) (*models.Session, error) {
+	m.nextID++
+	sess := &models.Session{ID: fmt.Sprintf("session-%d", m.nextID)}
+	m.sessions[sess.ID] = sess
+	return sess, nil
+}
+
+func (m *mockSessionRepository) FindSession(ctx context.Context, id string) (*models.Session, error) {
+	sess, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return sess, nil
+}
+
+func (m *mockSessionRepository) ListSessions(ctx context.Context, limit int) ([]*models.Session, error) {
+	var sessions []*models.Session
+	for _, sess := range m.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepository) AppendTurn(ctx context.Context, sessionID string, turn *models.Turn) error {
+	sess, ok := m.sessions[sessionID]
+	if !ok {
+		return errors.New("no such session")
+	}
+	sess.Turns = append(sess.Turns, *turn)
+	return nil
+}
+
+func (m *mockSessionRepository) DeleteSession(ctx context.Context, id string) (bool, error) {
+	if _, ok := m.sessions[id]; !ok {
+		return false, nil
+	}
+	delete(m.sessions, id)
+	return true, nil
+}
+
+func TestSessionService_History(t *testing.T) {
+	ctx := context.Background()
+	svc := session.NewService(newMockSessionRepository())
+
+	sess, err := svc.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start() returned an unexpected error: %v", err)
+	}
+
+	for i := 1; i <= 7; i++ {
+		turn := &models.Turn{Query: fmt.Sprintf("question %d", i), Answer: "answer"}
+		if err := svc.RecordTurn(ctx, sess.ID, turn); err != nil {
+			t.Fatalf("RecordTurn() returned an unexpected error: %v", err)
+		}
+	}
+
+	history, err := svc.History(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("History() returned an unexpected error: %v", err)
+	}
+	if len(history) != 5 {
+		t.Fatalf("Expected history to be capped at 5 turns, got %d", len(history))
+	}
+	if history[0].Query != "question 3" || history[4].Query != "question 7" {
+		t.Errorf("Expected the most recent turns oldest first, got %q..%q", history[0].Query, history[4].Query)
+	}
+}
+
+func TestSessionService_NotFound(t *testing.T) {
+	ctx := context.Background()
+	svc := session.NewService(newMockSessionRepository())
+
+	if _, err := svc.Get(ctx, "missing"); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("Get() expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.History(ctx, "missing"); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("History() expected ErrNotFound, got %v", err)
+	}
+	if err := svc.Delete(ctx, "missing"); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("Delete() expected ErrNotFound, got %v", err)
+	}
+}
@@ -2,26 +2,39 @@ package strategies_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"article-chat-system/internal/article"
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
 	"article-chat-system/internal/planner"
 	"article-chat-system/internal/prompts"
+	"article-chat-system/internal/repository"
 	"article-chat-system/internal/strategies"
 	"article-chat-system/internal/vector"
 )
 
 // mockStrategy is a mock implementation of the IntentStrategy interface.
 type mockStrategy struct {
-	ExecuteFunc func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error)
+	tool        llm.Tool
+	ExecuteFunc func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error)
 }
 
-func (m *mockStrategy) Execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+func (m *mockStrategy) Execute(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 	if m.ExecuteFunc != nil {
 		return m.ExecuteFunc(ctx, plan, articleSvc, promptFactory, vectorSvc)
 	}
-	return "", errors.New("ExecuteFunc not implemented")
+	return nil, errors.New("ExecuteFunc not implemented")
+}
+
+func (m *mockStrategy) Tool() llm.Tool {
+	return m.tool
 }
 
 func TestExecutor_ExecutePlan(t *testing.T) {
@@ -29,9 +42,12 @@ func TestExecutor_ExecutePlan(t *testing.T) {
 	mockSummarizeStrategy := &mockStrategy{}
 	wasCalled := false
 
-	mockSummarizeStrategy.ExecuteFunc = func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (string, error) {
+	mockSummarizeStrategy.ExecuteFunc = func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
 		wasCalled = true
-		return "Mocked summary response", nil
+		return &planner.Result{
+			Answer:  "Mocked summary response",
+			Sources: []planner.Source{{URL: "https://example.com/a", Title: "A", Score: 1}},
+		}, nil
 	}
 
 	executor := &strategies.Executor{
@@ -54,7 +70,411 @@ func TestExecutor_ExecutePlan(t *testing.T) {
 	if !wasCalled {
 		t.Error("Expected the SummarizeStrategy's Execute method to be called, but it was not.")
 	}
-	if response != "Mocked summary response" {
-		t.Errorf("Expected response 'Mocked summary response', but got '%s'", response)
+	if response.Answer != "Mocked summary response" {
+		t.Errorf("Expected response 'Mocked summary response', but got '%s'", response.Answer)
+	}
+	if len(response.Sources) != 1 || response.Sources[0].URL != "https://example.com/a" {
+		t.Errorf("Expected the strategy's sources to be returned, got %+v", response.Sources)
+	}
+}
+
+func TestExecutor_ToolsAndStep(t *testing.T) {
+	executor := strategies.NewExecutor()
+
+	tools := executor.Tools()
+	if len(tools) != len(executor.Strategies) {
+		t.Fatalf("Expected one tool per strategy, got %d", len(tools))
+	}
+	var topicTool *llm.Tool
+	for i, tool := range tools {
+		if i > 0 && tools[i-1].Name >= tool.Name {
+			t.Errorf("Expected tools sorted by name, got %q before %q", tools[i-1].Name, tool.Name)
+		}
+		if tool.Description == "" || tool.Parameters == nil || tool.Parameters.Type != "object" {
+			t.Errorf("Expected %q to have a description and an object schema", tool.Name)
+		}
+		if tool.Name == "find_by_topic" {
+			topicTool = &tools[i]
+		}
+	}
+	if topicTool == nil || topicTool.Parameters.Properties["since"].Format != "date" {
+		t.Fatalf("Expected a find_by_topic tool with a date range, got %+v", topicTool)
+	}
+
+	step, err := executor.Step(llm.ToolCall{Name: "find_by_topic", Arguments: json.RawMessage(`{"topic": "AI", "since": "2025-07-01"}`)})
+	if err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	if step.Intent != planner.IntentFindTopic || len(step.Parameters) != 1 || step.Parameters[0] != "AI" || step.Since != "2025-07-01" || step.Targets == nil {
+		t.Errorf("Unexpected step: %+v", step)
+	}
+
+	if _, err := executor.Step(llm.ToolCall{Name: "dance"}); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+}
+
+func TestExecutor_ExecutePlanRunsSteps(t *testing.T) {
+	answer := func(text, url string) *mockStrategy {
+		return &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+			if plan.Question != "summarize a and find AI articles" {
+				t.Errorf("Expected each step to keep the question, got %q", plan.Question)
+			}
+			return &planner.Result{Answer: text, Sources: []planner.Source{{URL: url}}}, nil
+		}}
+	}
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentSummarize: answer("summary", "https://example.com/a"),
+			planner.IntentFindTopic: answer("topic articles", "https://example.com/a"),
+		},
+	}
+
+	plan := &planner.QueryPlan{
+		Intent:   planner.IntentSummarize,
+		Question: "summarize a and find AI articles",
+		Steps: []planner.PlanStep{
+			{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/a"}},
+			{Intent: planner.IntentFindTopic, Parameters: []string{"AI"}},
+		},
+	}
+	result, err := executor.ExecutePlan(context.Background(), plan, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if result.Answer != "summary\n\n---\n\ntopic articles" {
+		t.Errorf("Expected both answers in order, got %q", result.Answer)
+	}
+	if len(result.Sources) != 1 {
+		t.Errorf("Expected shared sources to be listed once, got %+v", result.Sources)
+	}
+}
+
+func TestExecutor_ExecutePlanMarksIntent(t *testing.T) {
+	var intent string
+	strategy := &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+		intent = llm.IntentFrom(ctx)
+		return &planner.Result{Answer: "ok"}, nil
+	}}
+	executor := &strategies.Executor{Strategies: map[planner.QueryIntent]planner.IntentStrategy{planner.IntentKeywords: strategy}}
+
+	if _, err := executor.ExecutePlan(context.Background(), &planner.QueryPlan{Intent: planner.IntentKeywords}, nil, nil, nil); err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if intent != string(planner.IntentKeywords) {
+		t.Errorf("Expected synthesis calls to be routed for %q, got %q", planner.IntentKeywords, intent)
+	}
+}
+
+// synthesisArticleService implements article.Service for the final synthesis
+// of multi-step plans, recording the prompts it is sent.
+type synthesisArticleService struct {
+	mu      sync.Mutex
+	prompts []string
+	err     error
+	block   bool            // Synthesis waits until the context ends
+	article *models.Article // Returned for any URL when set
+}
+
+func (m *synthesisArticleService) GetArticle(ctx context.Context, url string) (*models.Article, bool) {
+	return m.article, m.article != nil
+}
+func (m *synthesisArticleService) StoreArticle(ctx context.Context, article *models.Article) error {
+	return nil
+}
+func (m *synthesisArticleService) DeleteArticle(ctx context.Context, url string) (bool, error) {
+	return false, nil
+}
+func (m *synthesisArticleService) ListArticles(ctx context.Context, filter repository.ArticleFilter) (*repository.ArticlePage, error) {
+	return &repository.ArticlePage{}, nil
+}
+func (m *synthesisArticleService) CallSynthesisLLM(ctx context.Context, req *llm.Request) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prompts = append(m.prompts, req.Prompt())
+	if m.block {
+		m.mu.Unlock()
+		<-ctx.Done()
+		m.mu.Lock()
+		return "", ctx.Err()
+	}
+	if m.err != nil {
+		return "", m.err
+	}
+	if onChunk, ok := llm.StreamHandlerFromContext(ctx); ok {
+		for _, chunk := range []string{"synthesized ", "answer"} {
+			if err := onChunk(chunk); err != nil {
+				return "", err
+			}
+		}
+	}
+	return "synthesized answer", nil
+}
+func (m *synthesisArticleService) FindCommonEntities(ctx context.Context, articleURLs []string) ([]repository.EntityCount, error) {
+	return nil, nil
+}
+func (m *synthesisArticleService) SearchSimilarArticles(ctx context.Context, queryText string, limit int) ([]*models.Article, error) {
+	return nil, nil
+}
+
+func newPromptFactory(t *testing.T) *prompts.Factory {
+	t.Helper()
+	loader, _ := prompts.NewLoader("v1")
+	loader.PromptDir = filepath.Join("..", "..", "..", loader.PromptDir)
+	factory, err := prompts.NewFactory(loader)
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v", err)
+	}
+	return factory
+}
+
+func TestExecutor_StepArgumentsChainSteps(t *testing.T) {
+	executor := strategies.NewExecutor()
+	for _, tool := range executor.Tools() {
+		if tool.Parameters.Properties["id"] == nil || tool.Parameters.Properties["inputs"] == nil {
+			t.Errorf("Expected %q to take an id and inputs", tool.Name)
+		}
+	}
+
+	step, err := executor.Step(llm.ToolCall{Name: "compare_multiple", Arguments: json.RawMessage(`{"inputs": ["found"], "targets": []}`)})
+	if err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	if len(step.Inputs) != 1 || step.Inputs[0] != "found" {
+		t.Errorf("Expected the step to take input from %q, got %+v", "found", step)
+	}
+}
+
+func TestExecutor_ExecutePlanFeedsInputs(t *testing.T) {
+	var compared []string
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentFindTopic: &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+				return &planner.Result{Answer: "found two", Sources: []planner.Source{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}}}, nil
+			}},
+			planner.IntentCompareMultiple: &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+				if _, ok := llm.StreamHandlerFromContext(ctx); ok {
+					t.Error("Expected the steps not to stream")
+				}
+				compared = plan.Targets
+				return &planner.Result{Answer: "a and b differ", Sources: []planner.Source{{URL: "https://example.com/a"}}}, nil
+			}},
+		},
+	}
+	articleSvc := &synthesisArticleService{}
+	plan := &planner.QueryPlan{
+		Intent:   planner.IntentFindTopic,
+		Question: "compare the articles about AI",
+		Steps: []planner.PlanStep{
+			{ID: "found", Intent: planner.IntentFindTopic, Parameters: []string{"AI"}},
+			{Intent: planner.IntentCompareMultiple, Targets: []string{}, Inputs: []string{"found"}},
+		},
+	}
+	ctx := llm.WithStreamHandler(context.Background(), func(string) error { return nil })
+
+	result, err := executor.ExecutePlan(ctx, plan, articleSvc, newPromptFactory(t), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if len(compared) != 2 || compared[0] != "https://example.com/a" || compared[1] != "https://example.com/b" {
+		t.Errorf("Expected the found articles as targets, got %v", compared)
+	}
+	// The only answer nothing built on is the comparison, so it is the answer.
+	if result.Answer != "a and b differ" || len(articleSvc.prompts) != 0 {
+		t.Errorf("Expected the comparison without a synthesis, got %q after %d synthesis calls", result.Answer, len(articleSvc.prompts))
+	}
+	if len(result.Sources) != 2 {
+		t.Errorf("Expected the sources of both steps once, got %+v", result.Sources)
+	}
+}
+
+func TestExecutor_ExecutePlanRunsIndependentStepsInParallel(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	slow := &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return &planner.Result{Answer: string(plan.Intent) + " answer"}, nil
+	}}
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentSummarize: slow,
+			planner.IntentKeywords:  slow,
+			planner.IntentSentiment: slow,
+		},
+		MaxParallel: 2,
+	}
+	articleSvc := &synthesisArticleService{}
+	plan := &planner.QueryPlan{
+		Intent:   planner.IntentSummarize,
+		Question: "summarize a, list its keywords and its sentiment",
+		Steps: []planner.PlanStep{
+			{Intent: planner.IntentSummarize},
+			{Intent: planner.IntentKeywords},
+			{Intent: planner.IntentSentiment},
+		},
+	}
+
+	result, err := executor.ExecutePlan(context.Background(), plan, articleSvc, newPromptFactory(t), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if peak != 2 {
+		t.Errorf("Expected two steps at a time, got %d", peak)
+	}
+	if result.Answer != "synthesized answer" || len(articleSvc.prompts) != 1 {
+		t.Fatalf("Expected one synthesis of the answers, got %q", result.Answer)
+	}
+	for _, want := range []string{plan.Question, "SUMMARIZE answer", "KEYWORDS answer", "SENTIMENT answer"} {
+		if !strings.Contains(articleSvc.prompts[0], want) {
+			t.Errorf("Expected the synthesis prompt to contain %q", want)
+		}
+	}
+}
+
+func TestExecutor_ExecutePlanReportsFailedSteps(t *testing.T) {
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentSummarize: &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+				return &planner.Result{Answer: "summary"}, nil
+			}},
+			planner.IntentFindTopic: &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}},
+			planner.IntentCompareMultiple: &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+				t.Error("Expected the step fed by a failed step to be skipped")
+				return nil, nil
+			}},
+		},
+		StepTimeout: 20 * time.Millisecond,
+	}
+	articleSvc := &synthesisArticleService{}
+	plan := &planner.QueryPlan{
+		Intent:   planner.IntentSummarize,
+		Question: "summarize a and compare the articles about AI",
+		Steps: []planner.PlanStep{
+			{Intent: planner.IntentSummarize, Targets: []string{"https://example.com/a"}},
+			{ID: "found", Intent: planner.IntentFindTopic, Parameters: []string{"AI"}},
+			{Intent: planner.IntentCompareMultiple, Inputs: []string{"found"}},
+		},
+	}
+
+	result, err := executor.ExecutePlan(context.Background(), plan, articleSvc, newPromptFactory(t), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if result.Answer != "synthesized answer" || len(articleSvc.prompts) != 1 {
+		t.Fatalf("Expected the answers to be synthesized, got %q", result.Answer)
+	}
+	prompt := articleSvc.prompts[0]
+	if !strings.Contains(prompt, "summary") || !strings.Contains(prompt, "timed out after 20ms") || !strings.Contains(prompt, "skipped because step 2 failed") {
+		t.Errorf("Expected the synthesis prompt to report the failed steps, got %q", prompt)
+	}
+	if len(result.Steps) != 3 || result.Steps[0].ID != "step1" || result.Steps[0].Answer != "summary" ||
+		result.Steps[1].ID != "found" || !strings.Contains(result.Steps[1].Error, "timed out") ||
+		!strings.Contains(result.Steps[2].Error, "skipped because step 2 failed") {
+		t.Errorf("Expected the output of every step, got %+v", result.Steps)
+	}
+
+	// When every answered step fails, so does the plan.
+	plan.Steps = plan.Steps[1:]
+	if _, err := executor.ExecutePlan(context.Background(), plan, articleSvc, newPromptFactory(t), nil); err == nil {
+		t.Error("Expected an error when no step succeeds")
+	}
+}
+
+func TestExecutor_ExecutePlanJoinsAnswersWhenSynthesisFails(t *testing.T) {
+	answer := func(text string) *mockStrategy {
+		return &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+			return &planner.Result{Answer: text}, nil
+		}}
+	}
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentSummarize: answer("summary"),
+			planner.IntentKeywords:  answer("keywords"),
+		},
+	}
+	plan := &planner.QueryPlan{
+		Intent: planner.IntentSummarize,
+		Steps:  []planner.PlanStep{{Intent: planner.IntentSummarize}, {Intent: planner.IntentKeywords}},
+	}
+
+	result, err := executor.ExecutePlan(context.Background(), plan, &synthesisArticleService{err: errors.New("provider down")}, newPromptFactory(t), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if result.Answer != "summary\n\n---\n\nkeywords" {
+		t.Errorf("Expected the answers joined in step order, got %q", result.Answer)
+	}
+}
+
+func TestExecutor_ExecutePlanBoundsSynthesis(t *testing.T) {
+	answer := func(text string) *mockStrategy {
+		return &mockStrategy{ExecuteFunc: func(ctx context.Context, plan *planner.QueryPlan, articleSvc article.Service, promptFactory *prompts.Factory, vectorSvc vector.Service) (*planner.Result, error) {
+			return &planner.Result{Answer: text}, nil
+		}}
+	}
+	executor := &strategies.Executor{
+		Strategies: map[planner.QueryIntent]planner.IntentStrategy{
+			planner.IntentSummarize: answer("summary"),
+			planner.IntentKeywords:  answer("keywords"),
+		},
+		StepTimeout: 20 * time.Millisecond,
+	}
+	plan := &planner.QueryPlan{
+		Intent: planner.IntentSummarize,
+		Steps:  []planner.PlanStep{{Intent: planner.IntentSummarize}, {Intent: planner.IntentKeywords}},
+	}
+
+	result, err := executor.ExecutePlan(context.Background(), plan, &synthesisArticleService{block: true}, newPromptFactory(t), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if result.Answer != "summary\n\n---\n\nkeywords" {
+		t.Errorf("Expected a synthesis that outlasts StepTimeout to be given up, got %q", result.Answer)
+	}
+}
+
+func TestExecutor_ExecutePlanRejectsUnknownInputs(t *testing.T) {
+	executor := &strategies.Executor{Strategies: map[planner.QueryIntent]planner.IntentStrategy{}}
+	plan := &planner.QueryPlan{
+		Intent: planner.IntentCompareMultiple,
+		Steps: []planner.PlanStep{
+			{Intent: planner.IntentCompareMultiple, Inputs: []string{"found"}},
+			{ID: "found", Intent: planner.IntentFindTopic},
+		},
+	}
+	if _, err := executor.ExecutePlan(context.Background(), plan, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "not an earlier step") {
+		t.Errorf("Expected an input naming a later step to be rejected, got %v", err)
+	}
+
+	if _, err := planner.Dependencies([]planner.PlanStep{{ID: "step2"}, {}}); err == nil {
+		t.Error("Expected a duplicate step id to be rejected")
+	}
+}
+
+func TestExecutor_StreamedTokensMatchAnswer(t *testing.T) {
+	articleSvc := &synthesisArticleService{article: &models.Article{URL: "https://example.com/a", Title: "A", Summary: "About A."}}
+	var streamed strings.Builder
+	ctx := llm.WithStreamHandler(context.Background(), func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+
+	plan := &planner.QueryPlan{Intent: planner.IntentKeywords, Targets: []string{"https://example.com/a"}}
+	result, err := strategies.NewExecutor().ExecutePlan(ctx, plan, articleSvc, newPromptFactory(t), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if streamed.String() != result.Answer {
+		t.Errorf("Expected the streamed tokens %q to add up to the answer %q", streamed.String(), result.Answer)
 	}
 }
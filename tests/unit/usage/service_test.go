@@ -0,0 +1,213 @@
+package usage_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"article-chat-system/internal/llm"
+	"article-chat-system/internal/models"
+	"article-chat-system/internal/repository"
+	"article-chat-system/internal/usage"
+)
+
+// memoryUsageRepository keeps usage records in memory.
+type memoryUsageRepository struct {
+	records []*models.UsageRecord
+}
+
+func (m *memoryUsageRepository) SaveUsage(ctx context.Context, records []*models.UsageRecord) error {
+	m.records = append(m.records, records...)
+	return nil
+}
+
+func (m *memoryUsageRepository) matching(filter repository.UsageFilter) []*models.UsageRecord {
+	var out []*models.UsageRecord
+	for _, rec := range m.records {
+		if (filter.User != "" && rec.User != filter.User) ||
+			(filter.APIKeyID != "" && rec.APIKeyID != filter.APIKeyID) ||
+			(filter.Anonymous && (rec.User != "" || rec.APIKeyID != "")) ||
+			(!filter.From.IsZero() && rec.CreatedAt.Before(filter.From)) ||
+			(!filter.To.IsZero() && !rec.CreatedAt.Before(filter.To)) {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func (m *memoryUsageRepository) SummarizeUsage(ctx context.Context, filter repository.UsageFilter) ([]*models.UsageSummary, error) {
+	var out []*models.UsageSummary
+	for _, rec := range m.matching(filter) {
+		out = append(out, &models.UsageSummary{
+			Day: rec.CreatedAt.UTC().Format("2006-01-02"), User: rec.User, APIKeyID: rec.APIKeyID, Model: rec.Model,
+			Requests: 1, PromptTokens: rec.PromptTokens, CompletionTokens: rec.CompletionTokens,
+			TotalTokens: rec.TotalTokens, CostUSD: rec.CostUSD,
+		})
+	}
+	return out, nil
+}
+
+func (m *memoryUsageRepository) TotalCost(ctx context.Context, filter repository.UsageFilter) (float64, error) {
+	var total float64
+	for _, rec := range m.matching(filter) {
+		total += rec.CostUSD
+	}
+	return total, nil
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
+
+var testPrices = usage.PriceTable{
+	"gpt-4o":      {Prompt: 2.5, Completion: 10, CacheRead: ptr(1.25)},
+	"gpt-4o-mini": {Prompt: 0.15, Completion: 0.6},
+}
+
+func TestPriceTable_Cost(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		usage llm.Usage
+		want  float64
+	}{
+		{name: "prompt and completion", model: "gpt-4o", usage: llm.Usage{PromptTokens: 1_000_000, CompletionTokens: 100_000}, want: 3.5},
+		{name: "cached prompt tokens", model: "gpt-4o", usage: llm.Usage{PromptTokens: 1_000_000, CacheReadTokens: 400_000}, want: 0.6*2.5 + 0.4*1.25},
+		{name: "longest prefix wins", model: "gpt-4o-mini-2024-07-18", usage: llm.Usage{PromptTokens: 1_000_000}, want: 0.15},
+		{name: "unknown model is free", model: "llama3", usage: llm.Usage{PromptTokens: 1_000_000}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testPrices.Cost(tt.model, tt.usage); !approx(got, tt.want) {
+				t.Errorf("Cost() = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPrices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	data := "models:\n  gpt-4o:\n    prompt: 2.5\n    completion: 10\n    cache_read: 1.25\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prices, err := usage.LoadPrices(path)
+	if err != nil {
+		t.Fatalf("LoadPrices() error = %v", err)
+	}
+	price, ok := prices.Lookup("gpt-4o-2024-08-06")
+	if !ok || price.Prompt != 2.5 || price.CacheRead == nil || *price.CacheRead != 1.25 {
+		t.Errorf("Unexpected price: %+v (found %v)", price, ok)
+	}
+
+	missing, err := usage.LoadPrices(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("Expected a missing file to load as an empty table, got %v, %v", missing, err)
+	}
+}
+
+func TestUsageService_RecordAttributesUsage(t *testing.T) {
+	repo := &memoryUsageRepository{}
+	svc := usage.NewService(repo, testPrices, usage.Budgets{})
+	ctx := usage.WithCaller(context.Background(), usage.Caller{User: "alice", APIKeyID: usage.KeyID("secret")})
+
+	cost, err := svc.Record(ctx, "chat", map[string]llm.Usage{
+		"gpt-4o":      {PromptTokens: 1_000_000, CompletionTokens: 100_000, TotalTokens: 1_100_000},
+		"gpt-4o-mini": {PromptTokens: 1_000_000, TotalTokens: 1_000_000},
+		"mock":        {},
+	})
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if !approx(cost, 3.65) {
+		t.Errorf("Expected a cost of 3.65, got %g", cost)
+	}
+	if len(repo.records) != 2 {
+		t.Fatalf("Expected one record per model with usage, got %d", len(repo.records))
+	}
+	for _, rec := range repo.records {
+		if rec.User != "alice" || rec.APIKeyID != usage.KeyID("secret") || rec.Operation != "chat" || rec.RequestID != repo.records[0].RequestID {
+			t.Errorf("Unexpected attribution: %+v", rec)
+		}
+	}
+	if usage.KeyID("secret") == "secret" {
+		t.Error("Expected the API key not to be stored in the clear")
+	}
+
+	report, err := svc.Report(ctx, repository.UsageFilter{User: "alice"})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if len(report.Usage) != 2 || !approx(report.Total.CostUSD, 3.65) || report.Total.TotalTokens != 2_100_000 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestUsageService_CheckEnforcesBudgets(t *testing.T) {
+	repo := &memoryUsageRepository{}
+	svc := usage.NewService(repo, testPrices, usage.Budgets{PerCaller: 1, Total: 5})
+	alice := usage.WithCaller(context.Background(), usage.Caller{User: "alice"})
+	bob := usage.WithCaller(context.Background(), usage.Caller{User: "bob"})
+	anonymous := context.Background()
+
+	if err := svc.Check(alice); err != nil {
+		t.Fatalf("Expected a fresh budget to allow the request, got %v", err)
+	}
+	// $3.50 spent by alice exhausts her budget but not the total.
+	if _, err := svc.Record(alice, "chat", map[string]llm.Usage{"gpt-4o": {PromptTokens: 1_000_000, CompletionTokens: 100_000}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Check(alice); !errors.Is(err, usage.ErrBudgetExceeded) {
+		t.Errorf("Expected alice's budget to be exceeded, got %v", err)
+	}
+	if err := svc.Check(bob); err != nil {
+		t.Errorf("Expected bob to have a separate budget, got %v", err)
+	}
+
+	// Another $3.50 from an anonymous caller exhausts the budget all
+	// anonymous callers share, and the total.
+	if err := svc.Check(anonymous); err != nil {
+		t.Fatalf("Expected a fresh anonymous budget to allow the request, got %v", err)
+	}
+	if _, err := svc.Record(anonymous, "chat", map[string]llm.Usage{"gpt-4o": {PromptTokens: 1_000_000, CompletionTokens: 100_000}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Check(anonymous); !errors.Is(err, usage.ErrBudgetExceeded) || !strings.Contains(err.Error(), "anonymous budget") {
+		t.Errorf("Expected the anonymous budget to be exceeded, got %v", err)
+	}
+	if err := svc.Check(bob); !errors.Is(err, usage.ErrBudgetExceeded) {
+		t.Errorf("Expected the total budget to be exceeded, got %v", err)
+	}
+}
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	keys := usage.NewAPIKeys([]string{"secret", ""}, []string{"admin-secret"})
+
+	caller, err := keys.Authenticate("secret", "")
+	if err != nil || caller.User != "" || caller.APIKeyID != usage.KeyID("secret") || caller.Admin {
+		t.Errorf("Expected a known key to be its own caller, got %+v, %v", caller, err)
+	}
+	if _, err := keys.Authenticate("secret", "alice"); !errors.Is(err, usage.ErrUserNotAllowed) {
+		t.Errorf("Expected a regular key naming a user to be refused, got %v", err)
+	}
+	caller, err = keys.Authenticate("admin-secret", "alice")
+	if err != nil || caller.User != "alice" || caller.APIKeyID != usage.KeyID("admin-secret") || !caller.Admin {
+		t.Errorf("Expected an admin key to act for alice, got %+v, %v", caller, err)
+	}
+	if caller, err := keys.Authenticate("", "alice"); err != nil || !caller.Anonymous() {
+		t.Errorf("Expected a user ID without a key to stay anonymous, got %+v, %v", caller, err)
+	}
+	if _, err := keys.Authenticate("guess", "alice"); !errors.Is(err, usage.ErrUnknownAPIKey) {
+		t.Errorf("Expected an unknown key to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package vector_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"article-chat-system/internal/vector"
+)
+
+// flakyStore fails to connect a fixed number of times before succeeding.
+type flakyStore struct {
+	failures int32
+	attempts atomic.Int32
+	ready    atomic.Bool
+}
+
+func (s *flakyStore) Connect(ctx context.Context) error {
+	if s.attempts.Add(1) <= s.failures {
+		return errors.New("weaviate unreachable")
+	}
+	s.ready.Store(true)
+	return nil
+}
+
+func (s *flakyStore) Ready() bool { return s.ready.Load() }
+
+func TestReconnect_AttachesOnceReachable(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := &flakyStore{failures: 2}
+	svc := &flakyStore{}
+
+	done := make(chan struct{})
+	go func() {
+		vector.Reconnect(context.Background(), logger, time.Millisecond, 5*time.Millisecond, repo, svc)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reconnect did not return after the stores connected")
+	}
+	if !repo.Ready() || !svc.Ready() {
+		t.Error("Expected both stores to be ready")
+	}
+	if got := svc.attempts.Load(); got != 1 {
+		t.Errorf("Expected a ready store not to be reconnected, got %d attempts", got)
+	}
+}
+
+func TestReconnect_StopsOnCancel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := &flakyStore{failures: 1 << 30}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		vector.Reconnect(ctx, logger, time.Millisecond, time.Millisecond, store)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reconnect did not stop when the context was cancelled")
+	}
+}